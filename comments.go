@@ -0,0 +1,130 @@
+package jsonvx
+
+import "bytes"
+
+// Comments returns the comment tokens attached to the node, in source order, each with its
+// LINE_COMMENT or BLOCK_COMMENT sub kind and its position.
+//
+// A node owns the comments written right before it and right after it, up to the next
+// value. A comment following the comma after a value, on the same line, documents that
+// value rather than the next one (e.g., `port: 8080, // default port`). Comments inside
+// an array or object that precede no value, such as those before its closing bracket, are
+// attached to the array or object itself.
+//
+// Comments are recorded whether or not PreserveTrivia is enabled.
+func Comments(node JSON) Tokens {
+	if isNilNode(node) {
+		return nil
+	}
+
+	if tn, ok := node.(triviaNode); ok {
+		return tn.trivia().comments
+	}
+
+	return nil
+}
+
+// Comments returns the comment tokens attached to the property: those around its key followed
+// by those attached to its value. See the Comments function for how comments are attached.
+func (kv *KeyValue) Comments() Tokens {
+	valueComments := Comments(kv.value)
+
+	if len(kv.keyTrivia.comments) == 0 {
+		return valueComments
+	}
+
+	comments := make(Tokens, 0, len(kv.keyTrivia.comments)+len(valueComments))
+	comments = append(comments, kv.keyTrivia.comments...)
+	comments = append(comments, valueComments...)
+
+	return comments
+}
+
+// attachTrivia records the tokens skipped before and after a node as its trivia.
+// See attach for the meaning of afterComma.
+func (p *Parser) attachTrivia(node JSON, leading, trailing Tokens, afterComma bool) {
+	if tn, ok := node.(triviaNode); ok {
+		p.attach(tn.trivia(), leading, trailing, afterComma)
+	}
+}
+
+// attach records the tokens skipped before and after a node. Whitespace is only kept when
+// PreserveTrivia is enabled, while comments are always recorded. Comments already attached
+// to the node, such as those inside an array or object, stay between the new ones.
+//
+// When the leading tokens follow a comma, the comments on the line of the comma are left out,
+// as they belong to the previous value (see attachSameLineComments).
+func (p *Parser) attach(trivia *Trivia, leading, trailing Tokens, afterComma bool) {
+	inner := trivia.comments
+
+	leadingComments := leading
+	if afterComma {
+		leadingComments = leading[len(sameLine(leading)):]
+	}
+
+	trivia.comments = appendComments(nil, leadingComments)
+	trivia.comments = append(trivia.comments, inner...)
+	trivia.comments = appendComments(trivia.comments, trailing)
+
+	if len(trivia.comments) == 0 {
+		trivia.comments = nil
+	}
+
+	if p.config.PreserveTrivia {
+		trivia.Leading = leading
+		trivia.Trailing = trailing
+	}
+}
+
+// attachSameLineComments attaches the comments following a comma on the same line to the value
+// before the comma.
+func (p *Parser) attachSameLineComments(node JSON, afterComma Tokens) {
+	if tn, ok := node.(triviaNode); ok {
+		trivia := tn.trivia()
+		trivia.comments = appendComments(trivia.comments, sameLine(afterComma))
+	}
+}
+
+// attachClosingTrivia attaches the comments before the closing bracket of an array or object,
+// except those already attached to its last value, to the array or object itself.
+// It returns the skipped tokens when PreserveTrivia is enabled.
+func (p *Parser) attachClosingTrivia(trivia *Trivia, closing Tokens, afterComma bool) Tokens {
+	closingComments := closing
+	if afterComma {
+		closingComments = closing[len(sameLine(closing)):]
+	}
+
+	trivia.comments = appendComments(trivia.comments, closingComments)
+
+	if !p.config.PreserveTrivia {
+		return nil
+	}
+
+	return closing
+}
+
+// sameLine returns the tokens up to the end of the current line, a line comment included,
+// or nil if the tokens do not end the line.
+func sameLine(tokens Tokens) Tokens {
+	for i, token := range tokens {
+		switch {
+		case token.Kind == COMMENT && token.SubKind == LINE_COMMENT:
+			return tokens[:i+1]
+		case token.Kind == WHITESPACE && bytes.ContainsAny(token.Literal, "\n\r"):
+			return tokens[:i]
+		}
+	}
+
+	return nil
+}
+
+// appendComments appends the comment tokens found in tokens to comments.
+func appendComments(comments Tokens, tokens Tokens) Tokens {
+	for _, token := range tokens {
+		if token.Kind == COMMENT {
+			comments = append(comments, token)
+		}
+	}
+
+	return comments
+}
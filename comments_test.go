@@ -0,0 +1,116 @@
+package jsonvx
+
+import (
+	"testing"
+)
+
+func commentLiterals(tokens Tokens) []string {
+	literals := []string{}
+
+	for _, token := range tokens {
+		literals = append(literals, string(token.Literal))
+	}
+
+	return literals
+}
+
+func equalLiterals(got, expected []string) bool {
+	if len(got) != len(expected) {
+		return false
+	}
+
+	for i := range got {
+		if got[i] != expected[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestComments(t *testing.T) {
+	input := "// config file\n{\n" +
+		"  // host to bind\n" +
+		"  host: 'localhost', // default host\n" +
+		"  port /* key */ : /* value */ 8080,\n" +
+		"  tags: [\n" +
+		"    'a', /* first */\n" +
+		"    /* second */ 'b', // last\n" +
+		"    // dangling\n" +
+		"  ],\n" +
+		"  empty: {/* nothing */},\n" +
+		"}\n"
+
+	for _, preserve := range []bool{false, true} {
+		cfg := JSON5Config()
+		cfg.PreserveTrivia = preserve
+
+		parser := NewParser([]byte(input), cfg)
+		node, err := parser.Parse()
+		if err != nil {
+			t.Fatalf("unexpected parse error: %v", err)
+		}
+
+		obj := node.(*Object)
+		tags, _ := obj.QueryPath("tags")
+		empty, _ := obj.QueryPath("empty")
+		host, _ := obj.QueryPath("host")
+
+		var tests = []struct {
+			msg      string
+			got      Tokens
+			expected []string
+		}{
+			{msg: "Root comments", got: Comments(obj), expected: []string{"// config file\n"}},
+			{msg: "Property comments", got: obj.Properties[0].Comments(), expected: []string{"// host to bind\n", "// default host\n"}},
+			{msg: "Value comments", got: Comments(host), expected: []string{"// default host\n"}},
+			{msg: "Key and value comments", got: obj.Properties[1].Comments(), expected: []string{"/* key */", "/* value */"}},
+			{msg: "Same line item comment", got: Comments(tags.(*Array).Items[0]), expected: []string{"/* first */"}},
+			{msg: "Leading and trailing item comments", got: Comments(tags.(*Array).Items[1]), expected: []string{"/* second */", "// last\n"}},
+			{msg: "Dangling array comment", got: Comments(tags), expected: []string{"// dangling\n"}},
+			{msg: "Empty object comment", got: Comments(empty), expected: []string{"/* nothing */"}},
+		}
+
+		for _, test := range tests {
+			t.Run(test.msg, func(t *testing.T) {
+				got := commentLiterals(test.got)
+
+				if !equalLiterals(got, test.expected) {
+					t.Errorf("got %q, expected %q (PreserveTrivia: %v)", got, test.expected, preserve)
+				}
+			})
+		}
+	}
+}
+
+func TestCommentTokens(t *testing.T) {
+	parser := NewParser([]byte("[1, /* one */ 2 // two\n]"), JSON5Config())
+	node, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	arr := node.(*Array)
+
+	first := Comments(arr.Items[0])
+	if len(first) != 0 {
+		t.Errorf("got %v, expected no comments", first)
+	}
+
+	second := Comments(arr.Items[1])
+	if len(second) != 2 {
+		t.Fatalf("got %v, expected 2 comments", second)
+	}
+
+	if second[0].Kind != COMMENT || second[0].SubKind != BLOCK_COMMENT || second[0].Column != 5 {
+		t.Errorf("got %v, expected a block comment at column 5", second[0])
+	}
+
+	if second[1].Kind != COMMENT || second[1].SubKind != LINE_COMMENT || second[1].Column != 17 {
+		t.Errorf("got %v, expected a line comment at column 17", second[1])
+	}
+
+	if Comments(nil) != nil || Comments((*Array)(nil)) != nil {
+		t.Errorf("expected no comments for nil nodes")
+	}
+}
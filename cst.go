@@ -0,0 +1,237 @@
+package jsonvx
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Trivia holds the whitespace and comment tokens surrounding a node.
+//
+// Trivia is only recorded when the ParserConfig enables PreserveTrivia. Together with the
+// literal text of every token, it makes the parsed tree a lossless concrete syntax tree:
+// Print reproduces the parsed input byte for byte.
+type Trivia struct {
+	Leading  Tokens // Leading holds the tokens between the previous structural token ('[', '{', ',' or ':') and the node.
+	Trailing Tokens // Trailing holds the tokens between the node and the next structural token (',', ':', ']' or '}').
+
+	comments Tokens // comments holds the comments attached to the node, they are recorded even without PreserveTrivia.
+}
+
+// triviaNode is implemented by every node that carries Trivia.
+type triviaNode interface {
+	trivia() *Trivia
+}
+
+func (n *Null) trivia() *Trivia    { return &n.Trivia }
+func (b *Boolean) trivia() *Trivia { return &b.Trivia }
+func (s *String) trivia() *Trivia  { return &s.Trivia }
+func (n *Number) trivia() *Trivia  { return &n.Trivia }
+func (a *Array) trivia() *Trivia   { return &a.Trivia }
+func (o *Object) trivia() *Trivia  { return &o.Trivia }
+
+// isNilNode reports whether node is nil, or a nil pointer to one of the node types.
+func isNilNode(node JSON) bool {
+	switch val := node.(type) {
+	case *Null:
+		return val == nil
+	case *Boolean:
+		return val == nil
+	case *String:
+		return val == nil
+	case *Number:
+		return val == nil
+	case *Array:
+		return val == nil
+	case *Object:
+		return val == nil
+	case *ErrorNode:
+		return val == nil
+	}
+
+	return node == nil
+}
+
+// inheritTrivia copies the trivia of the node being replaced onto its replacement,
+// unless the replacement already carries trivia of its own.
+func inheritTrivia(replacement, replaced JSON) {
+	if isNilNode(replacement) || isNilNode(replaced) {
+		return
+	}
+
+	newNode, ok := replacement.(triviaNode)
+	if !ok {
+		return
+	}
+
+	oldNode, ok := replaced.(triviaNode)
+	if !ok {
+		return
+	}
+
+	newTrivia, oldTrivia := newNode.trivia(), oldNode.trivia()
+	if len(newTrivia.Leading) == 0 && len(newTrivia.Trailing) == 0 {
+		*newTrivia = *oldTrivia
+	}
+}
+
+// Set replaces the item at index. The new item takes over the trivia of the replaced one,
+// unless it carries trivia of its own, so printing the array only changes the item itself.
+func (a *Array) Set(index int, item JSON) error {
+	if index < 0 || index >= a.Len() {
+		return fmt.Errorf("%w: %d", ErrIndexOutOfRange, index)
+	}
+
+	inheritTrivia(item, a.Items[index])
+	a.Items[index] = item
+
+	return nil
+}
+
+// Set replaces the value of the first property with the given key, or appends a new
+// property when the key is not found. A replaced value hands its trivia over to the new
+// value, unless it carries trivia of its own, so printing the object only changes the value itself.
+func (o *Object) Set(key string, value JSON) {
+	if index, ok := o.lookup([]byte(key)); ok {
+		inheritTrivia(value, o.Properties[index].value)
+		o.Properties[index].value = value
+		return
+	}
+
+	o.Properties = append(o.Properties, newKeyValue([]byte(key), value))
+	o.reindex()
+}
+
+// Print writes the node using the literal text of its tokens and its trivia.
+//
+// For a tree parsed with PreserveTrivia enabled, Print reproduces the input byte for byte,
+// and a node changed after parsing only affects its own part of the output. Without trivia,
+// the output is compact, while string and number literals are still written as they appear in the source.
+func Print(w io.Writer, node JSON) error {
+	var buf bytes.Buffer
+
+	if err := printNode(&buf, node); err != nil {
+		return err
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func printNode(buf *bytes.Buffer, node JSON) error {
+	var (
+		trivia *Trivia
+		write  func() error
+	)
+
+	switch val := node.(type) {
+	case *Null:
+		if val != nil {
+			trivia, write = &val.Trivia, func() error { return printToken(buf, val.Token) }
+		}
+	case *Boolean:
+		if val != nil {
+			trivia, write = &val.Trivia, func() error { return printToken(buf, val.Token) }
+		}
+	case *String:
+		if val != nil {
+			trivia, write = &val.Trivia, func() error { return printToken(buf, val.Token) }
+		}
+	case *Number:
+		if val != nil {
+			trivia, write = &val.Trivia, func() error { return printToken(buf, val.Token) }
+		}
+	case *Array:
+		if val != nil {
+			trivia, write = &val.Trivia, func() error { return printArray(buf, val) }
+		}
+	case *Object:
+		if val != nil {
+			trivia, write = &val.Trivia, func() error { return printObject(buf, val) }
+		}
+	case *ErrorNode:
+		if val != nil {
+			trivia, write = &val.Trivia, func() error { printTokens(buf, val.Tokens); return nil }
+		}
+	}
+
+	if trivia == nil {
+		return fmt.Errorf("%w: %T", ErrInvalidJSONType, node)
+	}
+
+	printTokens(buf, trivia.Leading)
+
+	if err := write(); err != nil {
+		return err
+	}
+
+	printTokens(buf, trivia.Trailing)
+
+	return nil
+}
+
+func printArray(buf *bytes.Buffer, arr *Array) error {
+	buf.WriteByte('[')
+
+	for i, item := range arr.Items {
+		if err := printNode(buf, item); err != nil {
+			return err
+		}
+
+		if i < arr.Len()-1 || arr.trailingComma {
+			buf.WriteByte(',')
+		}
+	}
+
+	printTokens(buf, arr.closing)
+	buf.WriteByte(']')
+
+	return nil
+}
+
+func printObject(buf *bytes.Buffer, obj *Object) error {
+	buf.WriteByte('{')
+
+	for i, prop := range obj.Properties {
+		printTokens(buf, prop.keyTrivia.Leading)
+
+		if prop.keyToken != nil {
+			buf.Write(prop.keyToken.Literal)
+		} else {
+			state := encodeState{config: NewEncoderConfig()}
+			state.writeString(string(prop.key))
+			buf.Write(state.buf.Bytes())
+		}
+
+		printTokens(buf, prop.keyTrivia.Trailing)
+		buf.WriteByte(':')
+
+		if err := printNode(buf, prop.value); err != nil {
+			return err
+		}
+
+		if i < obj.Len()-1 || obj.trailingComma {
+			buf.WriteByte(',')
+		}
+	}
+
+	printTokens(buf, obj.closing)
+	buf.WriteByte('}')
+
+	return nil
+}
+
+func printToken(buf *bytes.Buffer, token *Token) error {
+	if token == nil {
+		return fmt.Errorf("%w: node has no token", ErrInvalidJSONType)
+	}
+
+	buf.Write(token.Literal)
+	return nil
+}
+
+func printTokens(buf *bytes.Buffer, tokens Tokens) {
+	for _, token := range tokens {
+		buf.Write(token.Literal)
+	}
+}
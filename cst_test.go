@@ -0,0 +1,135 @@
+package jsonvx
+
+import (
+	"bytes"
+	"testing"
+)
+
+func parseWithTrivia(t *testing.T, input string) JSON {
+	t.Helper()
+
+	parser := NewParser([]byte(input), NewParserConfig(func(c *ParserConfig) {
+		*c = *JSON5Config()
+		c.PreserveTrivia = true
+	}))
+
+	node, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	return node
+}
+
+func printString(t *testing.T, node JSON) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := Print(&buf, node); err != nil {
+		t.Fatalf("unexpected print error: %v", err)
+	}
+
+	return buf.String()
+}
+
+func TestPrintRoundTrip(t *testing.T) {
+	var tests = []struct {
+		msg   string
+		input string
+	}{
+		{msg: "Print scalar", input: `42`},
+		{msg: "Print scalar with surrounding trivia", input: "  // answer\n  42 /* end */\n"},
+		{msg: "Print empty containers", input: `[ /* nothing */ ]`},
+		{msg: "Print empty object", input: "{\n}"},
+		{msg: "Print array", input: "[ 1 ,2,\t3 ]"},
+		{msg: "Print array with trailing comma", input: "[\n  1, // one\n  2, // two\n]"},
+		{msg: "Print relaxed literals", input: `[+1, .5, 0xFF, Infinity, NaN, 'single', "esc\u00e9\n"]`},
+		{msg: "Print object", input: `{ "a" : 1 , b:[true,null] }`},
+		{
+			msg: "Print commented config",
+			input: "// config\n{\n" +
+				"  /* server */\n" +
+				"  host: 'localhost', // default host\n" +
+				"  \"port\" /* key */ : /* value */ 8080,\n" +
+				"  tags: [\n    'a',\n    'b', // last\n  ],\n" +
+				"  nested: { deep: { value: .5 } },\n" +
+				"}\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			node := parseWithTrivia(t, test.input)
+
+			if got := printString(t, node); got != test.input {
+				t.Errorf("got %q, expected %q", got, test.input)
+			}
+		})
+	}
+}
+
+func TestPrintWithoutTrivia(t *testing.T) {
+	parser := NewParser([]byte("{ // comment\n  a: [1, 2,], 'b': 0xFF }"), JSON5Config())
+	node, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	expected := `{a:[1,2,],'b':0xFF}`
+	if got := printString(t, node); got != expected {
+		t.Errorf("got %q, expected %q", got, expected)
+	}
+}
+
+func TestPrintEdits(t *testing.T) {
+	input := "{\n  // port to listen on\n  port: 8080, // default\n  hosts: [\n    'a', /* first */\n    'b',\n  ],\n}"
+
+	newNumber := func(literal string) JSON {
+		return parseWithTrivia(t, literal)
+	}
+
+	t.Run("Replace object value", func(t *testing.T) {
+		obj := parseWithTrivia(t, input).(*Object)
+		obj.Set("port", newNumber("9090"))
+
+		expected := "{\n  // port to listen on\n  port: 9090, // default\n  hosts: [\n    'a', /* first */\n    'b',\n  ],\n}"
+		if got := printString(t, obj); got != expected {
+			t.Errorf("got %q, expected %q", got, expected)
+		}
+	})
+
+	t.Run("Replace array item", func(t *testing.T) {
+		obj := parseWithTrivia(t, input).(*Object)
+		hosts, err := obj.QueryPath("hosts")
+		if err != nil {
+			t.Fatalf("unexpected query error: %v", err)
+		}
+
+		if err := hosts.(*Array).Set(0, parseWithTrivia(t, `"c"`)); err != nil {
+			t.Fatalf("unexpected set error: %v", err)
+		}
+
+		expected := "{\n  // port to listen on\n  port: 8080, // default\n  hosts: [\n    \"c\", /* first */\n    'b',\n  ],\n}"
+		if got := printString(t, obj); got != expected {
+			t.Errorf("got %q, expected %q", got, expected)
+		}
+	})
+
+	t.Run("Add object property", func(t *testing.T) {
+		obj := parseWithTrivia(t, `{a: 1}`).(*Object)
+		obj.Set("b c", newNumber("2"))
+
+		expected := `{a: 1,"b c":2}`
+		if got := printString(t, obj); got != expected {
+			t.Errorf("got %q, expected %q", got, expected)
+		}
+	})
+
+	t.Run("Set array item out of range", func(t *testing.T) {
+		arr := parseWithTrivia(t, `[1]`).(*Array)
+
+		if err := arr.Set(1, newNumber("2")); err == nil {
+			t.Errorf("expected an error for index out of range")
+		}
+	})
+}
@@ -0,0 +1,225 @@
+package jsonvx
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"unicode/utf8"
+)
+
+// decoderChunkSize is the minimum number of bytes a Decoder reads at once.
+const decoderChunkSize = 4096
+
+// maxEmptyReads is the number of successive reads returning no data and no error
+// after which a Decoder gives up with io.ErrNoProgress.
+const maxEmptyReads = 100
+
+// Decoder parses JSON read from an io.Reader.
+//
+// Unlike Parser, which needs the whole input as a []byte, a Decoder lexes the input as it reads it:
+// it only buffers the token being lexed, along with the bytes read ahead of it, so the memory used
+// for the input is bounded by the size of the largest token rather than by the size of the input.
+// The tokens are dropped as the items of arrays and objects are parsed, and whitespace is only kept
+// with PreserveTrivia, so the memory used while decoding stays in line with the size of the tree.
+// Every ParserConfig option behaves exactly as with Parser, tokens split across reads included.
+type Decoder struct {
+	reader io.Reader
+	config *ParserConfig
+	parser Parser
+	lexer  Lexer
+
+	buf    []byte // buf holds the bytes read but not consumed yet, from pos on.
+	pos    int    // pos is the position in buf of the next token.
+	offset int    // offset is the byte offset in the input of the start of buf.
+	line   int    // line is the line of the next token (starting from 1).
+	column int    // column is the column of the next token (starting from 1).
+
+	eof bool  // eof reports whether the reader is exhausted.
+	err error // err holds the error returned by the reader, if any.
+}
+
+// NewDecoder creates a new Decoder reading from r, using the given configuration.
+// A nil configuration uses the defaults of NewParserConfig.
+func NewDecoder(r io.Reader, config *ParserConfig) *Decoder {
+	if config == nil {
+		config = NewParserConfig()
+	}
+
+	return &Decoder{
+		reader: r,
+		config: config,
+		parser: NewParser(nil, config),
+		line:   1,
+		column: 1,
+	}
+}
+
+// Decode reads the whole input and parses it as a single JSON value, like Parser.Parse.
+//
+// Errors carry the line, column and byte offset of the failure in the input. An error returned
+// by the reader, other than io.EOF, is returned as is.
+func (d *Decoder) Decode() (JSON, error) {
+	p := &d.parser
+	p.reset(nil, d.token)
+	p.dropSpaces = !d.config.PreserveTrivia
+
+	// the tokens of the value are no longer needed once it has been parsed
+	defer p.reset(nil, nil)
+
+	leading := p.ignoreWhitespacesOrComments()
+
+	if p.expectCurToken(EOF) {
+		if d.err != nil {
+			return nil, d.err
+		}
+		return nil, ErrJSONNoContent
+	}
+
+	node, err := p.parse()
+	if d.err != nil {
+		return nil, d.err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	trailing := p.ignoreWhitespacesOrComments()
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	if !p.expectCurToken(EOF) {
+		if isValueStart(p.curToken) {
+			return nil, WrapJSONMultipleContentError(p.curToken)
+		}
+		return nil, p.syntaxError(ErrJSONUnexpectedChar, p.curToken, "unexpected character after JSON data", "", EOF)
+	}
+
+	p.attachTrivia(node, leading, trailing, false)
+
+	return node, nil
+}
+
+// token lexes the next token of the input, reading more of it when the token may go on past the
+// bytes buffered so far. The literal of the token is copied out of the buffer, and the literal of
+// an ILLEGAL token is narrowed down to the malformed text.
+func (d *Decoder) token() Token {
+	for {
+		window := d.buf[d.pos:]
+
+		d.lexer = Lexer{input: window, config: d.config, line: 1}
+		d.lexer.readChar()
+
+		token := d.lexer.Token()
+		length := len(token.Literal)
+
+		if token.Kind == ILLEGAL {
+			// the malformed text is only narrowed down once the rune it ends in has been read in full
+			if !d.eof && d.err == nil && !utf8.FullRune(window[lastRuneStart(window):]) {
+				d.fill()
+				continue
+			}
+			length = recoveryLength(token, d.config)
+		}
+
+		// a token reaching the end of the buffer may be cut short, or be followed by
+		// characters deciding its kind, so it is lexed again once more input is read
+		if length >= len(window) && !isSingleByteToken(token) && !d.eof && d.err == nil {
+			d.fill()
+			continue
+		}
+
+		literal := token.Literal[:length]
+		token.Literal = bytes.Clone(literal)
+		token.Offset = d.offset + d.pos
+		if token.Line == 1 {
+			token.Column += d.column - 1
+		}
+		token.Line += d.line - 1
+
+		d.advance(literal)
+
+		return token
+	}
+}
+
+// lastRuneStart returns the position of the start of the last rune in buf, which may be cut short.
+func lastRuneStart(buf []byte) int {
+	for i := len(buf) - 1; i >= max(0, len(buf)-utf8.UTFMax); i-- {
+		if utf8.RuneStart(buf[i]) {
+			return i
+		}
+	}
+
+	return max(0, len(buf)-1)
+}
+
+// advance consumes the given bytes from the buffer, moving the line and column on.
+func (d *Decoder) advance(consumed []byte) {
+	d.pos += len(consumed)
+
+	if lines := bytes.Count(consumed, []byte("\n")); lines > 0 {
+		d.line += lines
+		d.column = len(consumed) - bytes.LastIndexByte(consumed, '\n')
+		return
+	}
+
+	d.column += len(consumed)
+}
+
+// fill drops the consumed bytes from the buffer, then reads more of the input: up to as many bytes
+// as the buffer holds, so a long token is lexed again a logarithmic number of times with readers
+// filling the space they are given.
+func (d *Decoder) fill() {
+	if d.pos > 0 {
+		n := copy(d.buf, d.buf[d.pos:])
+		d.buf = d.buf[:n]
+		d.offset += d.pos
+		d.pos = 0
+	}
+
+	target := len(d.buf) + max(len(d.buf), decoderChunkSize)
+	if cap(d.buf) < target {
+		buf := make([]byte, len(d.buf), target)
+		copy(buf, d.buf)
+		d.buf = buf
+	}
+
+	for emptyReads := 0; len(d.buf) < target; {
+		n, err := d.reader.Read(d.buf[len(d.buf):target])
+		d.buf = d.buf[:len(d.buf)+n]
+
+		if errors.Is(err, io.EOF) {
+			d.eof = true
+			return
+		}
+
+		if err != nil {
+			d.err = err
+			return
+		}
+
+		if n > 0 {
+			// a short read does not mean the reader is exhausted, but the token may be complete already
+			return
+		}
+
+		if emptyReads++; emptyReads >= maxEmptyReads {
+			d.err = io.ErrNoProgress
+			return
+		}
+	}
+}
+
+// isSingleByteToken reports whether token is made of a single byte whatever follows it, so it can be
+// used without reading further: punctuation, whitespace and the record separator of JSON text sequences.
+func isSingleByteToken(token Token) bool {
+	switch token.Kind {
+	case LEFT_CURLY_BRACE, RIGHT_CURLY_BRACE, LEFT_SQUARE_BRACE, RIGHT_SQUARE_BRACE, COMMA, COLON, WHITESPACE:
+		return true
+	case ILLEGAL:
+		return isRecordSeparator(token)
+	default:
+		return false
+	}
+}
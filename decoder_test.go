@@ -0,0 +1,208 @@
+package jsonvx
+
+import (
+	"errors"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// decoderReaders returns readers of input splitting it differently, so tokens cross read boundaries.
+func decoderReaders(input string) map[string]io.Reader {
+	return map[string]io.Reader{
+		"whole":    strings.NewReader(input),
+		"one byte": iotest.OneByteReader(strings.NewReader(input)),
+		"half":     iotest.HalfReader(strings.NewReader(input)),
+		"data err": iotest.DataErrReader(strings.NewReader(input)),
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	json5 := NewParserConfig(func(c *ParserConfig) {
+		*c = *JSON5Config()
+		c.PreserveTrivia = true
+	})
+
+	var tests = []struct {
+		msg   string
+		input string
+		cfg   *ParserConfig
+	}{
+		{msg: "Decode scalar", input: `42`},
+		{msg: "Decode keywords", input: `[true, false, null]`},
+		{msg: "Decode nested containers", input: "{\n  \"a\": [1, 2.5, -3e10],\n  \"b\": {\"c\": \"d\"}\n}\n"},
+		{msg: "Decode escapes", input: "[\"\u00e9\\\"\\\\\\/\\b\\f\\n\\r\\t\"]"},
+		{msg: "Decode comments", input: "// head\n[1, /* two */ 2] // tail\n", cfg: json5},
+		{msg: "Decode relaxed numbers", input: `[+1, .5, 5., 0xFF, Infinity, -Infinity, NaN]`, cfg: json5},
+		{msg: "Decode relaxed strings", input: "{unquoted: 'single', \"esc\": \"a\\\nb\\q\"}", cfg: json5},
+		{msg: "Decode trailing commas", input: "{\"a\": [1, 2,],}", cfg: json5},
+		{msg: "Decode extra whitespace", input: "[1,\v2,\f3]", cfg: json5},
+		{msg: "Decode long string", input: `["` + strings.Repeat("abc", 1500) + `"]`},
+		{msg: "Decode long comment", input: "/*" + strings.Repeat(" * ", 1500) + "*/ {}", cfg: json5},
+		{msg: "Decode many values", input: "[" + strings.Repeat("1234567890, ", 1000) + "0]"},
+	}
+
+	for _, test := range tests {
+		parser := NewParser([]byte(test.input), test.cfg)
+		expected, err := parser.Parse()
+		if err != nil {
+			t.Fatalf("%s: unexpected parse error: %v", test.msg, err)
+		}
+
+		for name, reader := range decoderReaders(test.input) {
+			t.Run(test.msg+" with "+name+" reader", func(t *testing.T) {
+				node, err := NewDecoder(reader, test.cfg).Decode()
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				if !node.Equal(expected) {
+					t.Errorf("got %s, expected %s", node, expected)
+				}
+
+				if node.Span() != expected.Span() {
+					t.Errorf("got span %s, expected %s", node.Span(), expected.Span())
+				}
+
+				if test.cfg != nil && test.cfg.PreserveTrivia {
+					if got := printString(t, node); got != test.input {
+						t.Errorf("got %q printed, expected %q", got, test.input)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestDecoderErrors(t *testing.T) {
+	var tests = []struct {
+		msg   string
+		input string
+		cfg   *ParserConfig
+	}{
+		{msg: "No content", input: " \n "},
+		{msg: "Missing comma", input: "[1\n 2]"},
+		{msg: "Unterminated string", input: "[\"abc\n, 2]"},
+		{msg: "Unterminated block comment", input: "[1, /* 2]", cfg: NewParserConfig(WithAllowBlockComments(true))},
+		{msg: "Disallowed hex number", input: "[1,\n 0x1]"},
+		{msg: "Truncated keyword", input: `[tru`},
+		{msg: "Leading zero", input: `{"a": 01}`},
+		{msg: "Multiple values", input: "1\n2"},
+		{msg: "Unexpected character after value", input: `null x`},
+		{msg: "Illegal multi-byte character", input: "[1,\u2028 2]"},
+		{msg: "Illegal multi-byte character in number", input: "[1\u00e9]"},
+		{msg: "Duplicate key", input: `{"a": 1, "a": 2}`, cfg: NewParserConfig(WithDuplicateKeys(DuplicateKeysError))},
+	}
+
+	for _, test := range tests {
+		parser := NewParser([]byte(test.input), test.cfg)
+		_, expected := parser.Parse()
+		if expected == nil {
+			t.Fatalf("%s: expected a parse error", test.msg)
+		}
+
+		for name, reader := range decoderReaders(test.input) {
+			t.Run(test.msg+" with "+name+" reader", func(t *testing.T) {
+				_, err := NewDecoder(reader, test.cfg).Decode()
+
+				if err == nil || err.Error() != expected.Error() {
+					t.Errorf("got %v, expected %v", err, expected)
+				}
+
+				var syntaxErr, expectedSyntaxErr *SyntaxError
+				if errors.As(expected, &expectedSyntaxErr) {
+					if !errors.As(err, &syntaxErr) {
+						t.Fatalf("got %T, expected *SyntaxError", err)
+					}
+
+					if syntaxErr.Span() != expectedSyntaxErr.Span() {
+						t.Errorf("got span %s, expected %s", syntaxErr.Span(), expectedSyntaxErr.Span())
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestDecoderReaderError(t *testing.T) {
+	readErr := errors.New("connection reset")
+	reader := io.MultiReader(strings.NewReader(`{"a": [1, 2`), iotest.ErrReader(readErr))
+
+	_, err := NewDecoder(reader, nil).Decode()
+
+	if !errors.Is(err, readErr) {
+		t.Errorf("got %v, expected %v", err, readErr)
+	}
+}
+
+func TestDecoderBufferSize(t *testing.T) {
+	input := "[" + strings.Repeat(`{"name": "item", "tags": ["a", "b"]},`+"\n", 20000) + "null]"
+
+	decoder := NewDecoder(strings.NewReader(input), nil)
+	node, err := decoder.Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if arr, _ := AsArray(node); arr.Len() != 20001 {
+		t.Errorf("got %d items, expected 20001", arr.Len())
+	}
+
+	if cap(decoder.buf) > 2*decoderChunkSize {
+		t.Errorf("got a %d bytes buffer for a %d bytes input, expected at most %d", cap(decoder.buf), len(input), 2*decoderChunkSize)
+	}
+}
+
+// heapReader samples the live heap every few reads of the underlying reader.
+type heapReader struct {
+	reader io.Reader
+	reads  int
+	peak   uint64
+}
+
+func (r *heapReader) Read(p []byte) (int, error) {
+	if r.reads++; r.reads%16 == 0 {
+		r.peak = max(r.peak, liveHeap())
+	}
+
+	return r.reader.Read(p)
+}
+
+// liveHeap returns the size of the heap reachable after a garbage collection.
+func liveHeap() uint64 {
+	runtime.GC()
+
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	return stats.HeapAlloc
+}
+
+func TestDecoderMemory(t *testing.T) {
+	item := `{"id": 12345, "tags": ["a", "b"]}`
+	compact := "[" + strings.Repeat(item+",", 20000) + "null]"
+	// the same values, with many more whitespace tokens than other tokens
+	spaced := "[" + strings.Repeat(item+","+strings.Repeat(" ", 200)+"\n", 20000) + "null]"
+
+	base := liveHeap()
+	node, err := NewDecoder(strings.NewReader(compact), nil).Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tree := liveHeap() - base
+	runtime.KeepAlive(node)
+	node = nil
+
+	base = liveHeap()
+	reader := &heapReader{reader: strings.NewReader(spaced)}
+	if _, err := NewDecoder(reader, nil).Decode(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the tokens of the values, whitespace included, are not held until the end of the input
+	if peak := reader.peak - min(reader.peak, base); peak > 2*tree {
+		t.Errorf("got a peak heap of %d bytes, expected at most %d for a tree of %d bytes", peak, 2*tree, tree)
+	}
+}
@@ -0,0 +1,307 @@
+package jsonvx
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxSnippetLength is the maximum number of bytes of an illegal token quoted in diagnostics.
+const maxSnippetLength = 32
+
+// diagnose fills in the message and hint of a SyntaxError caused by an ILLEGAL token, and narrows
+// the quoted literal down to the offending text. The hint names the ParserConfig flag that would
+// accept the input, when it is disabled in cfg; a nil cfg is treated as the strict default.
+func (e *SyntaxError) diagnose(cfg *ParserConfig) {
+	if e.Token.Kind != ILLEGAL {
+		return
+	}
+
+	if cfg == nil {
+		cfg = NewParserConfig()
+	}
+
+	literal := e.Token.Literal
+	snippet := firstRune(literal)
+	message, flag := "", ""
+
+	switch e.Token.SubKind {
+	case INVALID_CHARACTER:
+		snippet, message, flag = diagnoseCharacter(literal, cfg)
+	case INVALID_WHITESPACE:
+		char, _ := utf8.DecodeRune(literal)
+		message = fmt.Sprintf("whitespace character %U is not allowed", char)
+		flag = "AllowExtraWS"
+	case INVALID_NULL:
+		message = "bad null literal"
+	case INVALID_TRUE, INVALID_FALSE:
+		message = "bad boolean literal"
+	case INVALID_COMMENT:
+		message = "'/' must be followed by '/' or '*' to start a comment"
+	case INVALID_LINE_COMMENT:
+		snippet = "//"
+		message = "line comments are not allowed"
+		flag = "AllowLineComments"
+	case INVALID_BLOCK_COMMENT:
+		snippet = "/*"
+		if cfg.AllowBlockComments {
+			message = "unterminated block comment"
+		} else {
+			message = "block comments are not allowed"
+			flag = "AllowBlockComments"
+		}
+	case INVALID_STRING:
+		snippet = firstLine(literal)
+		if literal[0] == '\'' && !cfg.AllowSingleQuotes {
+			message = "single-quoted strings are not allowed"
+			flag = "AllowSingleQuotes"
+		} else {
+			message = "unterminated string literal"
+		}
+	case INVALID_HEX_STRING:
+		snippet = escapeAt(literal, 'u')
+		message = "bad Unicode escape, \\u must be followed by 4 hexadecimal digits"
+	case INVALID_NEWLINE_STRING:
+		snippet = firstLine(literal)
+		message = "escaped line breaks are not allowed in strings"
+		flag = "AllowNewlineInStrings"
+	case INVALID_ESCAPED_STRING:
+		snippet = invalidEscape(literal)
+		message = fmt.Sprintf("bad escape character %s", snippet)
+		flag = "AllowOtherEscapeChars"
+	case INVALID_LEADING_ZERO:
+		snippet = numberText(literal)
+		message = "numbers cannot have leading zeros"
+	case INVALID_LEADING_PLUS:
+		snippet = numberText(literal)
+		message = "numbers cannot start with '+'"
+		flag = "AllowLeadingPlus"
+	case INVALID_NaN:
+		snippet = numberText(literal)
+		message = "NaN is not allowed"
+		flag = "AllowNaN"
+	case INVALID_INF:
+		snippet = numberText(literal)
+		message = "Infinity is not allowed"
+		flag = "AllowInfinity"
+	case INVALID_POINT_EDGE_DOT:
+		snippet = numberText(literal)
+		if mantissa, _, _ := bytes.Cut(bytes.TrimLeft([]byte(snippet), "+-"), []byte("e")); bytes.HasPrefix(mantissa, []byte(".")) {
+			message = "missing digits before decimal point"
+		} else {
+			message = "missing digits after decimal point"
+		}
+		flag = "AllowPointEdgeNumbers"
+	case INVALID_HEX_NUMBER:
+		snippet = numberText(literal)
+		if cfg.AllowHexNumbers {
+			message = "bad hexadecimal number"
+		} else {
+			message = "hexadecimal numbers are not allowed"
+			flag = "AllowHexNumbers"
+		}
+	default:
+		message = "unexpected character"
+	}
+
+	e.Message = message
+	e.detail = fmt.Sprintf("%q at line %d, column %d", snippet, e.Line, e.Column)
+	e.length = offendingLength(literal, snippet)
+
+	e.Hint = configHint(flag, cfg)
+}
+
+// configHint returns the hint suggesting to enable the given ParserConfig flag, if any,
+// unless the flag is enabled in cfg already.
+func configHint(flag string, cfg *ParserConfig) string {
+	if flag == "" {
+		return ""
+	}
+
+	if cfg != nil {
+		for _, f := range cfg.flags() {
+			if f.name == flag && f.value {
+				return ""
+			}
+		}
+	}
+
+	return fmt.Sprintf("enable ParserConfig.%s to accept it", flag)
+}
+
+// syntaxError returns a SyntaxError for the offending token, described by message and by the flag
+// naming the ParserConfig option that would accept the input. ILLEGAL tokens get a diagnosis of their
+// own, which a non-empty message only replaces for a mere unexpected character.
+func (p *Parser) syntaxError(err error, token Token, message, flag string, expected ...TokenKind) error {
+	syntaxErr := newSyntaxError(err, token, p.config, expected...)
+
+	if token.Kind != ILLEGAL || (message != "" && token.SubKind == INVALID_CHARACTER) {
+		syntaxErr.Message = message
+		syntaxErr.Hint = configHint(flag, p.config)
+	}
+
+	return syntaxErr
+}
+
+// diagnoseCharacter describes an INVALID_CHARACTER token, which the lexer reports for malformed
+// numbers, malformed literals and unquoted strings as well as for unexpected characters.
+func diagnoseCharacter(literal []byte, cfg *ParserConfig) (snippet, message, flag string) {
+	first := literal[0]
+
+	switch {
+	case isDigit(first) || first == '+' || first == '-' || first == '.':
+		return diagnoseNumber(numberText(literal))
+	case first < utf8.RuneSelf && isPossibleJSIdentifier(first):
+		word := identifierText(literal)
+
+		for _, keyword := range []string{"null", "true", "false"} {
+			if len(word) < len(keyword) && keyword[:len(word)] == word {
+				return word, fmt.Sprintf("incomplete literal, expected %s", keyword), ""
+			}
+		}
+
+		if !cfg.AllowUnquoted {
+			return word, "strings must be quoted", "AllowUnquoted"
+		}
+
+		return word, "unquoted strings are only allowed as object keys", ""
+	default:
+		return firstRune(literal), "unexpected character", ""
+	}
+}
+
+// diagnoseNumber describes a malformed number.
+func diagnoseNumber(number string) (snippet, message, flag string) {
+	unsigned := number
+	if len(unsigned) > 0 && (unsigned[0] == '+' || unsigned[0] == '-') {
+		unsigned = unsigned[1:]
+	}
+
+	last := number[len(number)-1]
+
+	switch {
+	case unsigned == "" && number[0] == '-':
+		return number, "no number after minus sign", ""
+	case unsigned == "":
+		return number, "no number after plus sign", ""
+	case len(unsigned) > 1 && unsigned[0] == '0' && (unsigned[1] == 'x' || unsigned[1] == 'X'):
+		if len(unsigned) == 2 {
+			return number, "missing hexadecimal digits after 0x", ""
+		}
+		return number, "bad hexadecimal number", ""
+	case last == 'e' || last == 'E':
+		return number, "missing digits after exponent indicator", ""
+	case (last == '+' || last == '-') && len(number) > 1 && (number[len(number)-2] == 'e' || number[len(number)-2] == 'E'):
+		return number, "missing digits after exponent sign", ""
+	default:
+		return number, "bad number", ""
+	}
+}
+
+// offendingLength returns the number of bytes from the start of literal up to the end of snippet.
+func offendingLength(literal []byte, snippet string) int {
+	if index := bytes.Index(literal, []byte(snippet)); index >= 0 {
+		return index + len(snippet)
+	}
+
+	// the snippet was shortened
+	snippet = strings.TrimSuffix(snippet, "...")
+	if index := bytes.Index(literal, []byte(snippet)); index >= 0 {
+		return index + len(snippet)
+	}
+
+	return len(firstRune(literal))
+}
+
+// numberText returns the leading characters of literal that may belong to a number.
+func numberText(literal []byte) string {
+	end := 0
+	for end < len(literal) && end < maxSnippetLength && isPossibleNumber(literal[end]) {
+		end++
+	}
+
+	if end == 0 {
+		return firstRune(literal)
+	}
+
+	return string(literal[:end])
+}
+
+// identifierText returns the leading characters of literal that may belong to an identifier.
+func identifierText(literal []byte) string {
+	end := 0
+	for end < len(literal) && end < maxSnippetLength && literal[end] < utf8.RuneSelf && isPossibleJSIdentifier(literal[end]) {
+		end++
+	}
+
+	if end == 0 {
+		return firstRune(literal)
+	}
+
+	return string(literal[:end])
+}
+
+// firstLine returns literal up to the end of its first line, shortened to maxSnippetLength bytes.
+func firstLine(literal []byte) string {
+	if index := bytes.IndexAny(literal, "\r\n"); index >= 0 {
+		literal = literal[:index]
+	}
+
+	return shorten(literal)
+}
+
+// firstRune returns the first character of literal.
+func firstRune(literal []byte) string {
+	if len(literal) == 0 {
+		return ""
+	}
+
+	_, size := utf8.DecodeRune(literal)
+	return string(literal[:size])
+}
+
+// escapeAt returns the first escape sequence of literal starting with `\` followed by char,
+// along with the few characters after it.
+func escapeAt(literal []byte, char byte) string {
+	index := bytes.Index(literal, []byte{'\\', char})
+	if index < 0 {
+		return firstLine(literal)
+	}
+
+	end := min(index+6, len(literal))
+	return string(literal[index:end])
+}
+
+// invalidEscape returns the first escape sequence of a string literal that strict JSON does not accept.
+func invalidEscape(literal []byte) string {
+	for i := 1; i < len(literal)-1; i++ {
+		if literal[i] != '\\' {
+			continue
+		}
+
+		switch next := literal[i+1]; next {
+		case literal[0], '\\', '/', 'b', 'f', 'n', 'r', 't', 'u', '\n':
+			i++
+		default:
+			_, size := utf8.DecodeRune(literal[i+1:])
+			return string(literal[i : i+1+size])
+		}
+	}
+
+	return firstLine(literal)
+}
+
+// shorten cuts literal down to maxSnippetLength bytes, without splitting a character.
+func shorten(literal []byte) string {
+	if len(literal) <= maxSnippetLength {
+		return string(literal)
+	}
+
+	end := maxSnippetLength
+	for end > 0 && !utf8.RuneStart(literal[end]) {
+		end--
+	}
+
+	return string(literal[:end]) + "..."
+}
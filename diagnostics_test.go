@@ -0,0 +1,89 @@
+package jsonvx
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDiagnostics(t *testing.T) {
+	var tests = []struct {
+		msg             string
+		input           []byte
+		cfg             *ParserConfig
+		expectedMessage string
+		expectedHint    string
+		expectedError   string
+	}{
+		{msg: "Minus sign only", input: []byte(`-`), expectedMessage: "no number after minus sign"},
+		{msg: "Missing exponent digits", input: []byte(`[1e]`), expectedMessage: "missing digits after exponent indicator"},
+		{msg: "Missing exponent sign digits", input: []byte(`1e-`), expectedMessage: "missing digits after exponent sign"},
+		{msg: "Missing hexadecimal digits", input: []byte(`0x`), expectedMessage: "missing hexadecimal digits after 0x"},
+		{msg: "Missing digits after decimal point", input: []byte(`1.`), expectedMessage: "missing digits after decimal point", expectedHint: "enable ParserConfig.AllowPointEdgeNumbers to accept it"},
+		{msg: "Missing digits before decimal point", input: []byte(`-.5`), expectedMessage: "missing digits before decimal point", expectedHint: "enable ParserConfig.AllowPointEdgeNumbers to accept it"},
+		{msg: "Leading zero", input: []byte(`[01]`), expectedMessage: "numbers cannot have leading zeros", expectedError: `unexpected character in JSON input: numbers cannot have leading zeros: "01" at line 1, column 2`},
+		{msg: "Leading plus", input: []byte(`+1`), expectedMessage: "numbers cannot start with '+'", expectedHint: "enable ParserConfig.AllowLeadingPlus to accept it"},
+		{msg: "NaN", input: []byte(`NaN`), expectedMessage: "NaN is not allowed", expectedHint: "enable ParserConfig.AllowNaN to accept it"},
+		{msg: "Infinity", input: []byte(`-Infinity`), expectedMessage: "Infinity is not allowed", expectedHint: "enable ParserConfig.AllowInfinity to accept it"},
+		{msg: "Hexadecimal number", input: []byte(`0xFF`), expectedMessage: "hexadecimal numbers are not allowed", expectedHint: "enable ParserConfig.AllowHexNumbers to accept it"},
+		{msg: "Incomplete literal", input: []byte(`[tru]`), expectedMessage: "incomplete literal, expected true"},
+		{msg: "Unquoted string", input: []byte(`{key: 1}`), expectedMessage: "strings must be quoted", expectedHint: "enable ParserConfig.AllowUnquoted to accept it"},
+		{msg: "Unquoted string value", input: []byte(`{key: value}`), cfg: NewParserConfig(WithAllowUnquoted(true)), expectedMessage: "unquoted strings are only allowed as object keys"},
+		{msg: "Unexpected character", input: []byte(`@`), expectedMessage: "unexpected character"},
+		{msg: "Extra whitespace", input: []byte("\f1"), expectedMessage: "whitespace character U+000C is not allowed", expectedHint: "enable ParserConfig.AllowExtraWS to accept it"},
+		{msg: "Lone slash", input: []byte(`/ 1`), expectedMessage: "'/' must be followed by '/' or '*' to start a comment"},
+		{msg: "Line comment", input: []byte("// comment\n1"), expectedMessage: "line comments are not allowed", expectedHint: "enable ParserConfig.AllowLineComments to accept it"},
+		{msg: "Block comment", input: []byte(`/* comment */ 1`), expectedMessage: "block comments are not allowed", expectedHint: "enable ParserConfig.AllowBlockComments to accept it"},
+		{msg: "Unterminated block comment", input: []byte(`1 /* comment`), cfg: NewParserConfig(WithAllowBlockComments(true)), expectedMessage: "unterminated block comment"},
+		{msg: "Single-quoted string", input: []byte(`'text'`), expectedMessage: "single-quoted strings are not allowed", expectedHint: "enable ParserConfig.AllowSingleQuotes to accept it"},
+		{msg: "Unterminated string", input: []byte(`"text`), expectedMessage: "unterminated string literal"},
+		{msg: "Bad Unicode escape", input: []byte(`"\u12G4"`), expectedMessage: `bad Unicode escape, \u must be followed by 4 hexadecimal digits`},
+		{msg: "Bad escape character", input: []byte(`"a\qb"`), expectedMessage: `bad escape character \q`, expectedHint: "enable ParserConfig.AllowOtherEscapeChars to accept it"},
+		{msg: "Escaped line break", input: []byte("\"a\\\nb\""), expectedMessage: "escaped line breaks are not allowed in strings", expectedHint: "enable ParserConfig.AllowNewlineInStrings to accept it"},
+		{msg: "Trailing comma in array", input: []byte(`[1,]`), expectedMessage: "trailing comma is not allowed in arrays", expectedHint: "enable ParserConfig.AllowTrailingCommaArray to accept it"},
+		{msg: "Trailing comma in object", input: []byte(`{"a": 1,}`), expectedMessage: "trailing comma is not allowed in objects", expectedHint: "enable ParserConfig.AllowTrailingCommaObject to accept it"},
+		{msg: "Missing comma", input: []byte(`[1 2]`), expectedMessage: "expected ',' or ']' after array element"},
+		{msg: "Missing colon", input: []byte(`{"a" 1}`), expectedMessage: "expected ':' after property name"},
+		{msg: "Missing value", input: []byte(`[,]`), expectedMessage: "expected a value"},
+		{msg: "Missing property name", input: []byte(`{`), expectedMessage: "expected property name"},
+		{msg: "Missing property name after comma", input: []byte(`{"a": 1,`), cfg: NewParserConfig(WithAllowTrailingCommaObject(true)), expectedMessage: "expected property name"},
+		{msg: "Character after data", input: []byte(`null x`), expectedMessage: "unexpected character after JSON data"},
+		{
+			msg:             "Message with hint",
+			input:           []byte("[\n  .5\n]"),
+			expectedMessage: "missing digits before decimal point",
+			expectedHint:    "enable ParserConfig.AllowPointEdgeNumbers to accept it",
+			expectedError:   `unexpected character in JSON input: missing digits before decimal point: ".5" at line 2, column 3 (enable ParserConfig.AllowPointEdgeNumbers to accept it)`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			parser := NewParser(test.input, test.cfg)
+			_, err := parser.Parse()
+
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("got %v, expected a *SyntaxError", err)
+			}
+
+			if syntaxErr.Message != test.expectedMessage || syntaxErr.Hint != test.expectedHint {
+				t.Errorf("got (%q, %q), expected (%q, %q)", syntaxErr.Message, syntaxErr.Hint, test.expectedMessage, test.expectedHint)
+			}
+
+			if test.expectedError != "" && err.Error() != test.expectedError {
+				t.Errorf("got %q, expected %q", err.Error(), test.expectedError)
+			}
+		})
+	}
+}
+
+func TestConfigHint(t *testing.T) {
+	if got, expected := configHint("AllowNaN", NewParserConfig()), "enable ParserConfig.AllowNaN to accept it"; got != expected {
+		t.Errorf("got %q, expected %q", got, expected)
+	}
+
+	// a flag already enabled is not suggested
+	if got := configHint("AllowNaN", NewParserConfig(WithAllowNaN(true))); got != "" {
+		t.Errorf("got %q, expected no hint", got)
+	}
+}
@@ -0,0 +1,561 @@
+package jsonvx
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrInvalidDotPath is the error for a dot path that cannot be compiled.
+var ErrInvalidDotPath = errors.New("invalid dot path")
+
+// DotPath is a compiled dot path, a single-string query language in the style of GJSON:
+//
+//   - `name.first` selects members, and `children.1` items, by key or index.
+//   - `fav\.movie` escapes a dot, or any other special character, in a key.
+//   - `na*e` and `n?me` select the first member whose key matches the wildcards.
+//   - `children.#` is the number of items of an array, while `friends.#.first` applies the
+//     rest of the path to every item and gathers the results in an array.
+//   - `friends.#(last=="Murphy")` selects the first item matching a condition, and `friends.#(last=="Murphy")#`
+//     all of them. Conditions compare a path of the item with a JSON value using ==, !=, <, <=, >, >=,
+//     `%` (matches wildcards) or `!%` (does not match); `#(nets)` checks that the path exists and
+//     `#(=="fb")` compares the item itself.
+//   - `@reverse`, `@keys`, `@values` and `@this` are modifiers, which transform the current value.
+//   - `|` separates paths like `.` does, except that it applies the rest of the path to the result
+//     gathered by `#`, rather than to each item: `friends.#.first|0` is the first name of the first friend.
+type DotPath struct {
+	path  string
+	pipes [][]dotComponent
+}
+
+type dotKind int
+
+const (
+	dotKey dotKind = iota
+	dotCount
+	dotQuery
+	dotModifier
+)
+
+// dotComponent is a part of a dot path, between two separators.
+type dotComponent struct {
+	kind     dotKind
+	name     string        // name is the unescaped key of a key, or the name of a modifier.
+	pattern  string        // pattern is the key of a key with wildcards, with its escapes.
+	wildcard bool          // wildcard reports whether the key holds unescaped wildcards.
+	cond     *dotCondition // cond is the condition of a query.
+	all      bool          // all reports whether a query selects every matching item (`#(...)#`).
+}
+
+// dotCondition is the condition of a query, `#(left operator value)`.
+type dotCondition struct {
+	left     *DotPath // left is the path of the compared value in the item, nil for the item itself.
+	operator string   // operator is empty for an existence test.
+	value    JSON
+}
+
+// dotModifiers lists the supported modifiers.
+var dotModifiers = map[string]func(JSON) JSON{
+	"reverse": reverseModifier,
+	"keys":    keysModifier,
+	"values":  valuesModifier,
+	"this":    func(node JSON) JSON { return node },
+}
+
+// CompileDotPath parses a dot path, so it can be run against any number of nodes.
+// It fails with an error wrapping ErrInvalidDotPath if the path is empty or malformed,
+// e.g. with an unclosed query, an invalid condition value or an unknown modifier.
+func CompileDotPath(path string) (*DotPath, error) {
+	p := dotParser{path: path}
+
+	pipes, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	return &DotPath{path: path, pipes: pipes}, nil
+}
+
+// QueryDotPath compiles the dot path and runs it against node, see CompileDotPath and DotPath.Query.
+func QueryDotPath(node JSON, path string) (JSON, error) {
+	dp, err := CompileDotPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return dp.Query(node)
+}
+
+// String returns the path the DotPath was compiled from.
+func (dp *DotPath) String() string {
+	return dp.path
+}
+
+// Query returns the value the path selects from node. Values gathered by `#` and the results of
+// modifiers are new nodes, while the other selected values are the nodes of the tree.
+//
+// It fails with the errors of QueryPath: ErrKeyNotFound for a missing member, or for a query
+// matching no item, ErrExpectedIndex and ErrIndexOutOfRange for a missing item, ErrQueryExceedsDepth
+// when a key is left after reaching a scalar value, and ErrInvalidJSONType when `#` is applied to
+// anything but an array.
+func (dp *DotPath) Query(node JSON) (JSON, error) {
+	if isNilNode(node) {
+		return nil, ErrInvalidJSONType
+	}
+
+	current := node
+	for _, pipe := range dp.pipes {
+		var err error
+		if current, err = evalDotComponents(current, pipe); err != nil {
+			return nil, err
+		}
+	}
+
+	return current, nil
+}
+
+// evalDotComponents applies the components to node in turn.
+func evalDotComponents(node JSON, components []dotComponent) (JSON, error) {
+	for i, component := range components {
+		var err error
+
+		switch component.kind {
+		case dotKey:
+			node, err = selectDotKey(node, &component)
+		case dotModifier:
+			node = dotModifiers[component.name](node)
+		case dotCount, dotQuery:
+			arr, ok := node.(*Array)
+			if !ok || arr == nil {
+				return nil, fmt.Errorf("%w: '#' expects an array, got %T", ErrInvalidJSONType, node)
+			}
+
+			rest := components[i+1:]
+
+			if component.kind == dotCount {
+				if len(rest) == 0 {
+					return marshalNumber(strconv.Itoa(arr.Len()), INTEGER), nil
+				}
+				return mapDotComponents(arr.Items, rest), nil
+			}
+
+			if component.all {
+				var matched []JSON
+				for _, item := range arr.Items {
+					if component.cond.match(item) {
+						matched = append(matched, item)
+					}
+				}
+				return mapDotComponents(matched, rest), nil
+			}
+
+			node, err = nil, fmt.Errorf("%w: no item matches the query", ErrKeyNotFound)
+			for _, item := range arr.Items {
+				if component.cond.match(item) {
+					node, err = item, nil
+					break
+				}
+			}
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return node, nil
+}
+
+// mapDotComponents applies the components to every item, and gathers the values found into a new array.
+func mapDotComponents(items []JSON, components []dotComponent) *Array {
+	results := []JSON{}
+
+	for _, item := range items {
+		if result, err := evalDotComponents(item, components); err == nil {
+			results = append(results, result)
+		}
+	}
+
+	return newArray(results, nil)
+}
+
+// selectDotKey selects the member of an object, or the item of an array, for a key.
+func selectDotKey(node JSON, component *dotComponent) (JSON, error) {
+	switch val := node.(type) {
+	case *Object:
+		if val == nil {
+			return nil, ErrInvalidJSONType
+		}
+
+		if !component.wildcard {
+			index, ok := val.lookup([]byte(component.name))
+			if !ok {
+				return nil, fmt.Errorf("%w: %q", ErrKeyNotFound, component.name)
+			}
+			return val.Properties[index].value, nil
+		}
+
+		for _, prop := range val.Properties {
+			if matchWildcard(component.pattern, string(prop.key)) {
+				return prop.value, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %q", ErrKeyNotFound, component.pattern)
+	case *Array:
+		if val == nil {
+			return nil, ErrInvalidJSONType
+		}
+
+		if component.wildcard || strings.TrimLeft(component.name, "0123456789") != "" {
+			return nil, fmt.Errorf("%w: %q", ErrExpectedIndex, component.name)
+		}
+
+		index, err := strconv.Atoi(component.name)
+		if err != nil || index >= val.Len() {
+			return nil, fmt.Errorf("%w: %s", ErrIndexOutOfRange, component.name)
+		}
+		return val.Items[index], nil
+	case *Null, *Boolean, *Number, *String:
+		return nil, ErrQueryExceedsDepth
+	default:
+		return nil, ErrInvalidJSONType
+	}
+}
+
+// match reports whether an item satisfies the condition.
+func (c *dotCondition) match(item JSON) bool {
+	left := item
+	if c.left != nil {
+		var err error
+		if left, err = c.left.Query(item); err != nil {
+			return false
+		}
+	}
+
+	switch c.operator {
+	case "":
+		return true
+	case "%", "!%":
+		str, ok := left.(*String)
+		if !ok {
+			return false
+		}
+		strVal, err := str.Value()
+		if err != nil {
+			return false
+		}
+		pattern, _ := c.value.(*String).Value()
+		return matchWildcard(pattern, strVal) == (c.operator == "%")
+	default:
+		return compareValues(c.operator, pathValue{left}, pathValue{c.value})
+	}
+}
+
+// matchWildcard reports whether str matches pattern, where `*` matches any sequence of
+// characters, `?` any single character, and `\` escapes the character following it.
+//
+// Only the last `*` is backtracked to, which is enough for `*` to match any sequence,
+// so the time taken is bounded by the product of the lengths of pattern and str.
+func matchWildcard(pattern, str string) bool {
+	p, s := 0, 0
+	// starP is the position in pattern after the last star, or -1 before any,
+	// and starS the position in str where the star ends for now
+	starP, starS := -1, 0
+
+	for s < len(str) {
+		if p < len(pattern) {
+			char, size := utf8.DecodeRuneInString(pattern[p:])
+
+			switch char {
+			case '*':
+				p += size
+				starP, starS = p, s
+				continue
+			case '?':
+				_, n := utf8.DecodeRuneInString(str[s:])
+				p, s = p+size, s+n
+				continue
+			case '\\':
+				if p+size < len(pattern) {
+					p += size
+					char, size = utf8.DecodeRuneInString(pattern[p:])
+				}
+			}
+
+			if strChar, n := utf8.DecodeRuneInString(str[s:]); strChar == char {
+				p, s = p+size, s+n
+				continue
+			}
+		}
+
+		if starP < 0 {
+			return false
+		}
+
+		// the last star takes one more character
+		_, n := utf8.DecodeRuneInString(str[starS:])
+		p, s = starP, starS+n
+		starS = s
+	}
+
+	// only stars may be left of the pattern
+	return strings.TrimLeft(pattern[p:], "*") == ""
+}
+
+// reverseModifier implements @reverse: the items of an array, or the members of an object, in reverse order.
+func reverseModifier(node JSON) JSON {
+	switch val := node.(type) {
+	case *Array:
+		items := make([]JSON, val.Len())
+		for i, item := range val.Items {
+			items[len(items)-1-i] = item
+		}
+		return newArray(items, nil)
+	case *Object:
+		props := make([]KeyValue, val.Len())
+		for i, prop := range val.Properties {
+			props[len(props)-1-i] = prop
+		}
+		return newObject(props, nil)
+	default:
+		return node
+	}
+}
+
+// keysModifier implements @keys: the keys of an object, as an array of strings.
+func keysModifier(node JSON) JSON {
+	keys := []JSON{}
+
+	if obj, ok := node.(*Object); ok {
+		for _, prop := range obj.Properties {
+			keys = append(keys, marshalString(string(prop.key)))
+		}
+	}
+
+	return newArray(keys, nil)
+}
+
+// valuesModifier implements @values: the values of an object, as an array. An array is returned as it is.
+func valuesModifier(node JSON) JSON {
+	switch val := node.(type) {
+	case *Array:
+		return val
+	case *Object:
+		values := make([]JSON, val.Len())
+		for i, prop := range val.Properties {
+			values[i] = prop.value
+		}
+		return newArray(values, nil)
+	default:
+		return newArray([]JSON{}, nil)
+	}
+}
+
+// dotParser parses a dot path.
+type dotParser struct {
+	path string
+	pos  int
+}
+
+// errorf returns an error under ErrInvalidDotPath, at the current position.
+func (p *dotParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: %s at offset %d of %q", ErrInvalidDotPath, fmt.Sprintf(format, args...), p.pos, p.path)
+}
+
+// parse parses the path into groups of components, separated by '|'.
+func (p *dotParser) parse() ([][]dotComponent, error) {
+	pipes := [][]dotComponent{nil}
+
+	for {
+		component, err := p.parseComponent()
+		if err != nil {
+			return nil, err
+		}
+
+		last := len(pipes) - 1
+		pipes[last] = append(pipes[last], component)
+
+		if p.pos == len(p.path) {
+			return pipes, nil
+		}
+
+		switch p.path[p.pos] {
+		case '.':
+		case '|':
+			pipes = append(pipes, nil)
+		default:
+			return nil, p.errorf("unexpected %q", p.path[p.pos:])
+		}
+		p.pos++
+	}
+}
+
+// atSeparator reports whether the current position is at the end of a component.
+func (p *dotParser) atSeparator() bool {
+	return p.pos == len(p.path) || p.path[p.pos] == '.' || p.path[p.pos] == '|'
+}
+
+func (p *dotParser) parseComponent() (dotComponent, error) {
+	switch {
+	case p.atSeparator():
+		return dotComponent{}, p.errorf("expected a key")
+	case p.path[p.pos] == '#':
+		p.pos++
+		if p.atSeparator() {
+			return dotComponent{kind: dotCount}, nil
+		}
+		if p.path[p.pos] != '(' {
+			return dotComponent{}, p.errorf("expected '(' after '#'")
+		}
+		return p.parseQuery()
+	case p.path[p.pos] == '@':
+		start := p.pos + 1
+		for !p.atSeparator() {
+			p.pos++
+		}
+
+		name := p.path[start:p.pos]
+		if _, ok := dotModifiers[name]; !ok {
+			return dotComponent{}, p.errorf("unknown modifier %q", name)
+		}
+		return dotComponent{kind: dotModifier, name: name}, nil
+	default:
+		return p.parseKey(), nil
+	}
+}
+
+// parseKey parses a key, up to the next unescaped separator.
+func (p *dotParser) parseKey() dotComponent {
+	var name strings.Builder
+	start, wildcard := p.pos, false
+
+	for !p.atSeparator() {
+		switch char := p.path[p.pos]; char {
+		case '\\':
+			if p.pos+1 < len(p.path) {
+				p.pos++
+			}
+			name.WriteByte(p.path[p.pos])
+		case '*', '?':
+			wildcard = true
+			name.WriteByte(char)
+		default:
+			name.WriteByte(char)
+		}
+		p.pos++
+	}
+
+	return dotComponent{kind: dotKey, name: name.String(), pattern: p.path[start:p.pos], wildcard: wildcard}
+}
+
+// parseQuery parses `(condition)` or `(condition)#`, following '#'.
+func (p *dotParser) parseQuery() (dotComponent, error) {
+	start := p.pos + 1
+
+	end, ok := scanDotCondition(p.path, start, ')')
+	if !ok {
+		return dotComponent{}, p.errorf("unclosed query")
+	}
+
+	cond, err := p.parseCondition(p.path[start:end])
+	if err != nil {
+		return dotComponent{}, err
+	}
+	p.pos = end + 1
+
+	all := p.pos < len(p.path) && p.path[p.pos] == '#'
+	if all {
+		p.pos++
+	}
+
+	if !p.atSeparator() {
+		return dotComponent{}, p.errorf("unexpected %q after query", p.path[p.pos:])
+	}
+
+	return dotComponent{kind: dotQuery, cond: cond, all: all}, nil
+}
+
+// parseCondition parses the condition of a query: a path, optionally followed by an operator and a JSON value.
+func (p *dotParser) parseCondition(cond string) (*dotCondition, error) {
+	opStart, _ := scanDotCondition(cond, 0, 0)
+
+	leftStr := strings.TrimSpace(cond[:opStart])
+	rest := cond[opStart:]
+
+	var operator string
+	for _, op := range []string{"==", "!=", "<=", ">=", "!%", "=", "<", ">", "%"} {
+		if strings.HasPrefix(rest, op) {
+			operator = op
+			break
+		}
+	}
+	if operator == "" && rest != "" {
+		return nil, p.errorf("invalid condition %q", cond)
+	}
+
+	c := &dotCondition{operator: operator}
+
+	if leftStr != "" {
+		left, err := CompileDotPath(leftStr)
+		if err != nil {
+			return nil, err
+		}
+		c.left = left
+	} else if operator == "" {
+		return nil, p.errorf("empty condition")
+	}
+
+	if operator == "" {
+		return c, nil
+	}
+	if operator == "=" {
+		c.operator = "=="
+	}
+
+	valueStr := strings.TrimSpace(rest[len(operator):])
+	parser := NewParser([]byte(valueStr), nil)
+	value, err := parser.Parse()
+	if err != nil {
+		return nil, p.errorf("invalid value %q in condition: %v", valueStr, err)
+	}
+
+	if _, ok := value.(*String); !ok && (operator == "%" || operator == "!%") {
+		return nil, p.errorf("the %s operator expects a string", operator)
+	}
+	c.value = value
+
+	return c, nil
+}
+
+// scanDotCondition returns the position of the first stop byte in str from start, skipping escaped
+// characters, JSON strings and nested parentheses. A zero stop stops at the first comparison operator
+// instead, or at the end of str. It reports false if no stop byte is found.
+func scanDotCondition(str string, start int, stop byte) (int, bool) {
+	depth := 0
+
+	for i := start; i < len(str); i++ {
+		switch char := str[i]; {
+		case char == '\\':
+			i++
+		case char == '"':
+			for i++; i < len(str) && str[i] != '"'; i++ {
+				if str[i] == '\\' {
+					i++
+				}
+			}
+		case char == '(':
+			depth++
+		case char == ')' && depth > 0:
+			depth--
+		case depth > 0:
+		case char == stop:
+			return i, true
+		case stop == 0 && strings.IndexByte("=!<>%", char) >= 0:
+			return i, true
+		}
+	}
+
+	return len(str), stop == 0
+}
@@ -0,0 +1,170 @@
+package jsonvx
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const dotPathInput = `{
+	"name": {"first": "Tom", "last": "Anderson"},
+	"age": 37,
+	"children": ["Sara", "Alex", "Jack"],
+	"fav.movie": "Deer Hunter",
+	"a*b": 1,
+	"friends": [
+		{"first": "Dale", "last": "Murphy", "age": 44, "nets": ["ig", "fb", "tw"]},
+		{"first": "Roger", "last": "Craig", "age": 68, "nets": ["fb", "tw"]},
+		{"first": "Jane", "last": "Murphy", "age": 47, "nets": ["ig", "tw"]}
+	]
+}`
+
+func TestDotPath(t *testing.T) {
+	var tests = []struct {
+		msg         string
+		path        string
+		expected    string
+		expectedErr error
+	}{
+		{msg: "Query member", path: `age`, expected: `37`},
+		{msg: "Query nested member", path: `name.last`, expected: `"Anderson"`},
+		{msg: "Query item", path: `children.1`, expected: `"Alex"`},
+		{msg: "Query escaped dot", path: `fav\.movie`, expected: `"Deer Hunter"`},
+		{msg: "Query escaped wildcard", path: `a\*b`, expected: `1`},
+		{msg: "Query star wildcard", path: `child*.2`, expected: `"Jack"`},
+		{msg: "Query question mark wildcard", path: `c?ildren.0`, expected: `"Sara"`},
+		{msg: "Query count", path: `children.#`, expected: `3`},
+		{msg: "Query every item", path: `friends.#.first`, expected: `["Dale","Roger","Jane"]`},
+		{msg: "Query nested counts", path: `friends.#.nets.#`, expected: `[3,2,2]`},
+		{msg: "Query first match", path: `friends.#(last=="Murphy").first`, expected: `"Dale"`},
+		{msg: "Query all matches", path: `friends.#(last=="Murphy")#.first`, expected: `["Dale","Jane"]`},
+		{msg: "Query numeric condition", path: `friends.#(age>45)#.first`, expected: `["Roger","Jane"]`},
+		{msg: "Query condition with spaces", path: `friends.#(age <= 47)#.age`, expected: `[44,47]`},
+		{msg: "Query single equal sign", path: `friends.#(first="Jane").age`, expected: `47`},
+		{msg: "Query not equal", path: `friends.#(last!="Murphy")#.first`, expected: `["Roger"]`},
+		{msg: "Query like", path: `friends.#(first%"D*").last`, expected: `"Murphy"`},
+		{msg: "Query not like", path: `friends.#(first!%"*e")#.first`, expected: `["Roger"]`},
+		{msg: "Query item itself", path: `children.#(=="Jack")`, expected: `"Jack"`},
+		{msg: "Query nested condition", path: `friends.#(nets.#(=="fb"))#.first`, expected: `["Dale","Roger"]`},
+		{msg: "Query existence", path: `friends.#(nets.2)#.first`, expected: `["Dale"]`},
+		{msg: "Query pipe after all matches", path: `friends.#.first|1`, expected: `"Roger"`},
+		{msg: "Query dot after all matches", path: `friends.#.nets.0`, expected: `["ig","fb","ig"]`},
+		{msg: "Query reverse", path: `children|@reverse`, expected: `["Jack","Alex","Sara"]`},
+		{msg: "Query reverse then item", path: `children.@reverse.0`, expected: `"Jack"`},
+		{msg: "Query reverse object", path: `name.@reverse`, expected: `{"last":"Anderson","first":"Tom"}`},
+		{msg: "Query keys", path: `name.@keys`, expected: `["first","last"]`},
+		{msg: "Query values", path: `name.@values`, expected: `["Tom","Anderson"]`},
+		{msg: "Query this", path: `@this.age`, expected: `37`},
+		{msg: "Query modifier on every item", path: `friends.#.nets.@reverse.0`, expected: `["tw","tw","tw"]`},
+		{msg: "Query missing member", path: `name.middle`, expectedErr: ErrKeyNotFound},
+		{msg: "Query no match", path: `friends.#(age>100).first`, expectedErr: ErrKeyNotFound},
+		{msg: "Query no matches", path: `friends.#(age>100)#.first`, expected: `[]`},
+		{msg: "Query index out of range", path: `children.3`, expectedErr: ErrIndexOutOfRange},
+		{msg: "Query key in array", path: `children.first`, expectedErr: ErrExpectedIndex},
+		{msg: "Query beyond scalar", path: `age.value`, expectedErr: ErrQueryExceedsDepth},
+		{msg: "Query count of object", path: `name.#`, expectedErr: ErrInvalidJSONType},
+		{msg: "Empty path", path: ``, expectedErr: ErrInvalidDotPath},
+		{msg: "Empty key", path: `name..first`, expectedErr: ErrInvalidDotPath},
+		{msg: "Trailing dot", path: `name.`, expectedErr: ErrInvalidDotPath},
+		{msg: "Unclosed query", path: `friends.#(last=="Murphy"`, expectedErr: ErrInvalidDotPath},
+		{msg: "Invalid condition value", path: `friends.#(last==Murphy)`, expectedErr: ErrInvalidDotPath},
+		{msg: "Like with number", path: `friends.#(age%4)`, expectedErr: ErrInvalidDotPath},
+		{msg: "Unknown modifier", path: `children.@sort`, expectedErr: ErrInvalidDotPath},
+		{msg: "Text after query", path: `friends.#(age>1)x`, expectedErr: ErrInvalidDotPath},
+	}
+
+	parser := NewParser([]byte(dotPathInput), nil)
+	node, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			got, err := QueryDotPath(node, test.path)
+
+			if !errors.Is(err, test.expectedErr) {
+				t.Fatalf("got error %v, expected %v", err, test.expectedErr)
+			}
+
+			if test.expectedErr != nil {
+				return
+			}
+
+			data, err := Encode(got, nil)
+			if err != nil {
+				t.Fatalf("unexpected encode error: %v", err)
+			}
+
+			if string(data) != test.expected {
+				t.Errorf("got %s, expected %s", data, test.expected)
+			}
+		})
+	}
+}
+
+func TestMatchWildcard(t *testing.T) {
+	var tests = []struct {
+		msg      string
+		pattern  string
+		input    string
+		expected bool
+	}{
+		{msg: "Match literal", pattern: "abc", input: "abc", expected: true},
+		{msg: "Match literal mismatch", pattern: "abc", input: "abd", expected: false},
+		{msg: "Match star", pattern: "a*c", input: "abbbc", expected: true},
+		{msg: "Match empty star", pattern: "a*", input: "a", expected: true},
+		{msg: "Match consecutive stars", pattern: "**b**", input: "abc", expected: true},
+		{msg: "Match question mark", pattern: "a?c", input: "a\u00e9c", expected: true},
+		{msg: "Match question mark needs a character", pattern: "ab?", input: "ab", expected: false},
+		{msg: "Match escaped star", pattern: `a\*`, input: "a*", expected: true},
+		{msg: "Match escaped star literally", pattern: `a\*`, input: "ab", expected: false},
+		{msg: "Match trailing input", pattern: "a", input: "ab", expected: false},
+		{msg: "Match star backtracking", pattern: "*ab*c", input: "aabxabc", expected: true},
+		{msg: "Match star after escape", pattern: `*\*`, input: "a*b*", expected: true},
+		{msg: "Match trailing backslash", pattern: `a\`, input: `a\`, expected: true},
+		{msg: "Match trailing stars", pattern: "a**", input: "a", expected: true},
+		{msg: "Match empty input", pattern: "?", input: "", expected: false},
+		{msg: "Match many stars on long input", pattern: "*a*a*a*a*a*a*a*a*a*a*b", input: strings.Repeat("a", 10000), expected: false},
+		{msg: "Match many stars on long matching input", pattern: "*a*a*a*a*a*a*a*a*a*a*b", input: strings.Repeat("a", 10000) + "b", expected: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			if got := matchWildcard(test.pattern, test.input); got != test.expected {
+				t.Errorf("got %v, expected %v", got, test.expected)
+			}
+		})
+	}
+}
+
+func TestDotPathReuse(t *testing.T) {
+	dp, err := CompileDotPath(`items.#(price<10)#.name`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := dp.String(); got != `items.#(price<10)#.name` {
+		t.Errorf("got %s, expected the source path", got)
+	}
+
+	for input, expected := range map[string]string{
+		`{"items": [{"name": "a", "price": 5}, {"name": "b", "price": 15}]}`: `["a"]`,
+		`{"items": [{"name": "c", "price": 1}, {"price": 2}]}`:               `["c"]`,
+	} {
+		parser := NewParser([]byte(input), nil)
+		node, err := parser.Parse()
+		if err != nil {
+			t.Fatalf("unexpected parse error: %v", err)
+		}
+
+		got, err := dp.Query(node)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if data, _ := Encode(got, nil); string(data) != expected {
+			t.Errorf("got %s in %s, expected %s", data, input, expected)
+		}
+	}
+}
@@ -0,0 +1,395 @@
+package jsonvx
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrUnsupportedValue is returned when a node cannot be represented in the configured output,
+// such as NaN or Infinity in strict JSON.
+var ErrUnsupportedValue = errors.New("value cannot be represented in JSON output")
+
+// EncoderConfig defines how the Encoder writes JSON nodes.
+//
+// By default, the output is compact and follows the strict [ECMA-404] specification,
+// whatever relaxed syntax the nodes were parsed from. The Allow fields mirror those of
+// ParserConfig and let the Encoder emit the matching relaxed syntax, such as [JSON5].
+//
+// [ECMA-404]: https://datatracker.ietf.org/doc/html/rfc7159
+// [JSON5]: https://json5.org/
+type EncoderConfig struct {
+	Indent     string // Indent is the string used for each level of indentation, an empty Indent produces compact output.
+	EscapeHTML bool   // EscapeHTML escapes <, > and & in strings so the output can be safely embedded in HTML.
+
+	AllowHexNumbers       bool // AllowHexNumbers keeps hexadecimal literals (e.g., 0xFF) as written instead of converting them to base 10.
+	AllowPointEdgeNumbers bool // AllowPointEdgeNumbers keeps numbers like `.5` or `5.` as written instead of completing them.
+
+	AllowInfinity    bool // AllowInfinity writes `Infinity` and `-Infinity` instead of failing with ErrUnsupportedValue.
+	AllowNaN         bool // AllowNaN writes `NaN` instead of failing with ErrUnsupportedValue.
+	AllowLeadingPlus bool // AllowLeadingPlus keeps a leading '+' in numbers (e.g., `+42`) as written.
+
+	AllowUnquoted     bool // AllowUnquoted writes object keys that are valid identifiers without quotes (e.g., `{foo: "bar"}`).
+	AllowSingleQuotes bool // AllowSingleQuotes writes strings enclosed in single quotes (' ') instead of double quotes.
+
+	AllowTrailingCommaArray  bool // AllowTrailingCommaArray writes a trailing comma after the last array item of indented output.
+	AllowTrailingCommaObject bool // AllowTrailingCommaObject writes a trailing comma after the last object property of indented output.
+}
+
+// NewEncoderConfig creates a new EncoderConfig instance, optionally applying one or more configuration options.
+// Options are applied in the order provided.
+func NewEncoderConfig(opts ...func(*EncoderConfig)) *EncoderConfig {
+	cfg := &EncoderConfig{}
+
+	for _, o := range opts {
+		o(cfg)
+	}
+
+	return cfg
+}
+
+// WithIndent is the functional option setter for the Indent string.
+func WithIndent(indent string) func(*EncoderConfig) {
+	return func(c *EncoderConfig) {
+		c.Indent = indent
+	}
+}
+
+// WithEscapeHTML is the functional option setter for the EscapeHTML flag.
+func WithEscapeHTML(escape bool) func(*EncoderConfig) {
+	return func(c *EncoderConfig) {
+		c.EscapeHTML = escape
+	}
+}
+
+// WithDialect is the functional option setter that enables every relaxed feature of the output
+// also enabled in the given ParserConfig, so the output is accepted by a parser using it.
+func WithDialect(dialect *ParserConfig) func(*EncoderConfig) {
+	return func(c *EncoderConfig) {
+		c.AllowHexNumbers = dialect.AllowHexNumbers
+		c.AllowPointEdgeNumbers = dialect.AllowPointEdgeNumbers
+		c.AllowInfinity = dialect.AllowInfinity
+		c.AllowNaN = dialect.AllowNaN
+		c.AllowLeadingPlus = dialect.AllowLeadingPlus
+		c.AllowUnquoted = dialect.AllowUnquoted
+		c.AllowSingleQuotes = dialect.AllowSingleQuotes
+		c.AllowTrailingCommaArray = dialect.AllowTrailingCommaArray
+		c.AllowTrailingCommaObject = dialect.AllowTrailingCommaObject
+	}
+}
+
+// JSON5EncoderConfig returns an EncoderConfig with all features enabled for JSON5 output.
+func JSON5EncoderConfig() *EncoderConfig {
+	return NewEncoderConfig(WithDialect(JSON5Config()))
+}
+
+// Encoder writes JSON nodes to an output stream.
+type Encoder struct {
+	w      io.Writer
+	config *EncoderConfig
+}
+
+// NewEncoder creates a new Encoder that writes to w using the given configuration.
+// A nil configuration produces compact, strict JSON.
+func NewEncoder(w io.Writer, cfg *EncoderConfig) *Encoder {
+	if cfg == nil {
+		cfg = NewEncoderConfig()
+	}
+
+	return &Encoder{w: w, config: cfg}
+}
+
+// Encode writes the serialized node to the underlying writer.
+// Nothing is written if the node cannot be represented, in which case an error wrapping
+// ErrUnsupportedValue or ErrInvalidJSONType is returned.
+func (e *Encoder) Encode(node JSON) error {
+	state := encodeState{config: e.config}
+
+	if err := state.encode(node, 0); err != nil {
+		return err
+	}
+
+	_, err := e.w.Write(state.buf.Bytes())
+	return err
+}
+
+// Encode returns the serialized node using the given configuration.
+// A nil configuration produces compact, strict JSON.
+func Encode(node JSON, cfg *EncoderConfig) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := NewEncoder(&buf, cfg).Encode(node); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// encodeState holds the output of a single Encode call.
+type encodeState struct {
+	buf    bytes.Buffer
+	config *EncoderConfig
+}
+
+func (s *encodeState) encode(node JSON, depth int) error {
+	switch val := node.(type) {
+	case *Null:
+		if val == nil {
+			break
+		}
+		s.buf.WriteString("null")
+		return nil
+	case *Boolean:
+		if val == nil {
+			break
+		}
+		boolVal, err := val.Value()
+		if err != nil {
+			return err
+		}
+		if boolVal {
+			s.buf.WriteString("true")
+		} else {
+			s.buf.WriteString("false")
+		}
+		return nil
+	case *String:
+		if val == nil {
+			break
+		}
+		strVal, err := val.Value()
+		if err != nil {
+			return err
+		}
+		s.writeString(strVal)
+		return nil
+	case *Number:
+		if val == nil {
+			break
+		}
+		return s.writeNumber(val)
+	case *Array:
+		if val == nil {
+			break
+		}
+		return s.writeArray(val, depth)
+	case *Object:
+		if val == nil {
+			break
+		}
+		return s.writeObject(val, depth)
+	}
+
+	return fmt.Errorf("%w: %T", ErrInvalidJSONType, node)
+}
+
+func (s *encodeState) writeNumber(num *Number) error {
+	if num.Token == nil {
+		return ErrNotNumber
+	}
+
+	literal := string(num.Token.Literal)
+	text := literal
+
+	switch num.Token.SubKind {
+	case INTEGER:
+	case FLOAT, SCI_NOT:
+		if !s.config.AllowPointEdgeNumbers {
+			text = canonicalDecimal(literal)
+		}
+	case HEX:
+		if !s.config.AllowHexNumbers {
+			decimal, err := num.Decimal()
+			if err != nil {
+				return err
+			}
+			text = decimal
+		}
+	case INF:
+		if !s.config.AllowInfinity {
+			return fmt.Errorf("%w: %s", ErrUnsupportedValue, literal)
+		}
+	case NaN:
+		if !s.config.AllowNaN {
+			return fmt.Errorf("%w: %s", ErrUnsupportedValue, literal)
+		}
+	default:
+		return ErrNotNumber
+	}
+
+	text = strings.TrimPrefix(text, "+")
+	if s.config.AllowLeadingPlus && strings.HasPrefix(literal, "+") {
+		text = "+" + text
+	}
+
+	s.buf.WriteString(text)
+	return nil
+}
+
+func (s *encodeState) writeArray(arr *Array, depth int) error {
+	if arr.Len() == 0 {
+		s.buf.WriteString("[]")
+		return nil
+	}
+
+	s.buf.WriteByte('[')
+
+	for i, item := range arr.Items {
+		if i > 0 {
+			s.buf.WriteByte(',')
+		}
+
+		s.writeNewline(depth + 1)
+
+		if err := s.encode(item, depth+1); err != nil {
+			return err
+		}
+	}
+
+	if s.config.AllowTrailingCommaArray && s.config.Indent != "" {
+		s.buf.WriteByte(',')
+	}
+
+	s.writeNewline(depth)
+	s.buf.WriteByte(']')
+
+	return nil
+}
+
+func (s *encodeState) writeObject(obj *Object, depth int) error {
+	if obj.Len() == 0 {
+		s.buf.WriteString("{}")
+		return nil
+	}
+
+	s.buf.WriteByte('{')
+
+	for i, prop := range obj.Properties {
+		if i > 0 {
+			s.buf.WriteByte(',')
+		}
+
+		s.writeNewline(depth + 1)
+
+		if s.config.AllowUnquoted && isUnquotableKey(prop.key) {
+			s.buf.Write(prop.key)
+		} else {
+			s.writeString(string(prop.key))
+		}
+
+		s.buf.WriteByte(':')
+
+		if s.config.Indent != "" {
+			s.buf.WriteByte(' ')
+		}
+
+		if err := s.encode(prop.value, depth+1); err != nil {
+			return err
+		}
+	}
+
+	if s.config.AllowTrailingCommaObject && s.config.Indent != "" {
+		s.buf.WriteByte(',')
+	}
+
+	s.writeNewline(depth)
+	s.buf.WriteByte('}')
+
+	return nil
+}
+
+// writeNewline starts a new indented line, it does nothing in compact output.
+func (s *encodeState) writeNewline(depth int) {
+	if s.config.Indent == "" {
+		return
+	}
+
+	s.buf.WriteByte('\n')
+	s.buf.WriteString(strings.Repeat(s.config.Indent, depth))
+}
+
+// writeString writes str as a quoted JSON string, escaping characters as required.
+// Strings are double-quoted unless AllowSingleQuotes is enabled. Invalid UTF-8 is replaced
+// by U+FFFD, and U+2028 and U+2029 are always escaped so the output is also valid JavaScript.
+func (s *encodeState) writeString(str string) {
+	const hex = "0123456789abcdef"
+
+	quote := byte('"')
+	if s.config.AllowSingleQuotes {
+		quote = '\''
+	}
+
+	s.buf.WriteByte(quote)
+
+	for i := 0; i < len(str); {
+		char := str[i]
+
+		if char < utf8.RuneSelf {
+			switch {
+			case char == quote || char == '\\':
+				s.buf.WriteByte('\\')
+				s.buf.WriteByte(char)
+			case char == '\b':
+				s.buf.WriteString(`\b`)
+			case char == '\f':
+				s.buf.WriteString(`\f`)
+			case char == '\n':
+				s.buf.WriteString(`\n`)
+			case char == '\r':
+				s.buf.WriteString(`\r`)
+			case char == '\t':
+				s.buf.WriteString(`\t`)
+			case char < 0x20 || (s.config.EscapeHTML && (char == '<' || char == '>' || char == '&')):
+				s.buf.WriteString(`\u00`)
+				s.buf.WriteByte(hex[char>>4])
+				s.buf.WriteByte(hex[char&0xF])
+			default:
+				s.buf.WriteByte(char)
+			}
+			i++
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(str[i:])
+
+		switch {
+		case r == utf8.RuneError && size == 1:
+			s.buf.WriteString(`\ufffd`)
+		case r == '\u2028' || r == '\u2029':
+			s.buf.WriteString(`\u202`)
+			s.buf.WriteByte(hex[r&0xF])
+		default:
+			s.buf.WriteString(str[i : i+size])
+		}
+
+		i += size
+	}
+
+	s.buf.WriteByte(quote)
+}
+
+// isUnquotableKey reports whether key can be written without quotes and still be read back
+// as the same key, i.e. it is an ASCII identifier that the lexer does not treat as a keyword.
+func isUnquotableKey(key []byte) bool {
+	if len(key) == 0 || isDigit(key[0]) {
+		return false
+	}
+
+	for _, b := range key {
+		if b >= utf8.RuneSelf || !isPossibleJSIdentifier(b) {
+			return false
+		}
+	}
+
+	// the lexer reads these literals as soon as the key starts with them
+	for _, keyword := range []string{"null", "true", "false", "NaN", "Infinity"} {
+		if bytes.HasPrefix(key, []byte(keyword)) {
+			return false
+		}
+	}
+
+	return true
+}
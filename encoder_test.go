@@ -0,0 +1,148 @@
+package jsonvx
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+type EncoderTest struct {
+	msg         string
+	input       []byte
+	cfg         *EncoderConfig
+	expected    string
+	expectedErr error
+}
+
+func runEncoderTests(t *testing.T, tests []EncoderTest) {
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			parser := NewParser(test.input, JSON5Config())
+			node, err := parser.Parse()
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+
+			got, err := Encode(node, test.cfg)
+
+			if string(got) != test.expected || !errors.Is(err, test.expectedErr) {
+				t.Errorf("got (%q, %v), expected (%q, %v)", got, err, test.expected, test.expectedErr)
+			}
+		})
+	}
+}
+
+func TestEncodeCompact(t *testing.T) {
+	var tests = []EncoderTest{
+		{msg: "Encode null", input: []byte(`null`), expected: `null`},
+		{msg: "Encode booleans", input: []byte(`[true, false]`), expected: `[true,false]`},
+		{msg: "Encode empty containers", input: []byte(`[[], {}]`), expected: `[[],{}]`},
+		{msg: "Encode object in source order", input: []byte(`{"b": 1, "a": [1, 2]}`), expected: `{"b":1,"a":[1,2]}`},
+		{msg: "Encode JSON5 as strict JSON", input: []byte(`{unquoted: 'single', hex: 0xFF, lead: .5, trail: 5., plus: +1,}`), expected: `{"unquoted":"single","hex":255,"lead":0.5,"trail":5,"plus":1}`},
+		{msg: "Encode escapes", input: []byte(`"quote \" backslash \\ tab \t newline \n \u0001"`), expected: `"quote \" backslash \\ tab \t newline \n \u0001"`},
+		{msg: "Encode decoded escapes", input: []byte(`'\x41 \u00e9 \uD83D\uDE00 \''`), expected: "\"A \u00e9 \U0001F600 '\""},
+		{msg: "Encode line separators", input: []byte("\"\u2028\u2029\""), expected: `"\u2028\u2029"`},
+		{msg: "Encode HTML characters unescaped", input: []byte(`"<a&b>"`), expected: `"<a&b>"`},
+		{msg: "Encode NaN", input: []byte(`[NaN]`), expectedErr: ErrUnsupportedValue},
+		{msg: "Encode Infinity", input: []byte(`{"a": -Infinity}`), expectedErr: ErrUnsupportedValue},
+	}
+
+	runEncoderTests(t, tests)
+}
+
+func TestEncodeOptions(t *testing.T) {
+	var tests = []EncoderTest{
+		{msg: "Encode HTML escaped", input: []byte(`"<a&b>"`), cfg: NewEncoderConfig(WithEscapeHTML(true)), expected: `"\u003ca\u0026b\u003e"`},
+		{msg: "Encode indented scalar", input: []byte(`1`), cfg: NewEncoderConfig(WithIndent("  ")), expected: `1`},
+		{msg: "Encode indented empty containers", input: []byte(`{"a": [], "b": {}}`), cfg: NewEncoderConfig(WithIndent("  ")), expected: "{\n  \"a\": [],\n  \"b\": {}\n}"},
+		{msg: "Encode indented nested", input: []byte(`{"a": [1, {"b": null}]}`), cfg: NewEncoderConfig(WithIndent("\t")), expected: "{\n\t\"a\": [\n\t\t1,\n\t\t{\n\t\t\t\"b\": null\n\t\t}\n\t]\n}"},
+	}
+
+	runEncoderTests(t, tests)
+}
+
+func TestEncoderWriter(t *testing.T) {
+	parser := NewParser([]byte(`[1, NaN]`), JSON5Config())
+	node, _ := parser.Parse()
+
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf, nil).Encode(node); !errors.Is(err, ErrUnsupportedValue) {
+		t.Errorf("got %v, expected %v", err, ErrUnsupportedValue)
+	}
+
+	if buf.Len() != 0 {
+		t.Errorf("expected nothing to be written, got %q", buf.String())
+	}
+
+	if err := NewEncoder(&buf, nil).Encode(nil); !errors.Is(err, ErrInvalidJSONType) {
+		t.Errorf("got %v, expected %v", err, ErrInvalidJSONType)
+	}
+}
+
+func TestEncodeInvalidUTF8(t *testing.T) {
+	node := &String{Token: newTokenPtr(STRING, DOUBLE_QUOTED, []byte("\"a\xffb\""), 1, 1, nil)}
+
+	got, err := Encode(node, nil)
+	if err != nil || string(got) != `"a\ufffdb"` {
+		t.Errorf("got (%q, %v), expected %q", got, err, `"a\ufffdb"`)
+	}
+}
+
+func TestEncodeJSON5(t *testing.T) {
+	json5 := JSON5EncoderConfig()
+	indented := JSON5EncoderConfig()
+	indented.Indent = "  "
+
+	var tests = []EncoderTest{
+		{msg: "Encode unquoted keys", input: []byte(`{"a": 1, "$b_2": 2, "c-d": 3, "1e": 4, "": 5}`), cfg: json5, expected: `{a:1,$b_2:2,'c-d':3,'1e':4,'':5}`},
+		{msg: "Encode keyword keys quoted", input: []byte(`{"null": 1, "trueish": 2, "NaN": 3, "Infinity": 4, "nope": 5}`), cfg: json5, expected: `{'null':1,'trueish':2,'NaN':3,'Infinity':4,nope:5}`},
+		{msg: "Encode single quoted strings", input: []byte(`["it's", "say \"hi\""]`), cfg: json5, expected: `['it\'s','say "hi"']`},
+		{msg: "Encode numbers as written", input: []byte(`[0xFF, -0x1a, +1, .5, 5., +.5e3, Infinity, -Infinity, NaN]`), cfg: json5, expected: `[0xFF,-0x1a,+1,.5,5.,+.5e3,Infinity,-Infinity,NaN]`},
+		{msg: "Encode trailing commas", input: []byte(`{"a": [1, 2], "b": []}`), cfg: indented, expected: "{\n  a: [\n    1,\n    2,\n  ],\n  b: [],\n}"},
+		{msg: "Encode compact without trailing commas", input: []byte(`{"a": [1, 2]}`), cfg: json5, expected: `{a:[1,2]}`},
+	}
+
+	runEncoderTests(t, tests)
+}
+
+func TestEncodeDialect(t *testing.T) {
+	var tests = []EncoderTest{
+		{msg: "Encode hex only", input: []byte(`[0xFF, +1, .5]`), cfg: NewEncoderConfig(WithDialect(NewParserConfig(WithAllowHexNumbers(true)))), expected: `[0xFF,1,0.5]`},
+		{msg: "Encode hex with leading plus", input: []byte(`[+0xFF, +1]`), cfg: NewEncoderConfig(WithDialect(NewParserConfig(WithAllowHexNumbers(true), WithAllowLeadingPlus(true)))), expected: `[+0xFF,+1]`},
+		{msg: "Encode leading plus without hex", input: []byte(`[+0xFF, +Infinity]`), cfg: NewEncoderConfig(WithDialect(NewParserConfig(WithAllowInfinity(true), WithAllowLeadingPlus(true)))), expected: `[+255,+Infinity]`},
+		{msg: "Encode point edge only", input: []byte(`[.5, 5., +5.]`), cfg: NewEncoderConfig(WithDialect(NewParserConfig(WithAllowPointEdgeNumbers(true)))), expected: `[.5,5.,5.]`},
+		{msg: "Encode Infinity without NaN", input: []byte(`[Infinity, NaN]`), cfg: NewEncoderConfig(WithDialect(NewParserConfig(WithAllowInfinity(true)))), expectedErr: ErrUnsupportedValue},
+		{msg: "Encode NaN without Infinity", input: []byte(`[NaN, -Infinity]`), cfg: NewEncoderConfig(WithDialect(NewParserConfig(WithAllowNaN(true)))), expectedErr: ErrUnsupportedValue},
+	}
+
+	runEncoderTests(t, tests)
+}
+
+func TestEncodeJSON5RoundTrip(t *testing.T) {
+	input := []byte(`{unquoted: 'and you can quote me on that', hexadecimal: 0xdecaf, leadingDecimalPoint: .8675309, andTrailing: 8675309., positiveSign: +1, trailingComma: 'in objects', andIn: ['arrays',], "backwardsCompatible": "with JSON", notANumber: NaN, inf: -Infinity}`)
+
+	parser := NewParser(input, JSON5Config())
+	node, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	cfg := JSON5EncoderConfig()
+	cfg.Indent = "\t"
+
+	output, err := Encode(node, cfg)
+	if err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+
+	reparser := NewParser(output, JSON5Config())
+	reparsed, err := reparser.Parse()
+	if err != nil {
+		t.Fatalf("unexpected parse error of %s: %v", output, err)
+	}
+
+	again, err := Encode(reparsed, cfg)
+	if err != nil || string(again) != string(output) {
+		t.Errorf("got (%s, %v), expected %s", again, err, output)
+	}
+}
@@ -0,0 +1,105 @@
+package jsonvx
+
+import (
+	"fmt"
+	"strings"
+)
+
+// valueKinds holds the token kinds a JSON value can start with.
+var valueKinds = []TokenKind{NULL, BOOLEAN, STRING, NUMBER, LEFT_SQUARE_BRACE, LEFT_CURLY_BRACE}
+
+// SyntaxError describes why and where the input could not be parsed.
+//
+// It unwraps to one of the parser sentinel errors (e.g., ErrJSONSyntax or ErrJSONUnexpectedChar),
+// so errors.Is keeps working, while errors.As gives access to the position of the failure.
+type SyntaxError struct {
+	Err      error        // Err is the sentinel error the failure falls under.
+	Code     TokenSubKind // Code is the sub kind of the offending token when it is ILLEGAL (e.g., INVALID_LEADING_ZERO), NONE otherwise.
+	Token    Token        // Token is the offending token.
+	Expected []TokenKind  // Expected holds the token kinds that would have been accepted instead of Token, if any.
+	Message  string       // Message is a human-readable description of the failure, if any (e.g., "missing digits after exponent sign").
+	Hint     string       // Hint names the ParserConfig flag that would accept the input, if any.
+
+	Line   int // Line is the line number where the offending token starts (1-based index).
+	Column int // Column is the byte column where the offending token starts (1-based index).
+	Offset int // Offset is the byte offset where the offending token starts (0-based index).
+
+	detail string // detail describes the failure, after the sentinel error message.
+	length int    // length is the number of bytes of the offending text, from the start of Token.
+}
+
+// newSyntaxError creates a SyntaxError for the offending token.
+// An ILLEGAL token is diagnosed against cfg, see SyntaxError.diagnose.
+func newSyntaxError(err error, token Token, cfg *ParserConfig, expected ...TokenKind) *SyntaxError {
+	code := NONE
+	if token.Kind == ILLEGAL {
+		code = token.SubKind
+	}
+
+	syntaxErr := &SyntaxError{
+		Err:      err,
+		Code:     code,
+		Token:    token,
+		Expected: expected,
+		Line:     token.Line,
+		Column:   token.Column,
+		Offset:   token.Offset,
+		detail:   fmt.Sprintf("%q at line %d, column %d", token.Literal, token.Line, token.Column),
+		length:   len(token.Literal),
+	}
+
+	syntaxErr.diagnose(cfg)
+
+	return syntaxErr
+}
+
+// Error returns the sentinel error message followed by the description of the failure,
+// the offending token and its position, and the hint.
+func (e *SyntaxError) Error() string {
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, "%v: ", e.Err)
+
+	if e.Message != "" {
+		fmt.Fprintf(&builder, "%s: ", e.Message)
+	}
+
+	builder.WriteString(e.detail)
+
+	if e.Hint != "" {
+		fmt.Fprintf(&builder, " (%s)", e.Hint)
+	}
+
+	return builder.String()
+}
+
+// Unwrap returns the sentinel error the failure falls under.
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// Span returns the range of the input covered by the offending text. It is usually the whole Token,
+// but only the malformed part of an ILLEGAL token, whose literal runs up to the end of the input.
+func (e *SyntaxError) Span() Span {
+	length := min(e.length, len(e.Token.Literal))
+	text := Token{Literal: e.Token.Literal[:length], Line: e.Line, Column: e.Column, Offset: e.Offset}
+
+	return Span{Start: text.Start(), End: text.End()}
+}
+
+// ExpectedString returns the expected token kinds as a readable list (e.g., "COMMA or RIGHT_SQUARE_BRACE").
+func (e *SyntaxError) ExpectedString() string {
+	kinds := make([]string, len(e.Expected))
+	for i, kind := range e.Expected {
+		kinds[i] = kind.String()
+	}
+
+	switch len(kinds) {
+	case 0:
+		return ""
+	case 1:
+		return kinds[0]
+	default:
+		return strings.Join(kinds[:len(kinds)-1], ", ") + " or " + kinds[len(kinds)-1]
+	}
+}
@@ -0,0 +1,86 @@
+package jsonvx
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestSyntaxError(t *testing.T) {
+	var tests = []struct {
+		msg              string
+		input            []byte
+		cfg              *ParserConfig
+		expectedErr      error
+		expectedCode     TokenSubKind
+		expectedLine     int
+		expectedColumn   int
+		expectedOffset   int
+		expectedExpected []TokenKind
+	}{
+		{msg: "Invalid leading zero", input: []byte(`01`), expectedErr: ErrJSONUnexpectedChar, expectedCode: INVALID_LEADING_ZERO, expectedLine: 1, expectedColumn: 1, expectedOffset: 0, expectedExpected: valueKinds},
+		{msg: "Invalid hex number in array", input: []byte("[1,\n 0x1]"), expectedErr: ErrJSONUnexpectedChar, expectedCode: INVALID_HEX_NUMBER, expectedLine: 2, expectedColumn: 2, expectedOffset: 5, expectedExpected: valueKinds},
+		{msg: "Missing colon", input: []byte(`{"a" 1}`), expectedErr: ErrJSONSyntax, expectedCode: NONE, expectedLine: 1, expectedColumn: 6, expectedOffset: 5, expectedExpected: []TokenKind{COLON}},
+		{msg: "Missing comma in array", input: []byte(`[1 2]`), expectedErr: ErrJSONSyntax, expectedCode: NONE, expectedLine: 1, expectedColumn: 4, expectedOffset: 3, expectedExpected: []TokenKind{COMMA, RIGHT_SQUARE_BRACE}},
+		{msg: "Missing comma in object", input: []byte("{\"a\": 1\n\"b\": 2}"), expectedErr: ErrJSONSyntax, expectedCode: NONE, expectedLine: 2, expectedColumn: 1, expectedOffset: 8, expectedExpected: []TokenKind{COMMA, RIGHT_CURLY_BRACE}},
+		{msg: "Invalid key", input: []byte(`{1: 2}`), expectedErr: ErrJSONSyntax, expectedCode: NONE, expectedLine: 1, expectedColumn: 2, expectedOffset: 1, expectedExpected: []TokenKind{STRING}},
+		{msg: "Invalid character after value", input: []byte(`null x`), expectedErr: ErrJSONUnexpectedChar, expectedCode: INVALID_CHARACTER, expectedLine: 1, expectedColumn: 6, expectedOffset: 5, expectedExpected: []TokenKind{EOF}},
+		{msg: "Multiple values", input: []byte("1\n2"), expectedErr: ErrJSONMultipleContent, expectedCode: NONE, expectedLine: 2, expectedColumn: 1, expectedOffset: 2, expectedExpected: []TokenKind{EOF}},
+		{msg: "Duplicate key", input: []byte(`{"a": 1, "a": 2}`), cfg: NewParserConfig(WithDuplicateKeys(DuplicateKeysError)), expectedErr: ErrJSONDuplicateKey, expectedCode: NONE, expectedLine: 1, expectedColumn: 10, expectedOffset: 9},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			parser := NewParser(test.input, test.cfg)
+			_, err := parser.Parse()
+
+			if !errors.Is(err, test.expectedErr) {
+				t.Fatalf("got %v, expected %v", err, test.expectedErr)
+			}
+
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("got %T, expected *SyntaxError", err)
+			}
+
+			if syntaxErr.Code != test.expectedCode {
+				t.Errorf("got code %v, expected %v", syntaxErr.Code, test.expectedCode)
+			}
+
+			if syntaxErr.Line != test.expectedLine || syntaxErr.Column != test.expectedColumn || syntaxErr.Offset != test.expectedOffset {
+				t.Errorf("got position %d:%d (%d), expected %d:%d (%d)", syntaxErr.Line, syntaxErr.Column, syntaxErr.Offset, test.expectedLine, test.expectedColumn, test.expectedOffset)
+			}
+
+			if !slices.Equal(syntaxErr.Expected, test.expectedExpected) {
+				t.Errorf("got expected kinds %v, expected %v", syntaxErr.Expected, test.expectedExpected)
+			}
+
+			if syntaxErr.Token.Offset != syntaxErr.Offset {
+				t.Errorf("got token offset %d, expected %d", syntaxErr.Token.Offset, syntaxErr.Offset)
+			}
+		})
+	}
+}
+
+func TestSyntaxErrorExpectedString(t *testing.T) {
+	var tests = []struct {
+		msg      string
+		expected []TokenKind
+		output   string
+	}{
+		{msg: "No expected kinds", expected: nil, output: ""},
+		{msg: "One expected kind", expected: []TokenKind{COLON}, output: "COLON"},
+		{msg: "Two expected kinds", expected: []TokenKind{COMMA, RIGHT_SQUARE_BRACE}, output: "COMMA or RIGHT_SQUARE_BRACE"},
+		{msg: "Three expected kinds", expected: []TokenKind{NULL, BOOLEAN, STRING}, output: "NULL, BOOLEAN or STRING"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			err := &SyntaxError{Expected: test.expected}
+
+			if got := err.ExpectedString(); got != test.output {
+				t.Errorf("got %q, expected %q", got, test.output)
+			}
+		})
+	}
+}
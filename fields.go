@@ -0,0 +1,222 @@
+package jsonvx
+
+import (
+	"cmp"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// field describes a struct field as seen by Unmarshal and MarshalNode, following the rules of encoding/json:
+// the name comes from the `json` tag, or from the field name, and the fields of embedded
+// structs are promoted unless a shallower or tagged field has the same name.
+type field struct {
+	name      string       // name is the key of the field in JSON objects.
+	index     []int        // index is the index sequence of the field, for reflect.Value.FieldByIndex.
+	typ       reflect.Type // typ is the type of the field.
+	tagged    bool         // tagged reports whether the name comes from the `json` tag.
+	omitEmpty bool         // omitEmpty reports whether the `omitempty` option is set.
+	quoted    bool         // quoted reports whether the `string` option is set on a boolean, number or string field.
+}
+
+// fieldCache holds the fields of the struct types seen so far, by type.
+var fieldCache sync.Map
+
+// cachedFields returns the fields of the struct type t.
+func cachedFields(t reflect.Type) []field {
+	if fields, ok := fieldCache.Load(t); ok {
+		return fields.([]field)
+	}
+
+	fields, _ := fieldCache.LoadOrStore(t, typeFields(t))
+	return fields.([]field)
+}
+
+// lookupField returns the field named key, falling back to a case-insensitive match like encoding/json.
+func lookupField(fields []field, key string) (*field, bool) {
+	for i := range fields {
+		if fields[i].name == key {
+			return &fields[i], true
+		}
+	}
+
+	for i := range fields {
+		if strings.EqualFold(fields[i].name, key) {
+			return &fields[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// typeFields returns the fields of the struct type t, embedded structs being walked breadth first.
+func typeFields(t reflect.Type) []field {
+	var (
+		current []field
+		next    = []field{{typ: t}}
+
+		count     map[reflect.Type]int
+		nextCount = map[reflect.Type]int{}
+
+		visited = map[reflect.Type]bool{}
+		fields  []field
+	)
+
+	for len(next) > 0 {
+		current, next = next, current[:0]
+		count, nextCount = nextCount, map[reflect.Type]int{}
+
+		for _, f := range current {
+			if visited[f.typ] {
+				continue
+			}
+			visited[f.typ] = true
+
+			for i := 0; i < f.typ.NumField(); i++ {
+				sf := f.typ.Field(i)
+
+				if sf.Anonymous {
+					embedded := sf.Type
+					if embedded.Kind() == reflect.Pointer {
+						embedded = embedded.Elem()
+					}
+					// the exported fields of an unexported embedded struct are still promoted
+					if !sf.IsExported() && embedded.Kind() != reflect.Struct {
+						continue
+					}
+				} else if !sf.IsExported() {
+					continue
+				}
+
+				tag := sf.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+
+				name, opts, _ := strings.Cut(tag, ",")
+				if !isValidTagName(name) {
+					name = ""
+				}
+
+				index := append(slices.Clone(f.index), i)
+
+				typ := sf.Type
+				if typ.Name() == "" && typ.Kind() == reflect.Pointer {
+					typ = typ.Elem()
+				}
+
+				if name != "" || !sf.Anonymous || typ.Kind() != reflect.Struct {
+					newField := field{
+						name:      name,
+						index:     index,
+						typ:       sf.Type,
+						tagged:    name != "",
+						omitEmpty: hasTagOption(opts, "omitempty"),
+						quoted:    hasTagOption(opts, "string") && isQuotableKind(typ.Kind()),
+					}
+					if newField.name == "" {
+						newField.name = sf.Name
+					}
+
+					fields = append(fields, newField)
+
+					// the same struct embedded twice at one level cancels its fields out
+					if count[f.typ] > 1 {
+						fields = append(fields, fields[len(fields)-1])
+					}
+					continue
+				}
+
+				nextCount[typ]++
+				if nextCount[typ] == 1 {
+					next = append(next, field{name: typ.Name(), index: index, typ: typ})
+				}
+			}
+		}
+	}
+
+	// fields with the same name are sorted by depth, then tagged ones first
+	slices.SortFunc(fields, func(a, b field) int {
+		if c := strings.Compare(a.name, b.name); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(len(a.index), len(b.index)); c != 0 {
+			return c
+		}
+		if a.tagged != b.tagged {
+			if a.tagged {
+				return -1
+			}
+			return 1
+		}
+		return slices.Compare(a.index, b.index)
+	})
+
+	dominant := fields[:0]
+	for start := 0; start < len(fields); {
+		end := start + 1
+		for end < len(fields) && fields[end].name == fields[start].name {
+			end++
+		}
+
+		// the shallowest field wins, unless another one at the same depth is as much tagged
+		group := fields[start:end]
+		if len(group) == 1 || len(group[0].index) < len(group[1].index) || group[0].tagged != group[1].tagged {
+			dominant = append(dominant, group[0])
+		}
+
+		start = end
+	}
+
+	slices.SortFunc(dominant, func(a, b field) int {
+		return slices.Compare(a.index, b.index)
+	})
+
+	return dominant
+}
+
+// hasTagOption reports whether the comma-separated options of a `json` tag hold option.
+func hasTagOption(opts, option string) bool {
+	for opts != "" {
+		var name string
+		name, opts, _ = strings.Cut(opts, ",")
+		if name == option {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isValidTagName reports whether name can be used as the key of a field, as with encoding/json.
+func isValidTagName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for _, char := range name {
+		switch {
+		case strings.ContainsRune("!#$%&()*+-./:;<=>?@[]^_{|}~ ", char):
+		case !unicode.IsLetter(char) && !unicode.IsDigit(char):
+			return false
+		}
+	}
+
+	return true
+}
+
+// isQuotableKind reports whether the `string` tag option applies to values of the kind.
+func isQuotableKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.String:
+		return true
+	default:
+		return false
+	}
+}
@@ -1,6 +1,7 @@
 package jsonvx
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -262,3 +263,79 @@ func TestIsHex(t *testing.T) {
 		})
 	}
 }
+
+func TestUnquoteValue(t *testing.T) {
+	var tests = []struct {
+		msg         string
+		p1          []byte
+		expected    string
+		expectedErr error
+	}{
+		// Valid standard escapes
+		{msg: "Valid no escapes", p1: []byte(`"hello"`), expected: "hello"},
+		{msg: "Valid empty string", p1: []byte(`""`), expected: ""},
+		{msg: "Valid single quoted", p1: []byte(`'hello'`), expected: "hello"},
+		{msg: "Valid escaped newline", p1: []byte(`"a\nb"`), expected: "a\nb"},
+		{msg: "Valid escaped controls", p1: []byte(`"\b\f\r\t"`), expected: "\b\f\r\t"},
+		{msg: "Valid escaped quote, backslash and solidus", p1: []byte(`"\"\\\/"`), expected: `"\/`},
+		{msg: "Valid escaped single quote", p1: []byte(`'it\'s'`), expected: "it's"},
+		{msg: "Valid unicode escape", p1: []byte(`"\u00e9"`), expected: "\u00e9"},
+		{msg: "Valid raw multi-byte", p1: []byte("\"\u00e9\""), expected: "\u00e9"},
+		{msg: "Valid surrogate pair", p1: []byte(`"\uD83D\uDE00"`), expected: "\U0001F600"},
+		{msg: "Valid lone high surrogate", p1: []byte(`"\uD83Dx"`), expected: "\uFFFDx"},
+		{msg: "Valid lone low surrogate", p1: []byte(`"\uDE00"`), expected: "\uFFFD"},
+
+		// Valid relaxed escapes
+		{msg: "Valid line continuation", p1: []byte("\"a\\\nb\""), expected: "ab"},
+		{msg: "Valid CRLF line continuation", p1: []byte("\"a\\\r\nb\""), expected: "ab"},
+		{msg: "Valid U+2028 line continuation", p1: []byte("\"a\\\u2028b\""), expected: "ab"},
+		{msg: "Valid hex escape", p1: []byte(`"\x41\x7a"`), expected: "Az"},
+		{msg: "Valid hex escape above ASCII", p1: []byte(`"\xe9"`), expected: "\u00e9"},
+		{msg: "Valid null escape", p1: []byte(`"\0"`), expected: "\x00"},
+		{msg: "Valid vertical tab escape", p1: []byte(`"\v"`), expected: "\v"},
+		{msg: "Valid identity escape", p1: []byte(`"\a\q"`), expected: "aq"},
+		{msg: "Valid multi-byte identity escape", p1: []byte("\"\\\u00e9\""), expected: "\u00e9"},
+
+		// Invalid escapes
+		{msg: "Invalid short hex escape", p1: []byte(`"\x4"`), expectedErr: ErrInvalidEscape},
+		{msg: "Invalid unicode escape", p1: []byte(`"\u00G1"`), expectedErr: ErrInvalidEscape},
+		{msg: "Invalid dangling backslash", p1: []byte(`"\"`), expectedErr: ErrInvalidEscape},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			got, err := unquoteValue(test.p1)
+
+			if got != test.expected || !errors.Is(err, test.expectedErr) {
+				t.Errorf("got (%q, %v), expected (%q, %v)", got, err, test.expected, test.expectedErr)
+			}
+		})
+	}
+}
+
+func TestToHex(t *testing.T) {
+	var tests = []struct {
+		msg      string
+		p1       []byte
+		expected int64
+		isErr    bool
+	}{
+		{msg: "Valid lower case prefix", p1: []byte("0xff"), expected: 255},
+		{msg: "Valid upper case prefix", p1: []byte("0XFF"), expected: 255},
+		{msg: "Valid leading plus", p1: []byte("+0x10"), expected: 16},
+		{msg: "Valid negative", p1: []byte("-0x10"), expected: -16},
+		{msg: "Valid min int64", p1: []byte("-0x8000000000000000"), expected: -1 << 63},
+		{msg: "Invalid overflow", p1: []byte("0x8000000000000000"), isErr: true},
+		{msg: "Invalid digits", p1: []byte("0xG1"), isErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			got, err := ToHex(test.p1)
+
+			if got != test.expected && !test.isErr || (err != nil) != test.isErr {
+				t.Errorf("got (%d, %v), expected (%d, error %t)", got, err, test.expected, test.isErr)
+			}
+		})
+	}
+}
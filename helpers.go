@@ -2,8 +2,12 @@ package jsonvx
 
 import (
 	"bytes"
+	"math/big"
 	"strconv"
+	"strings"
 	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
 )
 
 // isNewLine reports whether a byte is a newline.
@@ -155,7 +159,17 @@ func startsWithDigit(input []byte) bool {
 	return len(input) > 0 && input[0] >= '0' && input[0] <= '9'
 }
 
+// startsOrEndsWithDot reports whether the mantissa of a number, ignoring its sign and exponent,
+// has no digit before or after the decimal point (e.g., `.5`, `-.5` or `5.e3`).
 func startsOrEndsWithDot(input []byte) bool {
+	if len(input) > 0 && (isPlus(input[0]) || isMinus(input[0])) {
+		input = input[1:]
+	}
+
+	if index := bytes.IndexAny(input, "eE"); index >= 0 {
+		input = input[:index]
+	}
+
 	return bytes.HasPrefix(input, []byte{'.'}) || bytes.HasSuffix(input, []byte{'.'})
 }
 
@@ -202,13 +216,35 @@ func isInf(input []byte) bool {
 		input[7] == 'y'
 }
 
+// isInteger reports whether the input is a decimal integer with an optional sign.
+// Integers of any magnitude are accepted, not only those that fit into an int64.
 func isInteger(input []byte) bool {
-	_, err := strconv.ParseInt(string(input), 10, 64)
+	if len(input) > 0 && (isPlus(input[0]) || isMinus(input[0])) {
+		input = input[1:]
+	}
 
-	return err == nil
+	if len(input) == 0 {
+		return false
+	}
+
+	for _, b := range input {
+		if !isDigit(b) {
+			return false
+		}
+	}
+
+	return true
 }
 
 func isFloat(input []byte) bool {
+	// strconv also accepts spellings such as "inf", "nan" and hex floats,
+	// none of which are decimal number literals.
+	for _, b := range input {
+		if !isDigit(b) && !isPlus(b) && !isMinus(b) && !isDot(b) && !isExponent(b) {
+			return false
+		}
+	}
+
 	_, err := strconv.ParseFloat(string(input), 64)
 
 	return err == nil
@@ -221,7 +257,7 @@ func isScientificNotation(input []byte) bool {
 		parts = bytes.Split(input, []byte("E"))
 	}
 
-	if len(parts) < 2 {
+	if len(parts) != 2 {
 		return false
 	}
 
@@ -262,20 +298,137 @@ func isHex(input []byte) bool {
 	return true
 }
 
-func quoteValue(input []byte) string {
-	double := []byte(`"`)
-	single := []byte(`'`)
+// unquoteValue strips the surrounding quotes from a string literal and decodes its escape sequences.
+//
+// Besides the standard JSON escapes (\", \\, \/, \b, \f, \n, \r, \t and \uXXXX, including UTF-16
+// surrogate pairs) it decodes the relaxed forms the lexer accepts when AllowNewlineInStrings or
+// AllowOtherEscapeChars is enabled: escaped line continuations are removed, \v, \0 and \xHH map to
+// their JSON5 meaning, and any other escaped character stands for itself.
+func unquoteValue(input []byte) (string, error) {
+	if len(input) < 2 {
+		return "", ErrInvalidEscape
+	}
 
-	end := double
+	body := input[1 : len(input)-1]
 
-	if bytes.HasPrefix(input, single) {
-		end = single
+	if bytes.IndexByte(body, '\\') < 0 {
+		return string(body), nil
 	}
 
-	after, _ := bytes.CutPrefix(input, end)
-	before, _ := bytes.CutSuffix(after, end)
+	var b strings.Builder
+	b.Grow(len(body))
+
+	for i := 0; i < len(body); {
+		char := body[i]
+
+		if char != '\\' {
+			b.WriteByte(char)
+			i++
+			continue
+		}
+
+		if i+1 >= len(body) {
+			return "", ErrInvalidEscape
+		}
 
-	return string(before)
+		next := body[i+1]
+		i += 2
+
+		switch next {
+		case '"', '\'', '\\', '/':
+			b.WriteByte(next)
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'v':
+			b.WriteByte('\v')
+		case '0':
+			b.WriteByte(0)
+		case '\n':
+			// escaped line continuation, the newline is not part of the value
+		case '\r':
+			// an escaped CRLF is a single line continuation
+			if i < len(body) && body[i] == '\n' {
+				i++
+			}
+		case 'x':
+			if i+2 > len(body) || !isHexDigit(body[i]) || !isHexDigit(body[i+1]) {
+				return "", ErrInvalidEscape
+			}
+
+			b.WriteRune(rune(hexDigitValue(body[i])<<4 | hexDigitValue(body[i+1])))
+			i += 2
+		case 'u':
+			r, ok := decodeUnicodeEscape(body[i:])
+			if !ok {
+				return "", ErrInvalidEscape
+			}
+			i += 4
+
+			if utf16.IsSurrogate(r) {
+				if bytes.HasPrefix(body[i:], []byte(`\u`)) {
+					low, ok := decodeUnicodeEscape(body[i+2:])
+					if pair := utf16.DecodeRune(r, low); ok && pair != utf8.RuneError {
+						b.WriteRune(pair)
+						i += 6
+						continue
+					}
+				}
+
+				r = utf8.RuneError
+			}
+
+			b.WriteRune(r)
+		default:
+			// any other escaped character stands for itself, this also covers
+			// the U+2028 and U+2029 line continuations allowed by JSON5
+			r, size := utf8.DecodeRune(body[i-1:])
+			if r != '\u2028' && r != '\u2029' {
+				b.Write(body[i-1 : i-1+size])
+			}
+			i += size - 1
+		}
+	}
+
+	return b.String(), nil
+}
+
+// decodeUnicodeEscape decodes the 4 hex digits at the start of input, as found after a \u escape.
+func decodeUnicodeEscape(input []byte) (rune, bool) {
+	if len(input) < 4 || !is4HexDigits([4]byte{input[0], input[1], input[2], input[3]}) {
+		return 0, false
+	}
+
+	var r rune
+	for _, char := range input[:4] {
+		r = r<<4 | hexDigitValue(char)
+	}
+
+	return r, true
+}
+
+// isHexDigit reports whether a byte is a valid hexadecimal character (0-9, A-F, a-f).
+func isHexDigit(b byte) bool {
+	return isDigit(b) || isHexLetter(b)
+}
+
+// hexDigitValue returns the numeric value of a hexadecimal character.
+func hexDigitValue(b byte) rune {
+	switch {
+	case isDigit(b):
+		return rune(b - '0')
+	case b >= 'a' && b <= 'f':
+		return rune(b-'a') + 10
+	default:
+		return rune(b-'A') + 10
+	}
 }
 
 // func intValue(input []byte) int64 {
@@ -290,10 +443,56 @@ func quoteValue(input []byte) string {
 // 	return num
 // }
 
+// ToInt parses a decimal integer literal, with an optional sign, as an int64.
 func ToInt(input []byte) (int64, error) {
 	return strconv.ParseInt(string(input), 10, 64)
 }
 
+// ToFloat parses a decimal number literal, with an optional sign, as a float64.
+// Point edge forms such as `.5` and `5.` are accepted.
 func ToFloat(input []byte) (float64, error) {
 	return strconv.ParseFloat(string(input), 64)
 }
+
+// ToHex parses a hexadecimal integer literal with a 0x or 0X prefix and an optional sign (e.g., `-0xFF`) as an int64.
+func ToHex(input []byte) (int64, error) {
+	neg, digits := splitHex(input)
+
+	if neg {
+		return strconv.ParseInt("-"+string(digits), 16, 64)
+	}
+
+	return strconv.ParseInt(string(digits), 16, 64)
+}
+
+// hexToFloat parses a hexadecimal integer literal of any size, as ToHex does, as the nearest float64.
+func hexToFloat(input []byte) (float64, bool) {
+	neg, digits := splitHex(input)
+
+	intVal, ok := new(big.Int).SetString(string(digits), 16)
+	if !ok {
+		return 0, false
+	}
+	if neg {
+		intVal.Neg(intVal)
+	}
+
+	val, _ := new(big.Float).SetInt(intVal).Float64()
+	return val, true
+}
+
+// splitHex separates the sign of a hexadecimal literal from its digits, dropping the 0x prefix.
+func splitHex(input []byte) (bool, []byte) {
+	neg := false
+
+	if len(input) > 0 && (isPlus(input[0]) || isMinus(input[0])) {
+		neg = isMinus(input[0])
+		input = input[1:]
+	}
+
+	if len(input) >= 2 && input[0] == '0' && (input[1] == 'x' || input[1] == 'X') {
+		input = input[2:]
+	}
+
+	return neg, input
+}
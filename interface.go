@@ -0,0 +1,246 @@
+package jsonvx
+
+import (
+	"math"
+)
+
+// InterfaceConfig holds the options of ToInterface and FromInterface.
+type InterfaceConfig struct {
+	UseNumber bool            // UseNumber makes ToInterface return finite numbers as json.Number instead of float64, keeping their precision.
+	NonFinite NonFinitePolicy // NonFinite controls what NaN and Infinity are converted to.
+}
+
+// NonFinitePolicy controls how NaN and Infinity are converted by ToInterface and FromInterface.
+type NonFinitePolicy int
+
+const (
+	NonFiniteFloat  NonFinitePolicy = iota // NonFiniteFloat converts NaN and Infinity numbers to and from the float64 values math.NaN() and math.Inf().
+	NonFiniteError                         // NonFiniteError fails with ErrNumberNotFinite.
+	NonFiniteNull                          // NonFiniteNull converts them to null.
+	NonFiniteString                        // NonFiniteString converts them to the strings "NaN", "Infinity" and "-Infinity".
+)
+
+// String returns a string representation of the NonFinitePolicy.
+func (n NonFinitePolicy) String() string {
+	m := map[NonFinitePolicy]string{
+		NonFiniteFloat:  "NonFiniteFloat",
+		NonFiniteError:  "NonFiniteError",
+		NonFiniteNull:   "NonFiniteNull",
+		NonFiniteString: "NonFiniteString",
+	}
+
+	if str, ok := m[n]; ok {
+		return str
+	}
+	return "UNKNOWN"
+}
+
+// NewInterfaceConfig creates a new InterfaceConfig, applying the given options.
+// By default, numbers are converted to float64 and NaN and Infinity are kept as float64 values.
+func NewInterfaceConfig(opts ...func(*InterfaceConfig)) *InterfaceConfig {
+	cfg := &InterfaceConfig{}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+// WithUseNumber is the functional option setter for UseNumber.
+func WithUseNumber(useNumber bool) func(*InterfaceConfig) {
+	return func(c *InterfaceConfig) {
+		c.UseNumber = useNumber
+	}
+}
+
+// WithNonFinite is the functional option setter for the NonFinite policy.
+func WithNonFinite(policy NonFinitePolicy) func(*InterfaceConfig) {
+	return func(c *InterfaceConfig) {
+		c.NonFinite = policy
+	}
+}
+
+// ToInterface converts node into the generic representation of encoding/json: map[string]any for
+// objects, []any for arrays, string, float64 (or json.Number with UseNumber), bool and nil.
+// When an object holds a key more than once, the last value wins.
+func ToInterface(node JSON, opts ...func(*InterfaceConfig)) (any, error) {
+	return toInterface(node, NewInterfaceConfig(opts...))
+}
+
+func toInterface(node JSON, cfg *InterfaceConfig) (any, error) {
+	if isNilNode(node) {
+		return nil, ErrInvalidJSONType
+	}
+
+	switch val := node.(type) {
+	case *Null:
+		return val.Value()
+	case *Boolean:
+		return val.Value()
+	case *String:
+		return val.Value()
+	case *Number:
+		if isNonFinite(val) {
+			return nonFiniteInterface(val, cfg)
+		}
+		if cfg.UseNumber {
+			return val.JSONNumber()
+		}
+		return val.Value()
+	case *Array:
+		items := make([]any, val.Len())
+		for i, item := range val.Items {
+			itemVal, err := toInterface(item, cfg)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = itemVal
+		}
+		return items, nil
+	case *Object:
+		props := make(map[string]any, val.Len())
+		for _, prop := range val.Properties {
+			propVal, err := toInterface(prop.value, cfg)
+			if err != nil {
+				return nil, err
+			}
+			props[string(prop.key)] = propVal
+		}
+		return props, nil
+	case *ErrorNode:
+		if val.Err == nil {
+			return nil, ErrInvalidJSONType
+		}
+		return nil, val.Err
+	default:
+		return nil, ErrInvalidJSONType
+	}
+}
+
+// nonFiniteInterface converts a NaN or Infinity number according to the NonFinite policy.
+func nonFiniteInterface(num *Number, cfg *InterfaceConfig) (any, error) {
+	switch cfg.NonFinite {
+	case NonFiniteError:
+		_, err := num.Decimal()
+		return nil, err
+	case NonFiniteNull:
+		return nil, nil
+	case NonFiniteString:
+		floatVal, err := num.Value()
+		if err != nil {
+			return nil, err
+		}
+		return nonFiniteName(floatVal), nil
+	default:
+		return num.Value()
+	}
+}
+
+// FromInterface builds the tree of nodes for v, the reverse of ToInterface: map[string]any becomes
+// an Object, []any an Array, and strings, numbers, booleans and nil the matching scalar nodes.
+// Any other value is converted like MarshalNode does, with struct tags and marshaler methods.
+//
+// NaN and Infinity floats become NaN and Infinity numbers, which only relaxed output accepts,
+// unless the NonFinite policy says otherwise.
+func FromInterface(v any, opts ...func(*InterfaceConfig)) (JSON, error) {
+	cfg := NewInterfaceConfig(opts...)
+
+	node, err := MarshalNode(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.NonFinite == NonFiniteFloat {
+		return node, nil
+	}
+
+	return replaceNonFinite(node, cfg)
+}
+
+// replaceNonFinite returns node with its NaN and Infinity numbers replaced according to the NonFinite
+// policy. Containers holding such numbers are copied, so nodes passed to FromInterface are left untouched.
+func replaceNonFinite(node JSON, cfg *InterfaceConfig) (JSON, error) {
+	switch val := node.(type) {
+	case *Number:
+		if !isNonFinite(val) {
+			return val, nil
+		}
+
+		switch cfg.NonFinite {
+		case NonFiniteError:
+			_, err := val.Decimal()
+			return nil, err
+		case NonFiniteNull:
+			return marshalNull(), nil
+		default:
+			floatVal, err := val.Value()
+			if err != nil {
+				return nil, err
+			}
+			return marshalString(nonFiniteName(floatVal)), nil
+		}
+	case *Array:
+		var items []JSON
+
+		for i, item := range val.Items {
+			newItem, err := replaceNonFinite(item, cfg)
+			if err != nil {
+				return nil, err
+			}
+
+			if newItem != item && items == nil {
+				items = append(make([]JSON, 0, val.Len()), val.Items[:i]...)
+			}
+			if items != nil {
+				items = append(items, newItem)
+			}
+		}
+
+		if items == nil {
+			return val, nil
+		}
+		return newArray(items, nil), nil
+	case *Object:
+		var props []KeyValue
+
+		for i, prop := range val.Properties {
+			newValue, err := replaceNonFinite(prop.value, cfg)
+			if err != nil {
+				return nil, err
+			}
+
+			if newValue != prop.value && props == nil {
+				props = append(make([]KeyValue, 0, val.Len()), val.Properties[:i]...)
+			}
+			if props != nil {
+				prop.value = newValue
+				props = append(props, prop)
+			}
+		}
+
+		if props == nil {
+			return val, nil
+		}
+		return newObject(props, nil), nil
+	default:
+		return node, nil
+	}
+}
+
+// isNonFinite reports whether num is NaN or Infinity.
+func isNonFinite(num *Number) bool {
+	return num.Token != nil && (num.Token.SubKind == NaN || num.Token.SubKind == INF)
+}
+
+// nonFiniteName returns the name of a NaN or infinite float, as written in JSON5.
+func nonFiniteName(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	default:
+		return "NaN"
+	}
+}
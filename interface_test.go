@@ -0,0 +1,138 @@
+package jsonvx
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestToInterface(t *testing.T) {
+	var tests = []struct {
+		msg         string
+		input       string
+		opts        []func(*InterfaceConfig)
+		expected    any
+		expectedErr error
+	}{
+		{msg: "Convert null", input: `null`, expected: nil},
+		{msg: "Convert scalars", input: `[true, 'a\nb', 0x10, .5, +1e2]`, expected: []any{true, "a\nb", 16.0, 0.5, 100.0}},
+		{msg: "Convert nested containers", input: `{a: [{}, []], b: {c: null}}`, expected: map[string]any{"a": []any{map[string]any{}, []any{}}, "b": map[string]any{"c": nil}}},
+		{msg: "Convert duplicate keys", input: `{a: 1, a: 2}`, expected: map[string]any{"a": 2.0}},
+		{msg: "Convert numbers with UseNumber", input: `[12345678901234567890, 0xFF, 5.]`, opts: []func(*InterfaceConfig){WithUseNumber(true)}, expected: []any{json.Number("12345678901234567890"), json.Number("255"), json.Number("5")}},
+		{msg: "Convert non-finite numbers to strings", input: `[NaN, Infinity, -Infinity]`, opts: []func(*InterfaceConfig){WithNonFinite(NonFiniteString)}, expected: []any{"NaN", "Infinity", "-Infinity"}},
+		{msg: "Convert non-finite numbers to null", input: `{a: -Infinity}`, opts: []func(*InterfaceConfig){WithNonFinite(NonFiniteNull), WithUseNumber(true)}, expected: map[string]any{"a": nil}},
+		{msg: "Convert non-finite numbers with an error", input: `[1, NaN]`, opts: []func(*InterfaceConfig){WithNonFinite(NonFiniteError)}, expectedErr: ErrNumberNotFinite},
+		{msg: "Convert infinity to float", input: `Infinity`, expected: math.Inf(1)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			parser := NewParser([]byte(test.input), JSON5Config())
+			node, err := parser.Parse()
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+
+			got, err := ToInterface(node, test.opts...)
+
+			if !reflect.DeepEqual(got, test.expected) || !errors.Is(err, test.expectedErr) {
+				t.Errorf("got (%#v, %v), expected (%#v, %v)", got, err, test.expected, test.expectedErr)
+			}
+		})
+	}
+}
+
+func TestToInterfaceNaN(t *testing.T) {
+	parser := NewParser([]byte(`NaN`), JSON5Config())
+	node, _ := parser.Parse()
+
+	got, err := ToInterface(node)
+	if f, ok := got.(float64); !ok || !math.IsNaN(f) || err != nil {
+		t.Errorf("got (%v, %v), expected NaN", got, err)
+	}
+
+	if _, err := ToInterface(&ErrorNode{}); !errors.Is(err, ErrInvalidJSONType) {
+		t.Errorf("got %v, expected %v", err, ErrInvalidJSONType)
+	}
+}
+
+func TestFromInterface(t *testing.T) {
+	var tests = []struct {
+		msg         string
+		input       any
+		opts        []func(*InterfaceConfig)
+		expected    string
+		expectedErr error
+	}{
+		{msg: "Build scalars", input: []any{nil, true, "s", 1.5, 42, json.Number("1e3")}, expected: `[null,true,"s",1.5,42,1e3]`},
+		{msg: "Build sorted object", input: map[string]any{"b": []any{}, "a": map[string]any{}}, expected: `{"a":{},"b":[]}`},
+		{msg: "Build non-finite floats", input: []any{math.NaN(), math.Inf(1)}, expected: `[NaN,Infinity]`},
+		{msg: "Build non-finite floats as null", input: map[string]any{"a": []any{1.0, math.Inf(-1)}}, opts: []func(*InterfaceConfig){WithNonFinite(NonFiniteNull)}, expected: `{"a":[1,null]}`},
+		{msg: "Build non-finite floats as strings", input: []float64{math.NaN(), math.Inf(-1)}, opts: []func(*InterfaceConfig){WithNonFinite(NonFiniteString)}, expected: `["NaN","-Infinity"]`},
+		{msg: "Build non-finite floats with an error", input: map[string]any{"a": math.NaN()}, opts: []func(*InterfaceConfig){WithNonFinite(NonFiniteError)}, expectedErr: ErrNumberNotFinite},
+		{msg: "Build unsupported value", input: []any{func() {}}, expectedErr: ErrUnsupportedValue},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			var got []byte
+
+			// NaN and Infinity are allowed in the output, so they show up in it
+			cfg := NewEncoderConfig(WithDialect(NewParserConfig(WithAllowNaN(true), WithAllowInfinity(true))))
+
+			node, err := FromInterface(test.input, test.opts...)
+			if err == nil {
+				got, err = Encode(node, cfg)
+			}
+
+			if string(got) != test.expected || !errors.Is(err, test.expectedErr) {
+				t.Errorf("got (%s, %v), expected (%s, %v)", got, err, test.expected, test.expectedErr)
+			}
+		})
+	}
+}
+
+func TestFromInterfaceKeepsNodes(t *testing.T) {
+	parser := NewParser([]byte(`[1, NaN]`), JSON5Config())
+	node, _ := parser.Parse()
+
+	built, err := FromInterface(map[string]any{"node": node}, WithNonFinite(NonFiniteNull))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got, _ := Encode(built, nil); string(got) != `{"node":[1,null]}` {
+		t.Errorf("got %s, expected %s", got, `{"node":[1,null]}`)
+	}
+
+	// the node passed in is not modified
+	if got := node.(*Array).Items[1]; !isNonFinite(got.(*Number)) {
+		t.Errorf("got %v, expected the original NaN", got)
+	}
+}
+
+func TestInterfaceRoundTrip(t *testing.T) {
+	input := `{"a": [1, "two", {"three": [true, null]}], "b": 2.5}`
+
+	parser := NewParser([]byte(input), nil)
+	node, _ := parser.Parse()
+
+	generic, err := ToInterface(node)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	built, err := FromInterface(generic)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, _ := Encode(built, nil)
+	expected, _ := Encode(node, nil)
+
+	if string(got) != string(expected) {
+		t.Errorf("got %s, expected %s", got, expected)
+	}
+}
@@ -0,0 +1,816 @@
+package jsonvx
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ErrInvalidJSONPath is the error for a JSONPath query that is not valid under RFC 9535.
+var ErrInvalidJSONPath = errors.New("invalid JSONPath query")
+
+// maxPathInt is the largest index allowed in a JSONPath query, the bound of the I-JSON exact integers.
+const maxPathInt = 1<<53 - 1
+
+// JSONPath is a compiled RFC 9535 JSONPath query.
+//
+// Every part of the RFC is supported: member names (`$.a`, `$['a']`), wildcards (`*`), indices (`[-1]`),
+// slices (`[1:5:2]`), unions (`[0,'a']`), descendant segments (`..`) and filters (`[?@.price < 10]`),
+// with comparisons, logical operators and the standard functions length, count, match, search and value.
+type JSONPath struct {
+	query    string
+	segments []pathSegment
+}
+
+// PathMatch is a node selected by a JSONPath query.
+type PathMatch struct {
+	Node JSON   // Node is the selected node.
+	Path string // Path is the normalized path of the node from the queried one, e.g. `$['store']['book'][0]`.
+
+	steps []pathStep
+}
+
+// CompileJSONPath parses a JSONPath query, so it can be run against any number of nodes.
+// It fails with an error wrapping ErrInvalidJSONPath if the query is not valid under RFC 9535.
+func CompileJSONPath(query string) (*JSONPath, error) {
+	p := pathParser{query: query}
+
+	segments, err := p.parseQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	return &JSONPath{query: query, segments: segments}, nil
+}
+
+// QueryJSONPath compiles the JSONPath query and runs it against node, see CompileJSONPath and JSONPath.Query.
+func QueryJSONPath(node JSON, query string) ([]PathMatch, error) {
+	path, err := CompileJSONPath(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return path.Query(node), nil
+}
+
+// String returns the query the JSONPath was compiled from.
+func (jp *JSONPath) String() string {
+	return jp.query
+}
+
+// Query returns the nodes selected by the query from node, the root `$`, in document order.
+// A query selecting nothing returns an empty result; a node can be selected more than once,
+// e.g. by `$[0,0]`.
+func (jp *JSONPath) Query(node JSON) []PathMatch {
+	ev := pathEvaluator{root: node}
+	nodes := ev.evalSegments([]pathNode{{node: node}}, jp.segments, true)
+
+	matches := make([]PathMatch, len(nodes))
+	for i, n := range nodes {
+		matches[i] = PathMatch{Node: n.node, Path: normalizedPath(n.steps), steps: n.steps}
+	}
+
+	return matches
+}
+
+// pathSegment is a child segment (`.a`, `[...]`) or a descendant segment (`..a`, `..[...]`) of a query.
+type pathSegment struct {
+	descendant bool
+	selectors  []pathSelector
+}
+
+type selectorKind int
+
+const (
+	nameSelector selectorKind = iota
+	wildcardSelector
+	indexSelector
+	sliceSelector
+	filterSelector
+)
+
+// pathSelector selects children of a node.
+type pathSelector struct {
+	kind   selectorKind
+	name   string     // name is the member name of a name selector.
+	index  int        // index is the index of an index selector.
+	slice  [3]*int    // slice holds the start, end and step of a slice selector, nil when omitted.
+	filter filterExpr // filter is the logical expression of a filter selector.
+}
+
+// pathParser parses a JSONPath query, following the ABNF grammar of RFC 9535.
+type pathParser struct {
+	query string
+	pos   int
+}
+
+// errorf returns an error under ErrInvalidJSONPath, at the current position.
+func (p *pathParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: %s at offset %d of %q", ErrInvalidJSONPath, fmt.Sprintf(format, args...), p.pos, p.query)
+}
+
+// peek returns the byte at the current position, or 0 at the end of the query.
+func (p *pathParser) peek() byte {
+	if p.pos < len(p.query) {
+		return p.query[p.pos]
+	}
+	return 0
+}
+
+// skipBlank skips the blank space (spaces, tabs and line breaks) at the current position.
+func (p *pathParser) skipBlank() {
+	for p.pos < len(p.query) && strings.IndexByte(" \t\n\r", p.query[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *pathParser) parseQuery() ([]pathSegment, error) {
+	if p.peek() != '$' {
+		return nil, p.errorf("query must start with '$'")
+	}
+	p.pos++
+
+	segments, err := p.parseSegments()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos < len(p.query) {
+		return nil, p.errorf("unexpected %q", p.query[p.pos:])
+	}
+
+	return segments, nil
+}
+
+// parseSegments parses the segments following '$' or '@', each possibly preceded by blank space.
+func (p *pathParser) parseSegments() ([]pathSegment, error) {
+	var segments []pathSegment
+
+	for {
+		start := p.pos
+		p.skipBlank()
+
+		if c := p.peek(); c != '.' && c != '[' {
+			p.pos = start
+			return segments, nil
+		}
+
+		segment, err := p.parseSegment()
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, segment)
+	}
+}
+
+func (p *pathParser) parseSegment() (pathSegment, error) {
+	if p.peek() == '[' {
+		selectors, err := p.parseBracketed()
+		return pathSegment{selectors: selectors}, err
+	}
+
+	// the segment starts with '.'
+	p.pos++
+	descendant := p.peek() == '.'
+	if descendant {
+		p.pos++
+	}
+
+	switch c := p.peek(); {
+	case descendant && c == '[':
+		selectors, err := p.parseBracketed()
+		return pathSegment{descendant: true, selectors: selectors}, err
+	case c == '*':
+		p.pos++
+		return pathSegment{descendant: descendant, selectors: []pathSelector{{kind: wildcardSelector}}}, nil
+	default:
+		name, ok := p.parseMemberName()
+		if !ok {
+			return pathSegment{}, p.errorf("expected a member name or '*'")
+		}
+		return pathSegment{descendant: descendant, selectors: []pathSelector{{kind: nameSelector, name: name}}}, nil
+	}
+}
+
+// parseMemberName parses the member name of a shorthand segment (e.g., `.name`).
+func (p *pathParser) parseMemberName() (string, bool) {
+	start := p.pos
+
+	for p.pos < len(p.query) {
+		char, size := utf8.DecodeRuneInString(p.query[p.pos:])
+
+		isFirst := char == '_' || (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') ||
+			(char >= 0x80 && !(char == utf8.RuneError && size == 1))
+		if !isFirst && (p.pos == start || char < '0' || char > '9') {
+			break
+		}
+
+		p.pos += size
+	}
+
+	return p.query[start:p.pos], p.pos > start
+}
+
+// parseBracketed parses the selectors of a bracketed selection, e.g. `[0, 'a', ?@.b]`.
+func (p *pathParser) parseBracketed() ([]pathSelector, error) {
+	var selectors []pathSelector
+
+	p.pos++ // '['
+
+	for {
+		p.skipBlank()
+
+		selector, err := p.parseSelector()
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, selector)
+
+		p.skipBlank()
+
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return selectors, nil
+		default:
+			return nil, p.errorf("expected ',' or ']'")
+		}
+	}
+}
+
+func (p *pathParser) parseSelector() (pathSelector, error) {
+	switch c := p.peek(); {
+	case c == '\'' || c == '"':
+		name, err := p.parseString()
+		return pathSelector{kind: nameSelector, name: name}, err
+	case c == '*':
+		p.pos++
+		return pathSelector{kind: wildcardSelector}, nil
+	case c == '?':
+		p.pos++
+		p.skipBlank()
+
+		expr, err := p.parseLogicalOr()
+		if err != nil {
+			return pathSelector{}, err
+		}
+		if err := p.checkLogical(expr); err != nil {
+			return pathSelector{}, err
+		}
+
+		return pathSelector{kind: filterSelector, filter: expr}, nil
+	case c == '-' || c == ':' || (c >= '0' && c <= '9'):
+		return p.parseIndexOrSlice()
+	default:
+		return pathSelector{}, p.errorf("expected a selector")
+	}
+}
+
+// parseIndexOrSlice parses an index selector (e.g., `-1`) or a slice selector (e.g., `1:5:2`).
+func (p *pathParser) parseIndexOrSlice() (pathSelector, error) {
+	var slice [3]*int
+
+	for part := 0; part < 3; part++ {
+		if c := p.peek(); c == '-' || (c >= '0' && c <= '9') {
+			n, err := p.parseInt()
+			if err != nil {
+				return pathSelector{}, err
+			}
+			slice[part] = &n
+			p.skipBlank()
+		}
+
+		if part == 0 && p.peek() != ':' {
+			if slice[0] == nil {
+				return pathSelector{}, p.errorf("expected an index")
+			}
+			return pathSelector{kind: indexSelector, index: *slice[0]}, nil
+		}
+
+		if part == 2 || p.peek() != ':' {
+			break
+		}
+
+		p.pos++
+		p.skipBlank()
+	}
+
+	return pathSelector{kind: sliceSelector, slice: slice}, nil
+}
+
+// parseInt parses an integer without leading zeros, within the I-JSON range.
+func (p *pathParser) parseInt() (int, error) {
+	start := p.pos
+
+	if p.peek() == '-' {
+		p.pos++
+	}
+
+	digits := p.pos
+	for c := p.peek(); c >= '0' && c <= '9'; c = p.peek() {
+		p.pos++
+	}
+
+	literal := p.query[start:p.pos]
+
+	switch {
+	case p.pos == digits:
+		return 0, p.errorf("expected digits")
+	case p.query[digits] == '0' && (p.pos-digits > 1 || digits > start):
+		p.pos = start
+		return 0, p.errorf("invalid integer %q", literal)
+	}
+
+	n, err := strconv.ParseInt(literal, 10, 64)
+	if err != nil || n > maxPathInt || n < -maxPathInt {
+		p.pos = start
+		return 0, p.errorf("integer %s out of range", literal)
+	}
+
+	return int(n), nil
+}
+
+// parseString parses a single- or double-quoted string literal, decoding its escape sequences.
+func (p *pathParser) parseString() (string, error) {
+	var builder strings.Builder
+
+	quote := p.query[p.pos]
+	p.pos++
+
+	for {
+		if p.pos >= len(p.query) {
+			return "", p.errorf("unterminated string")
+		}
+
+		c := p.query[p.pos]
+
+		switch {
+		case c == quote:
+			p.pos++
+			return builder.String(), nil
+		case c < 0x20:
+			return "", p.errorf("control character in string")
+		case c != '\\':
+			builder.WriteByte(c)
+			p.pos++
+			continue
+		}
+
+		p.pos++
+
+		switch esc := p.peek(); esc {
+		case 'b':
+			builder.WriteByte('\b')
+		case 'f':
+			builder.WriteByte('\f')
+		case 'n':
+			builder.WriteByte('\n')
+		case 'r':
+			builder.WriteByte('\r')
+		case 't':
+			builder.WriteByte('\t')
+		case '/', '\\':
+			builder.WriteByte(esc)
+		case '"', '\'':
+			if esc != quote {
+				return "", p.errorf("invalid escape sequence \\%c", esc)
+			}
+			builder.WriteByte(esc)
+		case 'u':
+			char, err := p.parseUnicodeEscape()
+			if err != nil {
+				return "", err
+			}
+			builder.WriteRune(char)
+			continue
+		default:
+			return "", p.errorf("invalid escape sequence")
+		}
+
+		p.pos++
+	}
+}
+
+// parseUnicodeEscape parses the `uXXXX` of a unicode escape sequence, and the low surrogate following a high one.
+func (p *pathParser) parseUnicodeEscape() (rune, error) {
+	hex4 := func() (rune, bool) {
+		if p.pos+5 > len(p.query) || p.query[p.pos] != 'u' {
+			return 0, false
+		}
+		n, err := strconv.ParseUint(p.query[p.pos+1:p.pos+5], 16, 32)
+		if err != nil {
+			return 0, false
+		}
+		p.pos += 5
+		return rune(n), true
+	}
+
+	char, ok := hex4()
+	switch {
+	case !ok:
+		return 0, p.errorf("invalid unicode escape sequence")
+	case char >= 0xDC00 && char <= 0xDFFF:
+		return 0, p.errorf("lone low surrogate in unicode escape sequence")
+	case char < 0xD800 || char > 0xDBFF:
+		return char, nil
+	}
+
+	if !strings.HasPrefix(p.query[p.pos:], `\`) {
+		return 0, p.errorf("high surrogate without a low surrogate")
+	}
+
+	p.pos++
+	low, ok := hex4()
+	if !ok || low < 0xDC00 || low > 0xDFFF {
+		return 0, p.errorf("high surrogate without a low surrogate")
+	}
+
+	return utf16.DecodeRune(char, low), nil
+}
+
+// parseLogicalOr parses operands joined by `||`.
+func (p *pathParser) parseLogicalOr() (filterExpr, error) {
+	return p.parseLogicalChain("||", p.parseLogicalAnd)
+}
+
+// parseLogicalAnd parses operands joined by `&&`.
+func (p *pathParser) parseLogicalAnd() (filterExpr, error) {
+	return p.parseLogicalChain("&&", p.parseBasic)
+}
+
+// parseLogicalChain parses operands joined by the operator, each one being parsed by parseOperand.
+func (p *pathParser) parseLogicalChain(operator string, parseOperand func() (filterExpr, error)) (filterExpr, error) {
+	left, err := parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		start := p.pos
+		p.skipBlank()
+
+		if !strings.HasPrefix(p.query[p.pos:], operator) {
+			p.pos = start
+			return left, nil
+		}
+
+		if err := p.checkLogical(left); err != nil {
+			return nil, err
+		}
+
+		p.pos += len(operator)
+		p.skipBlank()
+
+		right, err := parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.checkLogical(right); err != nil {
+			return nil, err
+		}
+
+		if operator == "||" {
+			left = orExpr{left, right}
+		} else {
+			left = andExpr{left, right}
+		}
+	}
+}
+
+// parseBasic parses a parenthesized expression, a negation, a comparison, or a single operand
+// whose type is checked by the caller: a query, a function call or a literal.
+func (p *pathParser) parseBasic() (filterExpr, error) {
+	switch p.peek() {
+	case '!':
+		p.pos++
+		p.skipBlank()
+
+		var operand filterExpr
+		var err error
+
+		if p.peek() == '(' {
+			operand, err = p.parseParen()
+		} else {
+			operand, err = p.parseOperand()
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.checkLogical(operand); err != nil {
+			return nil, err
+		}
+
+		return notExpr{operand}, nil
+	case '(':
+		return p.parseParen()
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	start := p.pos
+	p.skipBlank()
+
+	operator := ""
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if strings.HasPrefix(p.query[p.pos:], op) {
+			operator = op
+			break
+		}
+	}
+
+	if operator == "" {
+		p.pos = start
+		return left, nil
+	}
+
+	p.pos += len(operator)
+	p.skipBlank()
+
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, operand := range []filterExpr{left, right} {
+		if err := p.checkComparable(operand); err != nil {
+			return nil, err
+		}
+	}
+
+	return comparisonExpr{operator: operator, left: left, right: right}, nil
+}
+
+// parseParen parses a parenthesized logical expression.
+func (p *pathParser) parseParen() (filterExpr, error) {
+	p.pos++ // '('
+	p.skipBlank()
+
+	expr, err := p.parseLogicalOr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.checkLogical(expr); err != nil {
+		return nil, err
+	}
+
+	p.skipBlank()
+	if p.peek() != ')' {
+		return nil, p.errorf("expected ')'")
+	}
+	p.pos++
+
+	return parenExpr{expr}, nil
+}
+
+// parseOperand parses a query, a function call or a literal.
+func (p *pathParser) parseOperand() (filterExpr, error) {
+	switch c := p.peek(); {
+	case c == '@' || c == '$':
+		p.pos++
+
+		segments, err := p.parseSegments()
+		if err != nil {
+			return nil, err
+		}
+
+		return queryExpr{relative: c == '@', segments: segments}, nil
+	case c == '\'' || c == '"':
+		str, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return literalExpr{marshalString(str)}, nil
+	case c == '-' || (c >= '0' && c <= '9'):
+		return p.parseNumber()
+	case c >= 'a' && c <= 'z':
+		start := p.pos
+		for c := p.peek(); (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '_'; c = p.peek() {
+			p.pos++
+		}
+		name := p.query[start:p.pos]
+
+		if p.peek() == '(' {
+			return p.parseFunction(name)
+		}
+
+		switch name {
+		case "true":
+			return literalExpr{marshalBoolean(true)}, nil
+		case "false":
+			return literalExpr{marshalBoolean(false)}, nil
+		case "null":
+			return literalExpr{marshalNull()}, nil
+		}
+
+		p.pos = start
+		return nil, p.errorf("unexpected name %q", name)
+	default:
+		return nil, p.errorf("expected a literal, a query or a function")
+	}
+}
+
+// parseNumber parses a number literal: an integer, or `-0`, with optional fraction and exponent.
+func (p *pathParser) parseNumber() (filterExpr, error) {
+	start := p.pos
+
+	if strings.HasPrefix(p.query[p.pos:], "-0") {
+		p.pos += 2
+	} else if _, err := p.parseInt(); err != nil {
+		return nil, err
+	}
+
+	subKind := INTEGER
+
+	if p.peek() == '.' {
+		p.pos++
+		if !p.skipDigits() {
+			return nil, p.errorf("expected digits after '.'")
+		}
+		subKind = FLOAT
+	}
+
+	if c := p.peek(); c == 'e' || c == 'E' {
+		p.pos++
+		if c := p.peek(); c == '+' || c == '-' {
+			p.pos++
+		}
+		if !p.skipDigits() {
+			return nil, p.errorf("expected digits in exponent")
+		}
+		subKind = SCI_NOT
+	}
+
+	return literalExpr{marshalNumber(p.query[start:p.pos], subKind)}, nil
+}
+
+// skipDigits skips the decimal digits at the current position, it reports whether there were any.
+func (p *pathParser) skipDigits() bool {
+	start := p.pos
+	for c := p.peek(); c >= '0' && c <= '9'; c = p.peek() {
+		p.pos++
+	}
+	return p.pos > start
+}
+
+// parseFunction parses the arguments of a call to the named function, and checks their types.
+func (p *pathParser) parseFunction(name string) (filterExpr, error) {
+	fn, ok := pathFunctions[name]
+	if !ok {
+		return nil, p.errorf("unknown function %s()", name)
+	}
+
+	p.pos++ // '('
+	p.skipBlank()
+
+	var args []filterExpr
+
+	for p.peek() != ')' {
+		if len(args) > 0 {
+			if p.peek() != ',' {
+				return nil, p.errorf("expected ',' or ')'")
+			}
+			p.pos++
+			p.skipBlank()
+		}
+
+		arg, err := p.parseLogicalOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(args) < len(fn.params) {
+			if err := p.checkArgument(arg, fn.params[len(args)]); err != nil {
+				return nil, fmt.Errorf("argument %d of %s(): %w", len(args)+1, name, err)
+			}
+		}
+
+		args = append(args, arg)
+		p.skipBlank()
+	}
+
+	if len(args) != len(fn.params) {
+		return nil, p.errorf("%s() takes %d arguments, got %d", name, len(fn.params), len(args))
+	}
+
+	p.pos++ // ')'
+
+	// a literal pattern is compiled once, rather than on every call
+	if name == "match" || name == "search" {
+		if lit, ok := args[1].(literalExpr); ok {
+			if str, ok := lit.value.(*String); ok {
+				if pattern, err := str.Value(); err == nil {
+					re, _ := compileIRegexp(pattern, name == "match")
+					args[1] = regexpExpr{re}
+				}
+			}
+		}
+	}
+
+	return functionExpr{name: name, fn: fn, args: args}, nil
+}
+
+// checkLogical reports an error if expr cannot be used as a logical expression,
+// either as a test of the existence of nodes or as a truth value.
+func (p *pathParser) checkLogical(expr filterExpr) error {
+	switch val := expr.(type) {
+	case literalExpr:
+		return p.errorf("a literal must be compared")
+	case functionExpr:
+		if val.fn.result == valueType {
+			return p.errorf("the result of %s() must be compared", val.name)
+		}
+	}
+
+	return nil
+}
+
+// checkComparable reports an error if expr cannot be an operand of a comparison.
+func (p *pathParser) checkComparable(expr filterExpr) error {
+	switch val := expr.(type) {
+	case literalExpr:
+		return nil
+	case queryExpr:
+		if !val.isSingular() {
+			return p.errorf("only singular queries can be compared")
+		}
+		return nil
+	case functionExpr:
+		if val.fn.result != valueType {
+			return p.errorf("the result of %s() cannot be compared", val.name)
+		}
+		return nil
+	default:
+		return p.errorf("a logical expression cannot be compared")
+	}
+}
+
+// checkArgument reports an error if expr cannot be passed to a function parameter of type typ.
+func (p *pathParser) checkArgument(expr filterExpr, typ pathType) error {
+	switch typ {
+	case valueType:
+		return p.checkComparable(expr)
+	case logicalType:
+		return p.checkLogical(expr)
+	default:
+		switch val := expr.(type) {
+		case queryExpr:
+			return nil
+		case functionExpr:
+			if val.fn.result == nodesType {
+				return nil
+			}
+		}
+		return p.errorf("expected a query")
+	}
+}
+
+// normalizedPath returns the normalized path (RFC 9535, section 2.7) for the steps from the root.
+func normalizedPath(steps []pathStep) string {
+	var builder strings.Builder
+	builder.WriteByte('$')
+
+	for _, step := range steps {
+		if step.isIndex {
+			fmt.Fprintf(&builder, "[%d]", step.index)
+			continue
+		}
+
+		builder.WriteString("['")
+		for _, char := range step.name {
+			switch char {
+			case '\b':
+				builder.WriteString(`\b`)
+			case '\f':
+				builder.WriteString(`\f`)
+			case '\n':
+				builder.WriteString(`\n`)
+			case '\r':
+				builder.WriteString(`\r`)
+			case '\t':
+				builder.WriteString(`\t`)
+			case '\'':
+				builder.WriteString(`\'`)
+			case '\\':
+				builder.WriteString(`\\`)
+			default:
+				if char < 0x20 {
+					fmt.Fprintf(&builder, `\u%04x`, char)
+				} else {
+					builder.WriteRune(char)
+				}
+			}
+		}
+		builder.WriteString("']")
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,680 @@
+package jsonvx
+
+import (
+	"cmp"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// pathStep is a step of a normalized path: a member name or an array index.
+type pathStep struct {
+	name    string
+	index   int
+	isIndex bool
+}
+
+// pathNode is a node reached by a query, along with the steps leading to it.
+type pathNode struct {
+	node  JSON
+	steps []pathStep
+}
+
+// pathType is the type of a filter expression or of a function parameter (RFC 9535, section 2.4.1).
+type pathType int
+
+const (
+	valueType   pathType = iota // valueType is a JSON value, or Nothing.
+	logicalType                 // logicalType is a truth value.
+	nodesType                   // nodesType is a list of nodes.
+)
+
+// pathValue is a value of valueType: a node, or Nothing when node is nil.
+type pathValue struct {
+	node JSON
+}
+
+// pathFunction is a function extension usable in filters.
+type pathFunction struct {
+	params []pathType
+	result pathType
+	call   func(args []any) any // call receives a pathValue, bool or []JSON per parameter, and returns a pathValue or bool.
+}
+
+// pathFunctions holds the function extensions defined by RFC 9535.
+var pathFunctions = map[string]pathFunction{
+	"length": {params: []pathType{valueType}, result: valueType, call: pathLength},
+	"count":  {params: []pathType{nodesType}, result: valueType, call: pathCount},
+	"match":  {params: []pathType{valueType, valueType}, result: logicalType, call: pathMatch(true)},
+	"search": {params: []pathType{valueType, valueType}, result: logicalType, call: pathMatch(false)},
+	"value":  {params: []pathType{nodesType}, result: valueType, call: pathValueOf},
+}
+
+// filterExpr is an expression of a filter selector.
+type filterExpr interface{}
+
+type (
+	literalExpr struct{ value JSON }
+	queryExpr   struct {
+		relative bool // relative reports whether the query starts with '@' rather than '$'.
+		segments []pathSegment
+	}
+	functionExpr struct {
+		name string
+		fn   pathFunction
+		args []filterExpr
+	}
+	regexpExpr     struct{ re *regexp.Regexp } // regexpExpr is a literal pattern compiled when parsing, re is nil if it is not valid.
+	comparisonExpr struct {
+		operator    string
+		left, right filterExpr
+	}
+	notExpr   struct{ operand filterExpr }
+	andExpr   struct{ left, right filterExpr }
+	orExpr    struct{ left, right filterExpr }
+	parenExpr struct{ expr filterExpr }
+)
+
+// isSingular reports whether the query selects at most one node: it only has child segments
+// made of a single name or index selector.
+func (q queryExpr) isSingular() bool {
+	for _, segment := range q.segments {
+		if segment.descendant || len(segment.selectors) != 1 {
+			return false
+		}
+		if kind := segment.selectors[0].kind; kind != nameSelector && kind != indexSelector {
+			return false
+		}
+	}
+
+	return true
+}
+
+// pathEvaluator runs a query against a root node.
+type pathEvaluator struct {
+	root JSON
+}
+
+// evalSegments applies the segments in turn to the nodes, the steps leading to
+// the selected nodes are only recorded with withSteps.
+func (ev *pathEvaluator) evalSegments(nodes []pathNode, segments []pathSegment, withSteps bool) []pathNode {
+	for _, segment := range segments {
+		var selected []pathNode
+
+		emit := func(from pathNode) func(JSON, pathStep) {
+			return func(child JSON, step pathStep) {
+				selected = append(selected, pathNode{node: child, steps: appendStep(from.steps, step, withSteps)})
+			}
+		}
+
+		for _, n := range nodes {
+			if segment.descendant {
+				ev.visitDescendants(n, withSteps, func(d pathNode) {
+					ev.applySelectors(d.node, segment.selectors, emit(d))
+				})
+			} else {
+				ev.applySelectors(n.node, segment.selectors, emit(n))
+			}
+		}
+
+		nodes = selected
+	}
+
+	return nodes
+}
+
+// appendStep returns a copy of steps with step appended, or nil without withSteps.
+func appendStep(steps []pathStep, step pathStep, withSteps bool) []pathStep {
+	if !withSteps {
+		return nil
+	}
+
+	return append(steps[:len(steps):len(steps)], step)
+}
+
+// visitDescendants calls visit for n and then for each of its descendants, in document order.
+func (ev *pathEvaluator) visitDescendants(n pathNode, withSteps bool, visit func(pathNode)) {
+	visit(n)
+
+	switch val := n.node.(type) {
+	case *Array:
+		for i, item := range val.Items {
+			ev.visitDescendants(pathNode{node: item, steps: appendStep(n.steps, pathStep{index: i, isIndex: true}, withSteps)}, withSteps, visit)
+		}
+	case *Object:
+		for _, prop := range val.Properties {
+			ev.visitDescendants(pathNode{node: prop.value, steps: appendStep(n.steps, pathStep{name: string(prop.key)}, withSteps)}, withSteps, visit)
+		}
+	}
+}
+
+// applySelectors calls emit for each child of node selected by the selectors, in order.
+func (ev *pathEvaluator) applySelectors(node JSON, selectors []pathSelector, emit func(JSON, pathStep)) {
+	for i := range selectors {
+		ev.applySelector(node, &selectors[i], emit)
+	}
+}
+
+func (ev *pathEvaluator) applySelector(node JSON, selector *pathSelector, emit func(JSON, pathStep)) {
+	switch val := node.(type) {
+	case *Array:
+		switch selector.kind {
+		case wildcardSelector:
+			for i, item := range val.Items {
+				emit(item, pathStep{index: i, isIndex: true})
+			}
+		case indexSelector:
+			index := selector.index
+			if index < 0 {
+				index += val.Len()
+			}
+			if index >= 0 && index < val.Len() {
+				emit(val.Items[index], pathStep{index: index, isIndex: true})
+			}
+		case sliceSelector:
+			for _, i := range sliceIndices(selector.slice, val.Len()) {
+				emit(val.Items[i], pathStep{index: i, isIndex: true})
+			}
+		case filterSelector:
+			for i, item := range val.Items {
+				if ev.evalLogical(selector.filter, item) {
+					emit(item, pathStep{index: i, isIndex: true})
+				}
+			}
+		}
+	case *Object:
+		switch selector.kind {
+		case nameSelector:
+			if index, ok := val.lookup([]byte(selector.name)); ok {
+				emit(val.Properties[index].value, pathStep{name: selector.name})
+			}
+		case wildcardSelector:
+			for _, prop := range val.Properties {
+				emit(prop.value, pathStep{name: string(prop.key)})
+			}
+		case filterSelector:
+			for _, prop := range val.Properties {
+				if ev.evalLogical(selector.filter, prop.value) {
+					emit(prop.value, pathStep{name: string(prop.key)})
+				}
+			}
+		}
+	}
+}
+
+// sliceIndices returns the indices selected by a slice selector from an array of the given length,
+// following RFC 9535, section 2.3.4.2.
+func sliceIndices(slice [3]*int, length int) []int {
+	step := 1
+	if slice[2] != nil {
+		step = *slice[2]
+	}
+	if step == 0 {
+		return nil
+	}
+
+	normalize := func(i int) int {
+		if i < 0 {
+			return length + i
+		}
+		return i
+	}
+
+	start, end := 0, length
+	if step < 0 {
+		start, end = length-1, -length-1
+	}
+	if slice[0] != nil {
+		start = *slice[0]
+	}
+	if slice[1] != nil {
+		end = *slice[1]
+	}
+	start, end = normalize(start), normalize(end)
+
+	var indices []int
+
+	if step > 0 {
+		lower, upper := min(max(start, 0), length), min(max(end, 0), length)
+		for i := lower; i < upper; i += step {
+			indices = append(indices, i)
+		}
+	} else {
+		upper, lower := min(max(start, -1), length-1), min(max(end, -1), length-1)
+		for i := upper; lower < i; i += step {
+			indices = append(indices, i)
+		}
+	}
+
+	return indices
+}
+
+// evalLogical evaluates a logical expression for the current node.
+func (ev *pathEvaluator) evalLogical(expr filterExpr, current JSON) bool {
+	switch val := expr.(type) {
+	case orExpr:
+		return ev.evalLogical(val.left, current) || ev.evalLogical(val.right, current)
+	case andExpr:
+		return ev.evalLogical(val.left, current) && ev.evalLogical(val.right, current)
+	case notExpr:
+		return !ev.evalLogical(val.operand, current)
+	case parenExpr:
+		return ev.evalLogical(val.expr, current)
+	case comparisonExpr:
+		return compareValues(val.operator, ev.evalValue(val.left, current), ev.evalValue(val.right, current))
+	case queryExpr:
+		return len(ev.evalQuery(val, current)) > 0
+	case functionExpr:
+		result := ev.call(val, current)
+		if nodes, ok := result.([]JSON); ok {
+			return len(nodes) > 0
+		}
+		logical, _ := result.(bool)
+		return logical
+	default:
+		return false
+	}
+}
+
+// evalValue evaluates an operand of valueType for the current node.
+func (ev *pathEvaluator) evalValue(expr filterExpr, current JSON) pathValue {
+	switch val := expr.(type) {
+	case literalExpr:
+		return pathValue{val.value}
+	case queryExpr:
+		if nodes := ev.evalQuery(val, current); len(nodes) == 1 {
+			return pathValue{nodes[0]}
+		}
+		return pathValue{}
+	case functionExpr:
+		value, _ := ev.call(val, current).(pathValue)
+		return value
+	default:
+		return pathValue{}
+	}
+}
+
+// evalQuery returns the nodes selected by a query, from the current node or from the root.
+func (ev *pathEvaluator) evalQuery(q queryExpr, current JSON) []JSON {
+	start := ev.root
+	if q.relative {
+		start = current
+	}
+
+	found := ev.evalSegments([]pathNode{{node: start}}, q.segments, false)
+
+	nodes := make([]JSON, len(found))
+	for i, n := range found {
+		nodes[i] = n.node
+	}
+
+	return nodes
+}
+
+// call evaluates the arguments of a function according to its parameter types, then calls it.
+func (ev *pathEvaluator) call(f functionExpr, current JSON) any {
+	args := make([]any, len(f.args))
+
+	for i, arg := range f.args {
+		if re, ok := arg.(regexpExpr); ok {
+			args[i] = re
+			continue
+		}
+
+		switch f.fn.params[i] {
+		case valueType:
+			args[i] = ev.evalValue(arg, current)
+		case logicalType:
+			args[i] = ev.evalLogical(arg, current)
+		default:
+			if q, ok := arg.(queryExpr); ok {
+				args[i] = ev.evalQuery(q, current)
+			} else {
+				args[i], _ = ev.call(arg.(functionExpr), current).([]JSON)
+			}
+		}
+	}
+
+	return f.fn.call(args)
+}
+
+// compareValues applies a comparison operator to two values (RFC 9535, section 2.3.5.2.2).
+func compareValues(operator string, left, right pathValue) bool {
+	switch operator {
+	case "==":
+		return equalValues(left, right)
+	case "!=":
+		return !equalValues(left, right)
+	case "<":
+		return lessValue(left, right)
+	case "<=":
+		return lessValue(left, right) || equalValues(left, right)
+	case ">":
+		return lessValue(right, left)
+	case ">=":
+		return lessValue(right, left) || equalValues(left, right)
+	default:
+		return false
+	}
+}
+
+// equalValues reports whether two values are equal, Nothing being only equal to itself.
+func equalValues(left, right pathValue) bool {
+	if left.node == nil || right.node == nil {
+		return left.node == nil && right.node == nil
+	}
+
+	return equalNodes(left.node, right.node)
+}
+
+// equalNodes reports whether two nodes hold the same JSON value: numbers are compared by
+// value, strings once unescaped and objects regardless of the order of their members.
+func equalNodes(a, b JSON) bool {
+	switch left := a.(type) {
+	case *Null:
+		_, ok := b.(*Null)
+		return ok
+	case *Boolean:
+		right, ok := b.(*Boolean)
+		if !ok {
+			return false
+		}
+		leftVal, err1 := left.Value()
+		rightVal, err2 := right.Value()
+		return err1 == nil && err2 == nil && leftVal == rightVal
+	case *String:
+		right, ok := b.(*String)
+		if !ok {
+			return false
+		}
+		leftVal, err1 := left.Value()
+		rightVal, err2 := right.Value()
+		return err1 == nil && err2 == nil && leftVal == rightVal
+	case *Number:
+		right, ok := b.(*Number)
+		if !ok {
+			return false
+		}
+		c, ok := compareNumbers(left, right)
+		return ok && c == 0
+	case *Array:
+		right, ok := b.(*Array)
+		if !ok || left.Len() != right.Len() {
+			return false
+		}
+		for i, item := range left.Items {
+			if !equalNodes(item, right.Items[i]) {
+				return false
+			}
+		}
+		return true
+	case *Object:
+		right, ok := b.(*Object)
+		if !ok || left.Len() != right.Len() {
+			return false
+		}
+		for _, prop := range left.Properties {
+			index, ok := right.lookup(prop.key)
+			if !ok || !equalNodes(prop.value, right.Properties[index].value) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// lessValue reports whether left is less than right, which only holds for two numbers or two strings.
+func lessValue(left, right pathValue) bool {
+	switch leftNode := left.node.(type) {
+	case *Number:
+		rightNode, ok := right.node.(*Number)
+		if !ok {
+			return false
+		}
+		c, ok := compareNumbers(leftNode, rightNode)
+		return ok && c < 0
+	case *String:
+		rightNode, ok := right.node.(*String)
+		if !ok {
+			return false
+		}
+		leftVal, err1 := leftNode.Value()
+		rightVal, err2 := rightNode.Value()
+		// UTF-8 strings compare byte-wise in the order of their Unicode scalar values
+		return err1 == nil && err2 == nil && leftVal < rightVal
+	default:
+		return false
+	}
+}
+
+// compareNumbers compares two numbers exactly, it reports false if either is NaN or not a number.
+func compareNumbers(a, b *Number) (int, bool) {
+	if !isNonFinite(a) && !isNonFinite(b) {
+		ratA, errA := a.bigRat()
+		ratB, errB := b.bigRat()
+		if errA == nil && errB == nil {
+			return ratA.Cmp(ratB), true
+		}
+	}
+
+	floatA, errA := a.Value()
+	floatB, errB := b.Value()
+	if errA != nil || errB != nil || math.IsNaN(floatA) || math.IsNaN(floatB) {
+		return 0, false
+	}
+
+	return cmp.Compare(floatA, floatB), true
+}
+
+// pathLength implements length(): the number of characters of a string, or of items or members of a container.
+func pathLength(args []any) any {
+	switch val := args[0].(pathValue).node.(type) {
+	case *String:
+		strVal, err := val.Value()
+		if err != nil {
+			return pathValue{}
+		}
+		return pathValue{marshalNumber(strconv.Itoa(utf8.RuneCountInString(strVal)), INTEGER)}
+	case *Array:
+		return pathValue{marshalNumber(strconv.Itoa(val.Len()), INTEGER)}
+	case *Object:
+		return pathValue{marshalNumber(strconv.Itoa(val.Len()), INTEGER)}
+	default:
+		return pathValue{}
+	}
+}
+
+// pathCount implements count(): the number of nodes in a node list.
+func pathCount(args []any) any {
+	nodes, _ := args[0].([]JSON)
+	return pathValue{marshalNumber(strconv.Itoa(len(nodes)), INTEGER)}
+}
+
+// pathValueOf implements value(): the node of a node list holding a single one, Nothing otherwise.
+func pathValueOf(args []any) any {
+	if nodes, _ := args[0].([]JSON); len(nodes) == 1 {
+		return pathValue{nodes[0]}
+	}
+
+	return pathValue{}
+}
+
+// pathMatch implements match(), which matches the whole string, and search(), which matches a substring.
+// Both are false unless given a string and a valid I-Regexp (RFC 9485). A pattern read from the document
+// is compiled on every call, while a literal one is compiled once, when parsing the query.
+func pathMatch(whole bool) func(args []any) any {
+	return func(args []any) any {
+		str, ok := args[0].(pathValue).node.(*String)
+		if !ok {
+			return false
+		}
+
+		strVal, err := str.Value()
+		if err != nil {
+			return false
+		}
+
+		var re *regexp.Regexp
+
+		switch pattern := args[1].(type) {
+		case regexpExpr:
+			re = pattern.re
+		case pathValue:
+			patternStr, ok := pattern.node.(*String)
+			if !ok {
+				return false
+			}
+			patternVal, err := patternStr.Value()
+			if err != nil {
+				return false
+			}
+			re, _ = compileIRegexp(patternVal, whole)
+		}
+
+		return re != nil && re.MatchString(strVal)
+	}
+}
+
+// compileIRegexp translates an I-Regexp (RFC 9485) into a Go regular expression, anchored at both
+// ends with whole. It reports false if the pattern is not valid, the syntax Go adds to I-Regexp,
+// like Perl classes (\d), flags ((?i)) or lazy quantifiers (*?), included. As in I-Regexp, '^' and '$'
+// match themselves, and '.' matches any character but line feeds and carriage returns.
+func compileIRegexp(pattern string, whole bool) (*regexp.Regexp, bool) {
+	var builder strings.Builder
+	// quantifiable reports whether the last piece may be followed by a quantifier
+	inClass, classStart, quantifiable := false, false, false
+
+	for i := 0; i < len(pattern); {
+		char, size := utf8.DecodeRuneInString(pattern[i:])
+		if char == utf8.RuneError && size == 1 {
+			return nil, false
+		}
+		i += size
+
+		if char == '\\' {
+			escape, ok := iregexpEscape(pattern[i:])
+			if !ok {
+				return nil, false
+			}
+			builder.WriteString(`\` + escape)
+			i += len(escape)
+			classStart, quantifiable = false, !inClass
+			continue
+		}
+
+		if inClass {
+			switch {
+			case char == ']' && !classStart:
+				inClass, quantifiable = false, true
+			case char == '[' || char == ']':
+				return nil, false
+			}
+			classStart = false
+			builder.WriteRune(char)
+			continue
+		}
+
+		switch char {
+		case '[':
+			inClass, classStart = true, true
+			if strings.HasPrefix(pattern[i:], "^") {
+				builder.WriteString("[^")
+				i++
+				continue
+			}
+		case '(':
+			if strings.HasPrefix(pattern[i:], "?") {
+				return nil, false
+			}
+			quantifiable = false
+		case ')', '|':
+			quantifiable = char == ')'
+		case '?', '*', '+', '{':
+			if !quantifiable {
+				return nil, false
+			}
+			if char == '{' {
+				end := strings.IndexByte(pattern[i:], '}')
+				if end < 0 || !isQuantRange(pattern[i:i+end]) {
+					return nil, false
+				}
+				builder.WriteString(pattern[i-1 : i+end+1])
+				i += end + 1
+				quantifiable = false
+				continue
+			}
+			quantifiable = false
+		case '}', ']':
+			return nil, false
+		case '.':
+			builder.WriteString(`[^\n\r]`)
+			quantifiable = true
+			continue
+		case '^', '$':
+			builder.WriteString(`\` + string(char))
+			quantifiable = true
+			continue
+		default:
+			quantifiable = true
+		}
+
+		builder.WriteRune(char)
+	}
+
+	if inClass {
+		return nil, false
+	}
+
+	expr := "(?:" + builder.String() + ")"
+	if whole {
+		expr = `\A` + expr + `\z`
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, false
+	}
+
+	return re, true
+}
+
+// iregexpEscape returns the escape following a backslash at the start of rest, without the backslash,
+// if it is allowed in I-Regexp: a single character escape or a character category (\p{...}, \P{...}).
+func iregexpEscape(rest string) (string, bool) {
+	if rest == "" {
+		return "", false
+	}
+
+	switch rest[0] {
+	case '(', ')', '*', '+', '-', '.', '?', '[', '\\', ']', '^', '{', '|', '}', 'n', 'r', 't':
+		return rest[:1], true
+	case 'p', 'P':
+		end := strings.IndexByte(rest, '}')
+		if len(rest) < 2 || rest[1] != '{' || end < 3 {
+			return "", false
+		}
+		return rest[:end+1], true
+	default:
+		return "", false
+	}
+}
+
+// isQuantRange reports whether str, found between braces, is a quantifier range: {n}, {n,} or {n,m}.
+func isQuantRange(str string) bool {
+	low, high, hasComma := strings.Cut(str, ",")
+
+	isDigits := func(s string) bool {
+		for _, c := range s {
+			if c < '0' || c > '9' {
+				return false
+			}
+		}
+		return s != ""
+	}
+
+	return isDigits(low) && (!hasComma || high == "" || isDigits(high))
+}
@@ -0,0 +1,259 @@
+package jsonvx
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const jsonPathStore = `{
+  "store": {
+    "book": [
+      {"category": "reference", "author": "Nigel Rees", "title": "Sayings of the Century", "price": 8.95},
+      {"category": "fiction", "author": "Evelyn Waugh", "title": "Sword of Honour", "price": 12.99},
+      {"category": "fiction", "author": "Herman Melville", "title": "Moby Dick", "isbn": "0-553-21311-3", "price": 8.99},
+      {"category": "fiction", "author": "J. R. R. Tolkien", "title": "The Lord of the Rings", "isbn": "0-395-19395-8", "price": 22.99}
+    ],
+    "bicycle": {"color": "red", "price": 399}
+  }
+}`
+
+func TestJSONPath(t *testing.T) {
+	var tests = []struct {
+		msg           string
+		input         string
+		query         string
+		expected      []string
+		expectedPaths []string
+	}{
+		{msg: "Query root", input: `{"a": 1}`, query: `$`, expected: []string{`{"a":1}`}, expectedPaths: []string{`$`}},
+		{msg: "Query authors", input: jsonPathStore, query: `$.store.book[*].author`, expected: []string{`"Nigel Rees"`, `"Evelyn Waugh"`, `"Herman Melville"`, `"J. R. R. Tolkien"`}},
+		{msg: "Query all authors", input: jsonPathStore, query: `$..author`, expected: []string{`"Nigel Rees"`, `"Evelyn Waugh"`, `"Herman Melville"`, `"J. R. R. Tolkien"`}},
+		{msg: "Query store members", input: jsonPathStore, query: `$.store.*`, expectedPaths: []string{`$['store']['book']`, `$['store']['bicycle']`}},
+		{msg: "Query all prices", input: jsonPathStore, query: `$.store..price`, expected: []string{`8.95`, `12.99`, `8.99`, `22.99`, `399`}},
+		{msg: "Query third book", input: jsonPathStore, query: `$..book[2].author`, expected: []string{`"Herman Melville"`}, expectedPaths: []string{`$['store']['book'][2]['author']`}},
+		{msg: "Query missing member", input: jsonPathStore, query: `$..book[2].publisher`, expected: []string{}},
+		{msg: "Query last book", input: jsonPathStore, query: `$..book[-1].title`, expected: []string{`"The Lord of the Rings"`}},
+		{msg: "Query first two books", input: jsonPathStore, query: `$..book[0,1].title`, expected: []string{`"Sayings of the Century"`, `"Sword of Honour"`}},
+		{msg: "Query books with an isbn", input: jsonPathStore, query: `$..book[?@.isbn].title`, expected: []string{`"Moby Dick"`, `"The Lord of the Rings"`}},
+		{msg: "Query cheap books", input: jsonPathStore, query: `$..book[?@.price<10].title`, expected: []string{`"Sayings of the Century"`, `"Moby Dick"`}},
+		{msg: "Query every node", input: `{"a": [1, {"b": 2}]}`, query: `$..*`, expectedPaths: []string{`$['a']`, `$['a'][0]`, `$['a'][1]`, `$['a'][1]['b']`}},
+		{msg: "Query bracketed names", input: `{"a b": 1, "'": 2}`, query: `$['a b', "'"]`, expected: []string{`1`, `2`}, expectedPaths: []string{`$['a b']`, `$['\'']`}},
+		{msg: "Query escaped name", input: "{\"\u263a\\n\": 1}", query: "$['\u263a\\n']", expected: []string{`1`}, expectedPaths: []string{"$['\u263a\\n']"}},
+		{msg: "Query surrogate pair", input: "{\"\U0001d11e\": 1}", query: `$["\uD834\uDD1E"]`, expected: []string{`1`}},
+		{msg: "Query same node twice", input: `[1, 2]`, query: `$[0, 0]`, expected: []string{`1`, `1`}},
+		{msg: "Query out of range index", input: `[1, 2]`, query: `$[2, -3]`, expected: []string{}},
+		{msg: "Query index of object", input: `{"0": 1}`, query: `$[0]`, expected: []string{}},
+		{msg: "Query slice", input: `[0, 1, 2, 3, 4, 5, 6]`, query: `$[1:5:2]`, expected: []string{`1`, `3`}, expectedPaths: []string{`$[1]`, `$[3]`}},
+		{msg: "Query slice defaults", input: `[0, 1, 2, 3]`, query: `$[:2]`, expected: []string{`0`, `1`}},
+		{msg: "Query negative slice", input: `[0, 1, 2, 3]`, query: `$[-2:]`, expected: []string{`2`, `3`}},
+		{msg: "Query reversed slice", input: `[0, 1, 2, 3]`, query: `$[::-1]`, expected: []string{`3`, `2`, `1`, `0`}},
+		{msg: "Query reversed slice with bounds", input: `[0, 1, 2, 3, 4, 5, 6]`, query: `$[5:1:-2]`, expected: []string{`5`, `3`}},
+		{msg: "Query slice with zero step", input: `[0, 1]`, query: `$[::0]`, expected: []string{}},
+		{msg: "Query slice out of bounds", input: `[0, 1]`, query: `$[-10:10]`, expected: []string{`0`, `1`}},
+		{msg: "Query descendant wildcard", input: `{"o": {"j": 1, "k": 2}, "a": [5, 3]}`, query: `$..[*]`, expectedPaths: []string{`$['o']`, `$['a']`, `$['o']['j']`, `$['o']['k']`, `$['a'][0]`, `$['a'][1]`}},
+		{msg: "Query descendant index", input: `{"o": [{"a": [7]}], "a": [5]}`, query: `$..[0]`, expected: []string{`{"a":[7]}`, `7`, `5`}},
+		{msg: "Query blank space", input: `{"a": [1, 2]}`, query: "$ .a [ 0 , 1 ]", expected: []string{`1`, `2`}},
+		{msg: "Query filter on object", input: `{"a": 1, "b": 2, "c": 3}`, query: `$[?@ > 1]`, expected: []string{`2`, `3`}},
+		{msg: "Query filter with root", input: `{"min": 2, "items": [1, 2, 3]}`, query: `$.items[?@ >= $.min]`, expected: []string{`2`, `3`}},
+		{msg: "Query filter with logical operators", input: `[1, 2, 3, 4]`, query: `$[?@ > 1 && (@ < 3 || @ == 4)]`, expected: []string{`2`, `4`}},
+		{msg: "Query filter with negation", input: `[{"a": 1}, {"b": 2}]`, query: `$[?!@.a]`, expected: []string{`{"b":2}`}},
+		{msg: "Query filter with equal numbers", input: `[1, 1.0, 10e-1, 0x1, 2]`, query: `$[?@ == 1]`, expected: []string{`1`, `1.0`, `10e-1`, `0x1`}},
+		{msg: "Query filter with strings", input: `["a", "b", "ab", 1]`, query: `$[?@ < 'b']`, expected: []string{`"a"`, `"ab"`}},
+		{msg: "Query filter with null and booleans", input: `[null, false, true, 0]`, query: `$[?@ == null || @ == true]`, expected: []string{`null`, `true`}},
+		{msg: "Query filter with structured values", input: `[{"a": [1, {"b": 2}]}, {"a": [1]}]`, query: `$[?@.a == $[0].a]`, expected: []string{`{"a":[1,{"b":2}]}`}},
+		{msg: "Query filter with object comparison", input: `[{"a": 1, "b": 2}, {"b": 2, "a": 1.0}, {"a": 1}]`, query: `$[?@ == $[0]]`, expected: []string{`{"a":1,"b":2}`, `{"b":2,"a":1.0}`}},
+		{msg: "Query filter with missing members", input: `[{"a": 1}, {}]`, query: `$[?@.b == @.c]`, expected: []string{`{"a":1}`, `{}`}},
+		{msg: "Query filter with nothing not less", input: `[{"a": 1}, {}]`, query: `$[?@.b <= @.c]`, expected: []string{`{"a":1}`, `{}`}},
+		{msg: "Query filter with mismatched types", input: `[1, "1"]`, query: `$[?@ < 2 || @ > '0']`, expected: []string{`1`, `"1"`}},
+		{msg: "Query filter with exponent", input: `[100, 10]`, query: `$[?@ == 1e2]`, expected: []string{`100`}},
+		{msg: "Query nested filter", input: `[[1, 5], [2]]`, query: `$[?@[?@ > 4]]`, expected: []string{`[1,5]`}},
+		{msg: "Query length", input: "[\"ab\", \"\u00e9t\u00e9\", [1], {\"a\": 1, \"b\": 2}, 3]", query: `$[?length(@) == 2]`, expected: []string{`"ab"`, `{"a":1,"b":2}`}},
+		{msg: "Query count", input: `[{"a": 1, "b": 2}, {"a": 1}]`, query: `$[?count(@.*) > 1]`, expected: []string{`{"a":1,"b":2}`}},
+		{msg: "Query match", input: `["1974-05-01", "1974-05-011", "x1974-05-01"]`, query: `$[?match(@, '1974-05-..')]`, expected: []string{`"1974-05-01"`}},
+		{msg: "Query search", input: `["Bob", "Robert", "Alice", 1]`, query: `$[?search(@, '[BR]o')]`, expected: []string{`"Bob"`, `"Robert"`}},
+		{msg: "Query match dot", input: `["a\nb", "axb"]`, query: `$[?match(@, 'a.b')]`, expected: []string{`"axb"`}},
+		{msg: "Query invalid regexp", input: `["a"]`, query: `$[?match(@, '(')]`, expected: []string{}},
+		{msg: "Query regexp from the document", input: `[{"s": "ab", "p": "a."}, {"s": "ab", "p": "b"}, {"s": "ab", "p": "("}]`, query: `$[?match(@.s, @.p)]`, expected: []string{`{"s":"ab","p":"a."}`}},
+		{msg: "Query regexp anchors literally", input: `["^a$", "a"]`, query: `$[?search(@, '^a$')]`, expected: []string{`"^a$"`}},
+		{msg: "Query regexp with Go syntax", input: `["A", "1"]`, query: `$[?match(@, '(?i)a') || match(@, '\\d')]`, expected: []string{}},
+		{msg: "Query value", input: `[{"a": [3]}, {"a": [3, 4]}]`, query: `$[?value(@.a[*]) == 3]`, expected: []string{`{"a":[3]}`}},
+		{msg: "Query nested functions", input: `[{"a": "xy"}, {"a": "x"}]`, query: `$[?length(value(@.a)) == 2].a`, expected: []string{`"xy"`}},
+		{msg: "Query function on missing value", input: `[{"a": 1}, {}]`, query: `$[?length(@.b) == @.c]`, expected: []string{`{"a":1}`, `{}`}},
+	}
+
+	// numbers are written as they are in the input
+	encoderCfg := NewEncoderConfig(WithDialect(NewParserConfig(WithAllowHexNumbers(true))))
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			parser := NewParser([]byte(test.input), JSON5Config())
+			node, err := parser.Parse()
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+
+			matches, err := QueryJSONPath(node, test.query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if test.expected != nil {
+				got := []string{}
+				for _, match := range matches {
+					data, err := Encode(match.Node, encoderCfg)
+					if err != nil {
+						t.Fatalf("unexpected encode error: %v", err)
+					}
+					got = append(got, string(data))
+				}
+
+				if !reflect.DeepEqual(got, test.expected) {
+					t.Errorf("got %q, expected %q", got, test.expected)
+				}
+			}
+
+			if test.expectedPaths != nil {
+				got := []string{}
+				for _, match := range matches {
+					got = append(got, match.Path)
+				}
+
+				if !reflect.DeepEqual(got, test.expectedPaths) {
+					t.Errorf("got paths %q, expected %q", got, test.expectedPaths)
+				}
+			}
+		})
+	}
+}
+
+func TestJSONPathInvalid(t *testing.T) {
+	var tests = []struct {
+		msg   string
+		query string
+	}{
+		{msg: "Empty query", query: ``},
+		{msg: "Missing root", query: `a.b`},
+		{msg: "Relative query", query: `@.a`},
+		{msg: "Trailing blank space", query: `$ `},
+		{msg: "Leading blank space", query: ` $`},
+		{msg: "Trailing dot", query: `$.`},
+		{msg: "Triple dot", query: `$...a`},
+		{msg: "Unclosed bracket", query: `$[0`},
+		{msg: "Empty brackets", query: `$[]`},
+		{msg: "Leading zero", query: `$[01]`},
+		{msg: "Negative zero", query: `$[-0]`},
+		{msg: "Index too large", query: `$[9007199254740992]`},
+		{msg: "Member name starting with a digit", query: `$.1a`},
+		{msg: "Unterminated string", query: `$['a]`},
+		{msg: "Control character in string", query: "$['\n']"},
+		{msg: "Invalid escape", query: `$['\x']`},
+		{msg: "Lone surrogate", query: `$['\uD800']`},
+		{msg: "Double quote escaped in single quotes", query: `$['\"']`},
+		{msg: "Empty filter", query: `$[?]`},
+		{msg: "Literal as test", query: `$[?1]`},
+		{msg: "Comparison of non-singular query", query: `$[?@.* == 1]`},
+		{msg: "Comparison of logical expressions", query: `$[?(@.a) == 1]`},
+		{msg: "Chained comparison", query: `$[?@.a == 1 == 2]`},
+		{msg: "Value function as test", query: `$[?length(@)]`},
+		{msg: "Non-singular query as value argument", query: `$[?length(@.*) < 3]`},
+		{msg: "Literal as nodes argument", query: `$[?count(1) == 1]`},
+		{msg: "Logical function compared", query: `$[?match(@, 'a') == true]`},
+		{msg: "Unknown function", query: `$[?foo(@)]`},
+		{msg: "Wrong argument count", query: `$[?length(@, @) == 1]`},
+		{msg: "Blank space before parenthesis", query: `$[?length (@) == 1]`},
+		{msg: "Uppercase literal", query: `$[?@ == True]`},
+		{msg: "Number with leading zero", query: `$[?@ == 01]`},
+		{msg: "Single equal sign", query: `$[?@ = 1]`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			jp, err := CompileJSONPath(test.query)
+
+			if !errors.Is(err, ErrInvalidJSONPath) {
+				t.Errorf("got (%v, %v), expected %v", jp, err, ErrInvalidJSONPath)
+			}
+		})
+	}
+}
+
+func TestJSONPathReuse(t *testing.T) {
+	jp, err := CompileJSONPath(`$[?@.price > 10].title`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := jp.String(); got != `$[?@.price > 10].title` {
+		t.Errorf("got %s, expected the source query", got)
+	}
+
+	for input, expected := range map[string]int{
+		`[{"price": 11, "title": "a"}, {"price": 9, "title": "b"}]`:  1,
+		`[{"price": 20, "title": "a"}, {"price": 30, "title": "b"}]`: 2,
+		`{"price": 20, "title": "a"}`:                                0,
+	} {
+		parser := NewParser([]byte(input), nil)
+		node, err := parser.Parse()
+		if err != nil {
+			t.Fatalf("unexpected parse error: %v", err)
+		}
+
+		if got := len(jp.Query(node)); got != expected {
+			t.Errorf("got %d matches in %s, expected %d", got, input, expected)
+		}
+	}
+}
+
+func TestCompileIRegexp(t *testing.T) {
+	var tests = []struct {
+		msg      string
+		pattern  string
+		input    string
+		valid    bool
+		expected bool
+	}{
+		{msg: "Compile literal", pattern: "abc", input: "abc", valid: true, expected: true},
+		{msg: "Compile alternation", pattern: "a|b(c|d)", input: "bd", valid: true, expected: true},
+		{msg: "Compile quantifiers", pattern: "a?b*c+d{2}e{1,}f{1,2}", input: "bccddeeff", valid: true, expected: true},
+		{msg: "Compile class", pattern: "[a-c^]+", input: "ab^c", valid: true, expected: true},
+		{msg: "Compile negated class", pattern: "[^a-c]", input: "d", valid: true, expected: true},
+		{msg: "Compile escapes", pattern: `\.\[\n\t`, input: ".[\n\t", valid: true, expected: true},
+		{msg: "Compile category", pattern: `\p{Lu}\P{Lu}`, input: "Ab", valid: true, expected: true},
+		{msg: "Compile dot", pattern: "a.b", input: "a\rb", valid: true, expected: false},
+		{msg: "Compile caret", pattern: "^a", input: "^a", valid: true, expected: true},
+		{msg: "Compile dollar", pattern: "a$", input: "a", valid: true, expected: false},
+		{msg: "Compile empty group", pattern: "a()", input: "a", valid: true, expected: true},
+		{msg: "Compile flags", pattern: "(?i)a"},
+		{msg: "Compile non-capturing group", pattern: "(?:a)"},
+		{msg: "Compile lazy quantifier", pattern: "a*?"},
+		{msg: "Compile leading quantifier", pattern: "*a"},
+		{msg: "Compile quantifier after alternation", pattern: "a|+"},
+		{msg: "Compile bad range", pattern: "a{,2}"},
+		{msg: "Compile unclosed range", pattern: "a{2"},
+		{msg: "Compile lone brace", pattern: "a}"},
+		{msg: "Compile lone bracket", pattern: "a]"},
+		{msg: "Compile Perl class", pattern: `\d`},
+		{msg: "Compile assertion", pattern: `\bab`},
+		{msg: "Compile category without braces", pattern: `\pL`},
+		{msg: "Compile POSIX class", pattern: "[[:alpha:]]"},
+		{msg: "Compile empty class", pattern: "[]a]"},
+		{msg: "Compile unclosed class", pattern: "[a"},
+		{msg: "Compile trailing backslash", pattern: `a\`},
+		{msg: "Compile invalid UTF-8", pattern: "a\xff"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			re, valid := compileIRegexp(test.pattern, true)
+
+			if valid != test.valid {
+				t.Fatalf("got valid %v, expected %v", valid, test.valid)
+			}
+
+			if valid && re.MatchString(test.input) != test.expected {
+				t.Errorf("got match %v, expected %v", !test.expected, test.expected)
+			}
+		})
+	}
+}
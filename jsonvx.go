@@ -52,7 +52,10 @@ var (
 	ErrNotBoolean = errors.New("value is not a JSON boolean")
 	ErrNotNull    = errors.New("value is not null")
 
+	ErrInvalidEscape = errors.New("invalid escape sequence in JSON string")
+
 	ErrInvalidQueryKey   = errors.New("invalid query key")
+	ErrKeyNotFound       = errors.New("key not found")
 	ErrExpectedIndex     = errors.New("invalid query key, expected integer index")
 	ErrIndexOutOfRange   = errors.New("index out of range")
 	ErrEmptyArray        = errors.New("array is empty")
@@ -64,11 +67,14 @@ var (
 type JSON interface {
 	fmt.Stringer
 	Equal(JSON) bool
+	Span() Span
+	Decode(v any) error
 }
 
 // Null represents a JSON null value.
 type Null struct {
 	Token *Token
+	Trivia
 }
 
 // newNull creates a new *Null value, optionally invoking a callback.
@@ -116,6 +122,7 @@ func AsNull(j JSON) (*Null, bool) {
 // Boolean represents a JSON boolean (true or false).
 type Boolean struct {
 	Token *Token
+	Trivia
 }
 
 // newBoolean creates a new *Boolean value, optionally invoking a callback
@@ -179,6 +186,9 @@ func AsBoolean(j JSON) (*Boolean, bool) {
 // String represents a JSON string.
 type String struct {
 	Token *Token
+	Trivia
+
+	config *ParserConfig // config is the configuration the string was parsed with, if any.
 }
 
 // newString creates a new *String value, optionally invoking a callback
@@ -191,28 +201,39 @@ func newString(Token *Token, cb func()) *String {
 }
 
 func (s *String) String() string {
-	return (s.Token.Value()).(string)
+	val, _ := s.Value()
+	return val
 }
 
-// Value returns the string or an error if invalid.
+// Value returns the decoded string or an error if invalid.
+// Escape sequences are decoded, so "a\nb" yields a string containing a real newline.
 func (s *String) Value() (string, error) {
-	if s.Token == nil {
+	if s.Token == nil || s.Token.Kind != STRING {
 		return "", ErrNotString
 	}
 
-	val := s.Token.Value()
-
-	if val == nil {
+	switch s.Token.SubKind {
+	case SINGLE_QUOTED, DOUBLE_QUOTED:
+		strVal, err := unquoteValue(s.Token.Literal)
+		if err != nil {
+			return "", fmt.Errorf("%w: %s", err, s.Token.Literal)
+		}
+		return strVal, nil
+	case IDENT:
+		return string(s.Token.Literal), nil
+	default:
 		return "", ErrNotString
 	}
+}
 
-	strVal, ok := val.(string)
-
-	if !ok {
-		return "", ErrNotString
+// RawValue returns the string exactly as it appears in the source, including
+// its quotes and any undecoded escape sequences.
+func (s *String) RawValue() string {
+	if s.Token == nil {
+		return ""
 	}
 
-	return strVal, nil
+	return string(s.Token.Literal)
 }
 
 func (s *String) Equal(s2 JSON) bool {
@@ -238,6 +259,7 @@ func AsString(j JSON) (*String, bool) {
 // Number represents a JSON number (integer, float, hex, etc.).
 type Number struct {
 	Token *Token
+	Trivia
 }
 
 // newNumber creates a new *Number value, optionally invoking a callback
@@ -254,19 +276,20 @@ func (n *Number) String() string {
 }
 
 // Value attempts to parse the number as float64, depending on its subtype.
+// Hexadecimal, leading plus and point edge literals (e.g., `0xFF`, `+42`, `.5` and `5.`) are all supported.
 func (n *Number) Value() (float64, error) {
 	if n.Token == nil {
 		return 0, ErrNotNumber
 	}
 
 	switch n.Token.SubKind {
-	case INTEGER, HEX:
-		numVal, err := ToInt(n.Token.Literal)
-		if err != nil {
+	case HEX:
+		numVal, ok := hexToFloat(n.Token.Literal)
+		if !ok {
 			return 0, ErrNotNumber
 		}
-		return float64(numVal), nil
-	case FLOAT, SCI_NOT:
+		return numVal, nil
+	case INTEGER, FLOAT, SCI_NOT:
 		numVal, err := ToFloat(n.Token.Literal)
 		if err != nil {
 			return 0, ErrNotNumber
@@ -317,6 +340,12 @@ func AsNumber(j JSON) (*Number, bool) {
 // Array represents a JSON array.
 type Array struct {
 	Items []JSON
+	Trivia
+
+	openToken     *Token // openToken is the opening bracket the array was parsed from, if any.
+	closeToken    *Token // closeToken is the closing bracket the array was parsed from, if any.
+	closing       Tokens // closing holds the trivia before the closing bracket that no item holds.
+	trailingComma bool   // trailingComma reports whether the last item is followed by a comma.
 }
 
 // newArray creates a new *Array value, optionally invoking a callback
@@ -437,6 +466,9 @@ func AsArray(j JSON) (*Array, bool) {
 type KeyValue struct {
 	key   []byte
 	value JSON
+
+	keyToken  *Token // keyToken is the token the key was parsed from, if any.
+	keyTrivia Trivia // keyTrivia holds the trivia surrounding the key, up to the colon.
 }
 
 func newKeyValue(key []byte, value JSON) KeyValue {
@@ -456,8 +488,27 @@ func (kv *KeyValue) Equal(kv2 *KeyValue) bool {
 }
 
 // Object represents a JSON object.
+//
+// Properties are kept in source order, while a separate index sorted by key
+// provides log(n) lookups for QueryPath. The index is built when the object is
+// created and kept up to date by Set. A key missing from the index is reported
+// missing, so the keys of Properties must not be changed in place, nor properties
+// added or removed but through Set; reordering Properties only costs a linear scan.
 type Object struct {
 	Properties []KeyValue
+	Trivia
+
+	index         []indexEntry // index holds the keys of Properties with their positions, stably sorted by key.
+	openToken     *Token       // openToken is the opening brace the object was parsed from, if any.
+	closeToken    *Token       // closeToken is the closing brace the object was parsed from, if any.
+	closing       Tokens       // closing holds the trivia before the closing brace that no property holds.
+	trailingComma bool         // trailingComma reports whether the last property is followed by a comma.
+}
+
+// indexEntry is the key of a property as it was when the index was built, along with its position.
+type indexEntry struct {
+	key []byte
+	pos int
 }
 
 // newObject creates a new *Object value, optionally invoking a callback
@@ -466,7 +517,88 @@ func newObject(properties []KeyValue, cb func()) *Object {
 		cb()
 	}
 
-	return &Object{Properties: properties}
+	obj := &Object{Properties: properties}
+	obj.reindex()
+
+	return obj
+}
+
+// reindex rebuilds the sorted key index from Properties.
+func (o *Object) reindex() {
+	o.index = make([]indexEntry, len(o.Properties))
+	for i, prop := range o.Properties {
+		o.index[i] = indexEntry{key: prop.key, pos: i}
+	}
+
+	sort.SliceStable(o.index, func(i, j int) bool {
+		return bytes.Compare(o.index[i].key, o.index[j].key) < 0
+	})
+}
+
+// indexed reports whether the index matches Properties, which takes a linear scan.
+func (o *Object) indexed() bool {
+	if len(o.index) != len(o.Properties) {
+		return false
+	}
+
+	for _, entry := range o.index {
+		if !bytes.Equal(o.Properties[entry.pos].key, entry.key) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sortedIndex returns the positions of Properties stably sorted by key, taken from the index
+// when it matches Properties, and sorted afresh otherwise. The index is never rebuilt here,
+// so that concurrent reads of an object do not write to it.
+func (o *Object) sortedIndex() []int {
+	positions := make([]int, len(o.Properties))
+
+	if o.indexed() {
+		for i, entry := range o.index {
+			positions[i] = entry.pos
+		}
+		return positions
+	}
+
+	for i := range positions {
+		positions[i] = i
+	}
+
+	sort.SliceStable(positions, func(i, j int) bool {
+		return bytes.Compare(o.Properties[positions[i]].key, o.Properties[positions[j]].key) < 0
+	})
+
+	return positions
+}
+
+// lookup returns the position in Properties of the first property with the given key.
+// The index answers on its own, unless it was not built for the current Properties,
+// or the properties have been reordered since, in which case they are scanned linearly.
+func (o *Object) lookup(key []byte) (int, bool) {
+	if len(o.index) == len(o.Properties) {
+		i := sort.Search(len(o.index), func(i int) bool {
+			return bytes.Compare(o.index[i].key, key) >= 0
+		})
+
+		if i >= len(o.index) || !bytes.Equal(o.index[i].key, key) {
+			return 0, false
+		}
+
+		if pos := o.index[i].pos; bytes.Equal(o.Properties[pos].key, key) {
+			return pos, true
+		}
+	}
+
+	for i, prop := range o.Properties {
+		if bytes.Equal(prop.key, key) {
+			return i, true
+		}
+	}
+
+	return 0, false
 }
 
 func (o *Object) String() string {
@@ -495,14 +627,10 @@ func (o *Object) QueryPath(paths ...string) (JSON, error) {
 	}
 
 	keyStr := paths[0]
-	keyBytes := []byte(keyStr)
-
-	index := sort.Search(len(o.Properties), func(i int) bool {
-		return bytes.Compare(o.Properties[i].key, keyBytes) >= 0
-	})
 
-	if index >= o.Len() || !bytes.Equal(o.Properties[index].key, keyBytes) {
-		return nil, fmt.Errorf("key not found: %q", keyStr)
+	index, ok := o.lookup([]byte(keyStr))
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrKeyNotFound, keyStr)
 	}
 
 	item := o.Properties[index]
@@ -529,7 +657,7 @@ func (o *Object) QueryPath(paths ...string) (JSON, error) {
 // - object: the object being iterated
 type ObjectCallback func(key []byte, value JSON, object *Object)
 
-// ForEach calls the given callback for each key-value pair in the object.
+// ForEach calls the given callback for each key-value pair in the object, in source order.
 // It provides the key, value, and the object itself.
 func (o *Object) ForEach(cb ObjectCallback) {
 	for _, prop := range o.Properties {
@@ -548,8 +676,11 @@ func (o *Object) Equal(o2 JSON) bool {
 		return false
 	}
 
-	for i, prop := range o.Properties {
-		prop2 := other.Properties[i]
+	// objects are compared by key, regardless of the order of their properties
+	index, otherIndex := o.sortedIndex(), other.sortedIndex()
+
+	for i, pos := range index {
+		prop, prop2 := o.Properties[pos], other.Properties[otherIndex[i]]
 
 		if !prop.Equal(&prop2) {
 			return false
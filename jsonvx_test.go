@@ -0,0 +1,262 @@
+package jsonvx
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestStringValue(t *testing.T) {
+	var tests = []struct {
+		msg         string
+		input       []byte
+		cfg         *ParserConfig
+		expected    string
+		expectedRaw string
+		expectedErr error
+	}{
+		{msg: "Plain string", input: []byte(`"hello"`), expected: "hello", expectedRaw: `"hello"`},
+		{msg: "Escaped newline", input: []byte(`"a\nb"`), expected: "a\nb", expectedRaw: `"a\nb"`},
+		{msg: "Unicode escape", input: []byte(`"caf\u00e9"`), expected: "caf\u00e9", expectedRaw: `"caf\u00e9"`},
+		{msg: "Surrogate pair", input: []byte(`"\uD83D\uDE00"`), expected: "\U0001F600", expectedRaw: `"\uD83D\uDE00"`},
+		{msg: "Single quoted, with AllowSingleQuotes", input: []byte(`'it\'s'`), cfg: NewParserConfig(WithAllowSingleQuotes(true)), expected: "it's", expectedRaw: `'it\'s'`},
+		{msg: "Line continuation, with AllowNewlineInStrings", input: []byte("\"a\\\nb\""), cfg: NewParserConfig(WithAllowNewlineInStrings(true)), expected: "ab", expectedRaw: "\"a\\\nb\""},
+		{msg: "Other escapes, with AllowOtherEscapeChars", input: []byte(`"\x41\0\q"`), cfg: NewParserConfig(WithAllowOtherEscapeChars(true)), expected: "A\x00q", expectedRaw: `"\x41\0\q"`},
+		{msg: "Invalid hex escape, with AllowOtherEscapeChars", input: []byte(`"\xZZ"`), cfg: NewParserConfig(WithAllowOtherEscapeChars(true)), expectedRaw: `"\xZZ"`, expectedErr: ErrInvalidEscape},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			parser := NewParser(test.input, test.cfg)
+			node, err := parser.Parse()
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+
+			str, ok := AsString(node)
+			if !ok {
+				t.Fatalf("expected *String, got %T", node)
+			}
+
+			got, err := str.Value()
+			if got != test.expected || !errors.Is(err, test.expectedErr) {
+				t.Errorf("got (%q, %v), expected (%q, %v)", got, err, test.expected, test.expectedErr)
+			}
+
+			if raw := str.RawValue(); raw != test.expectedRaw {
+				t.Errorf("got raw %q, expected %q", raw, test.expectedRaw)
+			}
+		})
+	}
+}
+
+func TestObjectKeyEscapes(t *testing.T) {
+	parser := NewParser([]byte(`{"caf\u00e9": 1, "a\"b": 2}`), nil)
+	node, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	obj, _ := AsObject(node)
+
+	for _, key := range []string{"caf\u00e9", `a"b`} {
+		if _, err := obj.QueryPath(key); err != nil {
+			t.Errorf("QueryPath(%q) returned %v", key, err)
+		}
+	}
+}
+
+func TestNumberValue(t *testing.T) {
+	var tests = []struct {
+		msg             string
+		input           []byte
+		expectedSubKind TokenSubKind
+		expected        float64
+	}{
+		// INTEGER
+		{msg: "Integer", input: []byte("42"), expectedSubKind: INTEGER, expected: 42},
+		{msg: "Negative integer", input: []byte("-42"), expectedSubKind: INTEGER, expected: -42},
+		{msg: "Leading plus integer", input: []byte("+42"), expectedSubKind: INTEGER, expected: 42},
+		{msg: "Integer beyond int64", input: []byte("18446744073709551616"), expectedSubKind: INTEGER, expected: 18446744073709551616},
+
+		// FLOAT
+		{msg: "Float", input: []byte("1.5"), expectedSubKind: FLOAT, expected: 1.5},
+		{msg: "Leading plus float", input: []byte("+1.5"), expectedSubKind: FLOAT, expected: 1.5},
+		{msg: "Leading point float", input: []byte(".5"), expectedSubKind: FLOAT, expected: 0.5},
+		{msg: "Negative leading point float", input: []byte("-.5"), expectedSubKind: FLOAT, expected: -0.5},
+		{msg: "Trailing point float", input: []byte("5."), expectedSubKind: FLOAT, expected: 5},
+		{msg: "Leading plus trailing point float", input: []byte("+5."), expectedSubKind: FLOAT, expected: 5},
+
+		// SCI_NOT
+		{msg: "Scientific notation", input: []byte("123e45"), expectedSubKind: SCI_NOT, expected: 123e45},
+		{msg: "Scientific notation, upper case exponent", input: []byte("-2E-3"), expectedSubKind: SCI_NOT, expected: -2e-3},
+		{msg: "Scientific notation with fraction", input: []byte("1.5e+3"), expectedSubKind: SCI_NOT, expected: 1500},
+		{msg: "Scientific notation with point edge", input: []byte(".5e1"), expectedSubKind: SCI_NOT, expected: 5},
+		{msg: "Scientific notation underflow", input: []byte("2e-400"), expectedSubKind: SCI_NOT, expected: 0},
+
+		// HEX
+		{msg: "Hex", input: []byte("0xFF"), expectedSubKind: HEX, expected: 255},
+		{msg: "Upper case hex prefix", input: []byte("0XdecaF"), expectedSubKind: HEX, expected: 0xdecaf},
+		{msg: "Negative hex", input: []byte("-0x1A"), expectedSubKind: HEX, expected: -26},
+		{msg: "Leading plus hex", input: []byte("+0x1A"), expectedSubKind: HEX, expected: 26},
+		{msg: "Hex beyond int64", input: []byte("0x10000000000000000"), expectedSubKind: HEX, expected: 18446744073709551616},
+
+		// INF
+		{msg: "Infinity", input: []byte("Infinity"), expectedSubKind: INF, expected: math.Inf(1)},
+		{msg: "Leading plus infinity", input: []byte("+Infinity"), expectedSubKind: INF, expected: math.Inf(1)},
+		{msg: "Negative infinity", input: []byte("-Infinity"), expectedSubKind: INF, expected: math.Inf(-1)},
+
+		// NaN
+		{msg: "NaN", input: []byte("NaN"), expectedSubKind: NaN, expected: math.NaN()},
+		{msg: "Leading plus NaN", input: []byte("+NaN"), expectedSubKind: NaN, expected: math.NaN()},
+		{msg: "Negative NaN", input: []byte("-NaN"), expectedSubKind: NaN, expected: math.NaN()},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			parser := NewParser(test.input, JSON5Config())
+			node, err := parser.Parse()
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+
+			num, ok := AsNumber(node)
+			if !ok {
+				t.Fatalf("expected *Number, got %T", node)
+			}
+
+			if num.Token.SubKind != test.expectedSubKind {
+				t.Errorf("got sub kind %s, expected %s", num.Token.SubKind, test.expectedSubKind)
+			}
+
+			got, err := num.Value()
+			if err != nil {
+				t.Fatalf("unexpected value error: %v", err)
+			}
+
+			if math.IsNaN(test.expected) {
+				if !math.IsNaN(got) {
+					t.Errorf("got %v, expected NaN", got)
+				}
+			} else if got != test.expected {
+				t.Errorf("got %v, expected %v", got, test.expected)
+			}
+		})
+	}
+}
+
+func TestNumberTokenValue(t *testing.T) {
+	var tests = []struct {
+		msg      string
+		input    []byte
+		expected any
+	}{
+		{msg: "Integer", input: []byte("+42"), expected: int64(42)},
+		{msg: "Hex", input: []byte("-0xFF"), expected: int64(-255)},
+		{msg: "Integer out of range", input: []byte("99999999999999999999"), expected: 1e20},
+		{msg: "Negative integer out of range", input: []byte("-99999999999999999999"), expected: -1e20},
+		{msg: "Hex out of range", input: []byte("0xFFFFFFFFFFFFFFFFFF"), expected: math.Ldexp(1, 72)},
+		{msg: "Largest hex", input: []byte("0x7FFFFFFFFFFFFFFF"), expected: int64(math.MaxInt64)},
+		{msg: "Float", input: []byte(".5"), expected: 0.5},
+		{msg: "Infinity", input: []byte("-Infinity"), expected: math.Inf(-1)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			token := NewLexer(test.input, JSON5Config()).Token()
+
+			if got := token.Value(); got != test.expected {
+				t.Errorf("got %v (%T), expected %v (%T)", got, got, test.expected, test.expected)
+			}
+		})
+	}
+}
+
+func TestStrictPointEdgeNumbers(t *testing.T) {
+	for _, input := range []string{".5", "5.", "-.5", "+.5", "5.e3"} {
+		parser := NewParser([]byte(input), NewParserConfig(WithAllowLeadingPlus(true)))
+
+		if _, err := parser.Parse(); !errors.Is(err, ErrJSONUnexpectedChar) {
+			t.Errorf("%q: got %v, expected %v", input, err, ErrJSONUnexpectedChar)
+		}
+	}
+}
+
+func TestObjectSourceOrder(t *testing.T) {
+	parser := NewParser([]byte(`{"zeta": 1, "alpha": {"y": true, "b": false}, "mid": null}`), nil)
+	node, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	obj, _ := AsObject(node)
+
+	var keys []string
+	obj.ForEach(func(key []byte, value JSON, object *Object) {
+		keys = append(keys, string(key))
+	})
+
+	if got, expected := strings.Join(keys, ","), "zeta,alpha,mid"; got != expected {
+		t.Errorf("got keys %s, expected %s", got, expected)
+	}
+
+	for _, path := range [][]string{{"zeta"}, {"alpha", "b"}, {"alpha", "y"}, {"mid"}} {
+		if _, err := obj.QueryPath(path...); err != nil {
+			t.Errorf("QueryPath(%v) returned %v", path, err)
+		}
+	}
+
+	if _, err := obj.QueryPath("missing"); err == nil {
+		t.Errorf("QueryPath(missing) returned no error")
+	}
+
+	reordered := &Object{Properties: []KeyValue{obj.Properties[2], obj.Properties[0], obj.Properties[1]}}
+	if !obj.Equal(reordered) {
+		t.Errorf("expected objects with reordered keys to be equal")
+	}
+}
+
+func TestObjectEditedInPlace(t *testing.T) {
+	parser := NewParser([]byte(`{"a": 1, "b": 2, "c": 3}`), nil)
+	node, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	obj, _ := AsObject(node)
+	slices.Reverse(obj.Properties)
+
+	for _, key := range []string{"a", "b", "c"} {
+		if _, err := obj.QueryPath(key); err != nil {
+			t.Errorf("QueryPath(%s) returned %v", key, err)
+		}
+	}
+
+	if _, err := obj.QueryPath("d"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("QueryPath(d) returned %v, expected %v", err, ErrKeyNotFound)
+	}
+
+	// Set reindexes the reordered properties
+	obj.Set("d", &Null{})
+	if !obj.indexed() {
+		t.Errorf("expected Set to rebuild the index")
+	}
+	if _, err := obj.QueryPath("d"); err != nil {
+		t.Errorf("QueryPath(d) returned %v", err)
+	}
+
+	// an index built for other properties is not used
+	obj.Properties = obj.Properties[:3]
+
+	other := &Object{Properties: []KeyValue{obj.Properties[2], obj.Properties[1], obj.Properties[0]}}
+	if !obj.Equal(other) || !other.Equal(obj) {
+		t.Errorf("expected objects with the same properties to be equal")
+	}
+
+	// reading an object built by hand does not write its index
+	if _, err := other.QueryPath("a"); err != nil || other.index != nil {
+		t.Errorf("QueryPath(a) returned %v with index %v", err, other.index)
+	}
+}
@@ -0,0 +1,287 @@
+package jsonvx
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Get returns the value at path within data, as parsing data and calling QueryPath would, without
+// building the tree of the document: the tokens are scanned as they are lexed, the subtrees off the path
+// are skipped by matching their brackets, and only the requested value is parsed.
+//
+// As the lexer reads strings and comments as whole tokens, brackets inside them never unbalance the
+// scan, whatever the syntax the configuration allows. A nil configuration uses the defaults of NewParserConfig.
+//
+// Only the path leading to the value and the value itself are checked against the grammar; the skipped
+// subtrees must merely be made of valid tokens with properly nested brackets, and the input following the
+// containers on the path is not read at all. Within an object holding a key more than once, the first
+// occurrence is used, unless the configuration has DuplicateKeysLastWins, or DuplicateKeysError, which
+// reports a duplicate of a key on the path.
+func Get(data []byte, cfg *ParserConfig, path ...string) (JSON, error) {
+	if cfg == nil {
+		cfg = NewParserConfig()
+	}
+
+	s := lazyScanner{lexer: NewLexer(data, cfg), parser: NewParser(data, cfg)}
+
+	token, err := s.next()
+	if err != nil {
+		return nil, err
+	}
+
+	if token.Kind == EOF {
+		return nil, ErrJSONNoContent
+	}
+
+	for _, key := range path {
+		switch token.Kind {
+		case LEFT_CURLY_BRACE:
+			token, err = s.findKey(key)
+		case LEFT_SQUARE_BRACE:
+			token, err = s.findIndex(key)
+		case NULL, BOOLEAN, STRING, NUMBER:
+			return nil, ErrQueryExceedsDepth
+		default:
+			return nil, s.parser.syntaxError(ErrJSONUnexpectedChar, token, "expected a value", "", valueKinds...)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.build(token)
+}
+
+// lazyScanner reads the tokens of a document for Get.
+type lazyScanner struct {
+	lexer  *Lexer
+	parser Parser // parser builds the requested value and reports syntax errors.
+}
+
+// next returns the next token that is neither whitespace nor a comment.
+func (s *lazyScanner) next() (Token, error) {
+	for {
+		token, err := s.token()
+		if err != nil || (token.Kind != WHITESPACE && token.Kind != COMMENT) {
+			return token, err
+		}
+	}
+}
+
+// token returns the next token, failing on an illegal one.
+func (s *lazyScanner) token() (Token, error) {
+	token := s.lexer.Token()
+
+	if token.Kind == ILLEGAL {
+		return token, s.parser.syntaxError(ErrJSONUnexpectedChar, token, "", "", valueKinds...)
+	}
+
+	return token, nil
+}
+
+// findKey scans the object whose opening brace has just been read for the property with the given key,
+// and returns the first token of its value.
+func (s *lazyScanner) findKey(key string) (Token, error) {
+	config := s.parser.config
+	scanAll := config.DuplicateKeys == DuplicateKeysLastWins || config.DuplicateKeys == DuplicateKeysError
+
+	var (
+		found      bool
+		foundKey   Token
+		foundValue Token
+	)
+
+	token, err := s.next()
+	if err != nil {
+		return Token{}, err
+	}
+
+	for token.Kind != RIGHT_CURLY_BRACE {
+		keyToken := token
+		if keyToken.Kind != STRING {
+			return Token{}, s.parser.syntaxError(ErrJSONSyntax, keyToken, "expected property name", "", STRING)
+		}
+
+		keyValue, err := newString(&keyToken, nil).Value()
+		if err != nil {
+			return Token{}, s.parser.syntaxError(ErrJSONSyntax, keyToken, "bad escape in property name", "", STRING)
+		}
+
+		if token, err = s.next(); err != nil {
+			return Token{}, err
+		}
+		if token.Kind != COLON {
+			return Token{}, s.parser.syntaxError(ErrJSONSyntax, token, "expected ':' after property name", "", COLON)
+		}
+
+		value, err := s.next()
+		if err != nil {
+			return Token{}, err
+		}
+
+		if keyValue == key {
+			if found && config.DuplicateKeys == DuplicateKeysError {
+				return Token{}, WrapJSONDuplicateKeyError(keyToken, foundKey)
+			}
+
+			if !scanAll {
+				return value, nil
+			}
+
+			if !found || config.DuplicateKeys == DuplicateKeysLastWins {
+				found, foundKey, foundValue = true, keyToken, value
+			}
+		}
+
+		if _, err := s.scanValue(value, false); err != nil {
+			return Token{}, err
+		}
+
+		if token, err = s.next(); err != nil {
+			return Token{}, err
+		}
+
+		switch token.Kind {
+		case COMMA:
+			comma := token
+			if token, err = s.next(); err != nil {
+				return Token{}, err
+			}
+			if token.Kind == RIGHT_CURLY_BRACE && !config.AllowTrailingCommaObject {
+				return Token{}, s.parser.syntaxError(ErrJSONSyntax, comma, "trailing comma is not allowed in objects", "AllowTrailingCommaObject", STRING)
+			}
+		case RIGHT_CURLY_BRACE:
+		default:
+			return Token{}, s.parser.syntaxError(ErrJSONSyntax, token, "expected ',' or '}' after property value", "", COMMA, RIGHT_CURLY_BRACE)
+		}
+	}
+
+	if !found {
+		return Token{}, fmt.Errorf("%w: %q", ErrKeyNotFound, key)
+	}
+
+	// the value has been scanned past, it is read again
+	s.lexer.seek(foundValue, foundValue.Offset)
+	return s.next()
+}
+
+// findIndex scans the array whose opening bracket has just been read for the item at the index
+// given by key, and returns its first token.
+func (s *lazyScanner) findIndex(key string) (Token, error) {
+	index, err := strconv.Atoi(key)
+	if err != nil {
+		return Token{}, fmt.Errorf("%w: %q", ErrExpectedIndex, key)
+	}
+
+	token, err := s.next()
+	if err != nil {
+		return Token{}, err
+	}
+
+	length := 0
+	for ; token.Kind != RIGHT_SQUARE_BRACE; length++ {
+		if length == index {
+			return token, nil
+		}
+
+		if _, err := s.scanValue(token, false); err != nil {
+			return Token{}, err
+		}
+
+		if token, err = s.next(); err != nil {
+			return Token{}, err
+		}
+
+		switch token.Kind {
+		case COMMA:
+			comma := token
+			if token, err = s.next(); err != nil {
+				return Token{}, err
+			}
+			if token.Kind == RIGHT_SQUARE_BRACE && !s.parser.config.AllowTrailingCommaArray {
+				return Token{}, s.parser.syntaxError(ErrJSONSyntax, comma, "trailing comma is not allowed in arrays", "AllowTrailingCommaArray", valueKinds...)
+			}
+		case RIGHT_SQUARE_BRACE:
+		default:
+			return Token{}, s.parser.syntaxError(ErrJSONSyntax, token, "expected ',' or ']' after array element", "", COMMA, RIGHT_SQUARE_BRACE)
+		}
+	}
+
+	_, err = arrayIndex(key, length)
+	return Token{}, err
+}
+
+// scanValue reads the tokens of the value starting with start up to its end, matching brackets,
+// and returns them if keep is set.
+func (s *lazyScanner) scanValue(start Token, keep bool) (Tokens, error) {
+	switch start.Kind {
+	case NULL, BOOLEAN, STRING, NUMBER:
+		return Tokens{start}, nil
+	case LEFT_SQUARE_BRACE, LEFT_CURLY_BRACE:
+	default:
+		return nil, s.parser.syntaxError(ErrJSONUnexpectedChar, start, "expected a value", "", valueKinds...)
+	}
+
+	var tokens Tokens
+	if keep {
+		tokens = Tokens{start}
+	}
+
+	// closers holds the closing bracket expected for each open array or object
+	closers := []TokenKind{closingKind(start.Kind)}
+
+	for len(closers) > 0 {
+		token, err := s.token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch token.Kind {
+		case LEFT_SQUARE_BRACE, LEFT_CURLY_BRACE:
+			closers = append(closers, closingKind(token.Kind))
+		case RIGHT_SQUARE_BRACE, RIGHT_CURLY_BRACE:
+			closing := closers[len(closers)-1]
+			if token.Kind != closing {
+				message := "expected ']' to close the array"
+				if closing == RIGHT_CURLY_BRACE {
+					message = "expected '}' to close the object"
+				}
+				return nil, s.parser.syntaxError(ErrJSONSyntax, token, message, "", closing)
+			}
+			closers = closers[:len(closers)-1]
+		case EOF:
+			return nil, s.parser.syntaxError(ErrJSONSyntax, token, "unexpected end of input", "", closers[len(closers)-1])
+		}
+
+		if keep {
+			tokens = append(tokens, token)
+		}
+	}
+
+	return tokens, nil
+}
+
+// closingKind returns the kind of the bracket closing an opening one.
+func closingKind(opening TokenKind) TokenKind {
+	if opening == LEFT_CURLY_BRACE {
+		return RIGHT_CURLY_BRACE
+	}
+	return RIGHT_SQUARE_BRACE
+}
+
+// build parses the value starting with start.
+func (s *lazyScanner) build(start Token) (JSON, error) {
+	if start.Kind == STRING && start.SubKind == IDENT {
+		return nil, s.parser.syntaxError(ErrJSONSyntax, start, "unquoted strings are only allowed as object keys", "", valueKinds...)
+	}
+
+	tokens, err := s.scanValue(start, true)
+	if err != nil {
+		return nil, err
+	}
+
+	s.parser.reset(tokens, nil)
+
+	return s.parser.parse()
+}
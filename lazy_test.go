@@ -0,0 +1,139 @@
+package jsonvx
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	strict := []byte(`{
+		"name": {"first": "Tom", "last": "Anderson"},
+		"age": 37,
+		"children": ["Sara", "Alex", "Jack"],
+		"empty": [],
+		"fav.movie": "Deer Hunter",
+		"friends": [
+			{"first": "Dale", "last": "Murphy", "age": 44, "nets": ["ig", "fb", "tw"]},
+			{"first": "Roger", "last": "Craig", "age": 68, "nets": ["fb", "tw"]},
+			{"first": "Jane", "last": "Murphy", "age": 47, "nets": ["ig", "tw"]}
+		]
+	}`)
+
+	relaxed := []byte(`// a comment with ] and }
+	{
+		/* skipped: { [ */ skipped: ['a]', "b}", {c: '[{'}, /* ] */],
+		'quoted key': 'value with ] and }',
+		nums: [0xFF, .5, +1, Infinity, NaN,],
+		nested: {deep: [[], [{x: 'y'}]],},
+	}`)
+
+	var tests = []struct {
+		msg   string
+		input []byte
+		cfg   *ParserConfig
+		path  []string
+	}{
+		{msg: "Get root", input: strict},
+		{msg: "Get nested member", input: strict, path: []string{"name", "first"}},
+		{msg: "Get object", input: strict, path: []string{"name"}},
+		{msg: "Get number", input: strict, path: []string{"age"}},
+		{msg: "Get item", input: strict, path: []string{"children", "2"}},
+		{msg: "Get key with dot", input: strict, path: []string{"fav.movie"}},
+		{msg: "Get deep item", input: strict, path: []string{"friends", "2", "nets", "1"}},
+		{msg: "Get missing key", input: strict, path: []string{"friends", "0", "middle"}},
+		{msg: "Get index out of range", input: strict, path: []string{"children", "3"}},
+		{msg: "Get negative index", input: strict, path: []string{"children", "-1"}},
+		{msg: "Get key in array", input: strict, path: []string{"children", "first"}},
+		{msg: "Get item of empty array", input: strict, path: []string{"empty", "0"}},
+		{msg: "Get beyond scalar", input: strict, path: []string{"age", "years"}},
+		{msg: "Get past brackets in strings and comments", input: relaxed, cfg: JSON5Config(), path: []string{"quoted key"}},
+		{msg: "Get skipped subtree", input: relaxed, cfg: JSON5Config(), path: []string{"skipped", "2", "c"}},
+		{msg: "Get relaxed numbers", input: relaxed, cfg: JSON5Config(), path: []string{"nums"}},
+		{msg: "Get item after trailing comma", input: relaxed, cfg: JSON5Config(), path: []string{"nums", "5"}},
+		{msg: "Get deep relaxed value", input: relaxed, cfg: JSON5Config(), path: []string{"nested", "deep", "1", "0"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			parser := NewParser(test.input, test.cfg)
+			node, err := parser.Parse()
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+
+			var expected JSON
+			var expectedErr error
+
+			switch val := node.(type) {
+			case *Array:
+				expected, expectedErr = val.QueryPath(test.path...)
+			case *Object:
+				expected, expectedErr = val.QueryPath(test.path...)
+			}
+
+			got, err := Get(test.input, test.cfg, test.path...)
+
+			if expectedErr != nil {
+				if err == nil || err.Error() != expectedErr.Error() {
+					t.Errorf("got error %v, expected %v", err, expectedErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// the value is the same as in the tree, source positions included
+			if !got.Equal(expected) {
+				t.Errorf("got %v, expected %v", got, expected)
+			}
+		})
+	}
+}
+
+func TestGetErrors(t *testing.T) {
+	var tests = []struct {
+		msg         string
+		input       string
+		cfg         *ParserConfig
+		path        []string
+		expected    string
+		expectedErr error
+	}{
+		{msg: "Get from empty input", input: ` `, expectedErr: ErrJSONNoContent},
+		{msg: "Get with illegal token in skipped subtree", input: `{"a": [1, @], "b": 2}`, path: []string{"b"}, expectedErr: ErrJSONUnexpectedChar},
+		{msg: "Get with single quotes not allowed", input: `{"a": ['x]'], "b": 2}`, path: []string{"b"}, expectedErr: ErrJSONUnexpectedChar},
+		{msg: "Get with comment not allowed", input: `{/* } */ "b": 2}`, path: []string{"b"}, expectedErr: ErrJSONUnexpectedChar},
+		{msg: "Get with unbalanced brackets", input: `{"a": [1, {"b": 2}`, path: []string{"b"}, expectedErr: ErrJSONSyntax},
+		{msg: "Get with array closed by brace", input: `{"a": [1, 2}, "b": 3}`, path: []string{"b"}, expectedErr: ErrJSONSyntax},
+		{msg: "Get with object closed by bracket", input: `[{"a": 1], 2]`, path: []string{"1"}, expectedErr: ErrJSONSyntax},
+		{msg: "Get with nested mismatch", input: `{"a": {"b": [{}}], "c": 3}`, path: []string{"c"}, expectedErr: ErrJSONSyntax},
+		{msg: "Get requested value with mismatch", input: `{"a": [1}`, path: []string{"a"}, expectedErr: ErrJSONSyntax},
+		{msg: "Get with missing colon", input: `{"a" 1}`, path: []string{"a"}, expectedErr: ErrJSONSyntax},
+		{msg: "Get with missing comma", input: `{"a": 1 "b": 2}`, path: []string{"b"}, expectedErr: ErrJSONSyntax},
+		{msg: "Get with trailing comma not allowed", input: `[1,]`, path: []string{"1"}, expectedErr: ErrJSONSyntax},
+		{msg: "Get with invalid requested value", input: `{"a": [1 2]}`, path: []string{"a"}, expectedErr: ErrJSONSyntax},
+		{msg: "Get ignores invalid skipped value", input: `{"a": [1 2], "b": 2}`, path: []string{"b"}, expected: `2`},
+		{msg: "Get ignores input after the value", input: `{"a": 1} x`, path: []string{"a"}, expected: `1`},
+		{msg: "Get first duplicate", input: `{"a": 1, "a": 2}`, path: []string{"a"}, expected: `1`},
+		{msg: "Get last duplicate", input: `{"a": [1], "b": 0, "a": [2]}`, cfg: NewParserConfig(WithDuplicateKeys(DuplicateKeysLastWins)), path: []string{"a", "0"}, expected: `2`},
+		{msg: "Get duplicate error", input: `{"a": 1, "a": 2}`, cfg: NewParserConfig(WithDuplicateKeys(DuplicateKeysError)), path: []string{"a"}, expectedErr: ErrJSONDuplicateKey},
+		{msg: "Get unquoted string value", input: `{a: b}`, cfg: JSON5Config(), path: []string{"a"}, expectedErr: ErrJSONSyntax},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			got, err := Get([]byte(test.input), test.cfg, test.path...)
+
+			var data []byte
+			if got != nil {
+				data, _ = Encode(got, nil)
+			}
+
+			if string(data) != test.expected || !errors.Is(err, test.expectedErr) {
+				t.Errorf("got (%s, %v), expected (%s, %v)", data, err, test.expected, test.expectedErr)
+			}
+		})
+	}
+}
@@ -45,6 +45,16 @@ func (l *Lexer) String() string {
 
 // Token returns the current token being parsed by the lexer.
 func (l *Lexer) Token() Token {
+	line, column, offset := l.line, l.column, l.pos
+
+	token := l.token()
+	token.Line, token.Column, token.Offset = line, column, offset
+
+	return token
+}
+
+// token lexes the token starting at the current character.
+func (l *Lexer) token() Token {
 	if l.config == nil {
 		l.config = NewParserConfig()
 	}
@@ -329,14 +339,14 @@ func (l *Lexer) Token() Token {
 				return newToken(ILLEGAL, INVALID_POINT_EDGE_DOT, l.input[pos:], l.line, col, nil)
 			}
 
-			if isFloat(num) {
-				return newToken(NUMBER, FLOAT, num, l.line, col, nil)
-			}
-
 			if isScientificNotation(num) {
 				return newToken(NUMBER, SCI_NOT, num, l.line, col, nil)
 			}
 
+			if isFloat(num) {
+				return newToken(NUMBER, FLOAT, num, l.line, col, nil)
+			}
+
 			// 			isFlt := isFloat(num)
 
 			// if isFlt && (bytes.Contains(num, []byte("e")) || bytes.Contains(num, []byte("E"))) {
@@ -418,9 +428,54 @@ func (l *Lexer) TokensWithout(kind TokenKind) Tokens {
 	return tokens
 }
 
+// tolerantTokens returns all the tokens of the input like Tokens, but does not stop at ILLEGAL tokens:
+// their literal is narrowed down to the malformed text, and lexing resumes right after it.
+func (l *Lexer) tolerantTokens() Tokens {
+	tokens := []Token{}
+
+	for {
+		token := l.Token()
+
+		if token.Kind == ILLEGAL {
+			token.Literal = token.Literal[:recoveryLength(token, l.config)]
+			l.seek(token, token.Offset+len(token.Literal))
+		}
+
+		tokens = append(tokens, token)
+
+		if token.Kind == EOF {
+			break
+		}
+	}
+
+	return tokens
+}
+
+// seek moves the lexer to the character at offset, which must not precede the start of token.
+func (l *Lexer) seek(token Token, offset int) {
+	l.pos = token.Offset
+	l.readPos = token.Offset + 1
+	l.char = l.input[token.Offset]
+	l.line = token.Line
+	l.column = token.Column
+
+	for l.pos < offset {
+		l.readChar()
+	}
+}
+
 // readChar advances the lexer to the next character in the input,
 // updating the current character, position, and line counters as needed.
 func (l *Lexer) readChar() {
+	// a line feed ends its line, the next one starts with the character following it
+	if l.char == '\n' {
+		l.line++
+		l.lastColumn = l.column
+		l.column = 1
+	} else {
+		l.column++
+	}
+
 	if l.readPos > len(l.input)-1 {
 		l.char = 0
 	} else {
@@ -429,14 +484,6 @@ func (l *Lexer) readChar() {
 
 	l.pos = l.readPos
 	l.readPos++
-
-	if l.char == '\n' {
-		l.line++
-		l.lastColumn = l.column
-		l.column = 0
-	} else {
-		l.column++
-	}
 }
 
 // unreadChar moves the lexer back by one character in the input,
@@ -451,6 +498,7 @@ func (l *Lexer) unReadChar() {
 
 	l.char = l.input[l.pos]
 
+	// stepping back onto a line feed returns to the line it ends
 	if l.char == '\n' {
 		l.line--
 		l.column = l.lastColumn
@@ -0,0 +1,434 @@
+package jsonvx
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	jsonType          = reflect.TypeFor[JSON]()
+	marshalerType     = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// Marshal returns the encoding of v, written according to cfg like Encode does.
+// A nil configuration produces compact, strict JSON.
+//
+// The Allow fields of the configuration, which mirror those of ParserConfig, select the dialect
+// of the output: WithDialect(JSON5Config()) gives unquoted keys, and trailing commas in indented
+// output, for instance. See MarshalNode for how Go values are converted.
+func Marshal(v any, cfg *EncoderConfig) ([]byte, error) {
+	node, err := MarshalNode(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return Encode(node, cfg)
+}
+
+// MarshalNode converts v into a tree of nodes, the way encoding/json would encode it:
+//   - structs become objects holding their exported fields, named after their `json` tag
+//     (`omitempty`, `string` and `-` are honoured) and in declaration order;
+//   - maps become objects, their string, integer or encoding.TextMarshaler keys being sorted;
+//   - slices and arrays become arrays, except []byte which becomes a base64 string;
+//   - nil pointers, interfaces, maps and slices become null;
+//   - json.Number becomes a number, kept as written, and nodes are used as they are.
+//
+// Values implementing json.Marshaler or encoding.TextMarshaler are converted with their methods.
+// Floats are written like encoding/json writes them, NaN and Infinity included: they can only
+// be encoded with an EncoderConfig allowing them. Channels, functions, complex numbers and cyclic
+// data fail with ErrUnsupportedValue.
+func MarshalNode(v any) (JSON, error) {
+	m := marshalState{seen: map[cycleKey]struct{}{}}
+	return m.marshal(reflect.ValueOf(v))
+}
+
+// marshalState is the state of a call to MarshalNode.
+type marshalState struct {
+	seen map[cycleKey]struct{} // seen holds the pointers, maps and slices being converted, to detect cycles.
+}
+
+// cycleKey identifies a pointer, map or slice for cycle detection.
+type cycleKey struct {
+	ptr uintptr
+	len int
+	typ reflect.Type
+}
+
+// marshal converts v into a node.
+func (m *marshalState) marshal(v reflect.Value) (JSON, error) {
+	if !v.IsValid() {
+		return marshalNull(), nil
+	}
+
+	t := v.Type()
+
+	if t.Implements(jsonType) {
+		if isNilValue(v) {
+			return marshalNull(), nil
+		}
+		if node := v.Interface().(JSON); !isNilNode(node) {
+			return node, nil
+		}
+		return marshalNull(), nil
+	}
+
+	// methods with a pointer receiver are found on addressable values, as with encoding/json
+	if v.Kind() != reflect.Pointer && v.CanAddr() && reflect.PointerTo(t).Implements(marshalerType) {
+		return m.marshalJSON(v.Addr())
+	}
+	if t.Implements(marshalerType) {
+		return m.marshalJSON(v)
+	}
+
+	if v.Kind() != reflect.Pointer && v.CanAddr() && reflect.PointerTo(t).Implements(textMarshalerType) {
+		return m.marshalText(v.Addr())
+	}
+	if t.Implements(textMarshalerType) {
+		return m.marshalText(v)
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return marshalBoolean(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return marshalNumber(strconv.FormatInt(v.Int(), 10), INTEGER), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return marshalNumber(strconv.FormatUint(v.Uint(), 10), INTEGER), nil
+	case reflect.Float32, reflect.Float64:
+		return marshalFloat(v.Float(), t.Bits()), nil
+	case reflect.String:
+		if t == jsonNumberType {
+			return marshalJSONNumber(json.Number(v.String()))
+		}
+		return marshalString(v.String()), nil
+	case reflect.Interface:
+		if v.IsNil() {
+			return marshalNull(), nil
+		}
+		return m.marshal(v.Elem())
+	case reflect.Pointer:
+		if v.IsNil() {
+			return marshalNull(), nil
+		}
+
+		leave, err := m.enter(cycleKey{ptr: v.Pointer(), typ: t})
+		if err != nil {
+			return nil, err
+		}
+		defer leave()
+
+		return m.marshal(v.Elem())
+	case reflect.Struct:
+		return m.marshalStruct(v)
+	case reflect.Map:
+		return m.marshalMap(v)
+	case reflect.Slice:
+		if v.IsNil() {
+			return marshalNull(), nil
+		}
+
+		if t.Elem().Kind() == reflect.Uint8 && !reflect.PointerTo(t.Elem()).Implements(marshalerType) &&
+			!reflect.PointerTo(t.Elem()).Implements(textMarshalerType) {
+			return marshalString(base64.StdEncoding.EncodeToString(v.Bytes())), nil
+		}
+
+		leave, err := m.enter(cycleKey{ptr: v.Pointer(), len: v.Len(), typ: t})
+		if err != nil {
+			return nil, err
+		}
+		defer leave()
+
+		return m.marshalArray(v)
+	case reflect.Array:
+		return m.marshalArray(v)
+	default:
+		return nil, fmt.Errorf("%w: unsupported type %s", ErrUnsupportedValue, t)
+	}
+}
+
+// enter records that the value identified by key is being converted, it fails if it already is.
+// The returned function must be called once the value is converted.
+func (m *marshalState) enter(key cycleKey) (func(), error) {
+	if _, ok := m.seen[key]; ok {
+		return nil, fmt.Errorf("%w: encountered a cycle via %s", ErrUnsupportedValue, key.typ)
+	}
+
+	m.seen[key] = struct{}{}
+
+	return func() { delete(m.seen, key) }, nil
+}
+
+func (m *marshalState) marshalJSON(v reflect.Value) (JSON, error) {
+	if isNilValue(v) {
+		return marshalNull(), nil
+	}
+
+	data, err := v.Interface().(json.Marshaler).MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("calling MarshalJSON for type %s: %w", v.Type(), err)
+	}
+
+	parser := NewParser(data, nil)
+
+	node, err := parser.Parse()
+	if err != nil {
+		return nil, fmt.Errorf("calling MarshalJSON for type %s: %w", v.Type(), err)
+	}
+
+	return node, nil
+}
+
+func (m *marshalState) marshalText(v reflect.Value) (JSON, error) {
+	if isNilValue(v) {
+		return marshalNull(), nil
+	}
+
+	text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+	if err != nil {
+		return nil, fmt.Errorf("calling MarshalText for type %s: %w", v.Type(), err)
+	}
+
+	return marshalString(string(text)), nil
+}
+
+func (m *marshalState) marshalArray(v reflect.Value) (JSON, error) {
+	items := make([]JSON, v.Len())
+
+	for i := range items {
+		item, err := m.marshal(v.Index(i))
+		if err != nil {
+			return nil, err
+		}
+		items[i] = item
+	}
+
+	return newArray(items, nil), nil
+}
+
+func (m *marshalState) marshalStruct(v reflect.Value) (JSON, error) {
+	var properties []KeyValue
+
+	for _, f := range cachedFields(v.Type()) {
+		fieldVal, ok := fieldValue(v, f.index)
+		if !ok || (f.omitEmpty && isEmptyValue(fieldVal)) {
+			continue
+		}
+
+		value, err := m.marshal(fieldVal)
+		if err != nil {
+			return nil, err
+		}
+
+		if f.quoted {
+			if value, err = quoteNode(value); err != nil {
+				return nil, err
+			}
+		}
+
+		properties = append(properties, newKeyValue([]byte(f.name), value))
+	}
+
+	return newObject(properties, nil), nil
+}
+
+func (m *marshalState) marshalMap(v reflect.Value) (JSON, error) {
+	if v.IsNil() {
+		return marshalNull(), nil
+	}
+
+	leave, err := m.enter(cycleKey{ptr: v.Pointer(), typ: v.Type()})
+	if err != nil {
+		return nil, err
+	}
+	defer leave()
+
+	properties := make([]KeyValue, 0, v.Len())
+
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := marshalKey(iter.Key())
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := m.marshal(iter.Value())
+		if err != nil {
+			return nil, err
+		}
+
+		properties = append(properties, newKeyValue([]byte(key), value))
+	}
+
+	slices.SortFunc(properties, func(a, b KeyValue) int {
+		return bytes.Compare(a.key, b.key)
+	})
+
+	return newObject(properties, nil), nil
+}
+
+// marshalKey returns the object key for a map key.
+func marshalKey(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+
+	if tm, ok := k.Interface().(encoding.TextMarshaler); ok {
+		if isNilValue(k) {
+			return "", nil
+		}
+
+		text, err := tm.MarshalText()
+		if err != nil {
+			return "", fmt.Errorf("calling MarshalText for type %s: %w", k.Type(), err)
+		}
+		return string(text), nil
+	}
+
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	default:
+		return "", fmt.Errorf("%w: unsupported map key type %s", ErrUnsupportedValue, k.Type())
+	}
+}
+
+// fieldValue returns the field of the struct v at index, it reports false if
+// the field is promoted through a nil embedded pointer.
+func fieldValue(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, fieldIndex := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(fieldIndex)
+	}
+
+	return v, true
+}
+
+// quoteNode returns a string holding the strict JSON encoding of a scalar node, for fields with the `string` tag option.
+func quoteNode(node JSON) (JSON, error) {
+	if _, ok := node.(*Null); ok {
+		return node, nil
+	}
+
+	data, err := Encode(node, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return marshalString(string(data)), nil
+}
+
+// isEmptyValue reports whether v is empty for the `omitempty` tag option.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	default:
+		return false
+	}
+}
+
+// isNilValue reports whether v is a nil pointer or interface.
+func isNilValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
+
+func marshalNull() JSON {
+	return newNull(&Token{Kind: NULL, Literal: []byte("null")}, nil)
+}
+
+func marshalBoolean(b bool) JSON {
+	if b {
+		return newBoolean(&Token{Kind: BOOLEAN, SubKind: TRUE, Literal: []byte("true")}, nil)
+	}
+
+	return newBoolean(&Token{Kind: BOOLEAN, SubKind: FALSE, Literal: []byte("false")}, nil)
+}
+
+func marshalString(str string) JSON {
+	state := encodeState{config: NewEncoderConfig()}
+	state.writeString(str)
+
+	return newString(&Token{Kind: STRING, SubKind: DOUBLE_QUOTED, Literal: state.buf.Bytes()}, nil)
+}
+
+func marshalNumber(literal string, subKind TokenSubKind) JSON {
+	return newNumber(&Token{Kind: NUMBER, SubKind: subKind, Literal: []byte(literal)}, nil)
+}
+
+// marshalFloat returns the number node for a float of the given bit size, formatted like encoding/json does.
+func marshalFloat(f float64, bits int) JSON {
+	switch {
+	case math.IsNaN(f):
+		return marshalNumber("NaN", NaN)
+	case math.IsInf(f, 1):
+		return marshalNumber("Infinity", INF)
+	case math.IsInf(f, -1):
+		return marshalNumber("-Infinity", INF)
+	}
+
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+
+	literal := strconv.FormatFloat(f, format, -1, bits)
+
+	if format == 'e' {
+		// clean up e-09 to e-9
+		if n := len(literal); n >= 4 && literal[n-4] == 'e' && literal[n-3] == '-' && literal[n-2] == '0' {
+			literal = literal[:n-2] + literal[n-1:]
+		}
+		return marshalNumber(literal, SCI_NOT)
+	}
+
+	if strings.Contains(literal, ".") {
+		return marshalNumber(literal, FLOAT)
+	}
+
+	return marshalNumber(literal, INTEGER)
+}
+
+// marshalJSONNumber returns the number node for n, which must be a valid JSON number.
+func marshalJSONNumber(n json.Number) (JSON, error) {
+	if n == "" {
+		n = "0"
+	}
+
+	parser := NewParser([]byte(n), nil)
+
+	node, err := parser.Parse()
+	if _, ok := node.(*Number); err != nil || !ok {
+		return nil, fmt.Errorf("%w: invalid number literal %q", ErrUnsupportedValue, n)
+	}
+
+	return node, nil
+}
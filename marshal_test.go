@@ -0,0 +1,219 @@
+package jsonvx
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type marshalPoint struct {
+	X, Y int
+}
+
+func (p marshalPoint) MarshalText() ([]byte, error) {
+	return []byte("point"), nil
+}
+
+type marshalRaw struct{}
+
+func (r *marshalRaw) MarshalJSON() ([]byte, error) {
+	return []byte(` {"raw": [1, 2]} `), nil
+}
+
+type marshalFailing struct{}
+
+func (f marshalFailing) MarshalJSON() ([]byte, error) {
+	return nil, errors.New("boom")
+}
+
+type marshalEmbedded struct {
+	ID int `json:"id"`
+}
+
+type marshalItem struct {
+	*marshalEmbedded
+	Name     string            `json:"name"`
+	Note     string            `json:"note,omitempty"`
+	Tags     []string          `json:"tags,omitempty"`
+	Count    int64             `json:"count,string"`
+	Label    string            `json:"label,string"`
+	Secret   string            `json:"-"`
+	Dash     string            `json:"-,"`
+	Ratio    float32           `json:"ratio"`
+	Data     []byte            `json:"data"`
+	Extra    map[string]any    `json:"extra"`
+	Point    marshalPoint      `json:"point"`
+	Raw      *marshalRaw       `json:"raw"`
+	Missing  *marshalRaw       `json:"missing"`
+	When     time.Time         `json:"when"`
+	Number   json.Number       `json:"number"`
+	Node     JSON              `json:"node"`
+	Points   map[int]string    `json:"points"`
+	Nested   [2]map[string]int `json:"nested"`
+	Untagged bool
+	private  int
+}
+
+func TestMarshal(t *testing.T) {
+	nodeParser := NewParser([]byte(`{a: 0xFF}`), JSON5Config())
+	node, err := nodeParser.Parse()
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	item := marshalItem{
+		marshalEmbedded: &marshalEmbedded{ID: 7},
+		Name:            "<tom>",
+		Count:           42,
+		Label:           "x",
+		Secret:          "hidden",
+		Dash:            "dash",
+		Ratio:           0.1,
+		Data:            []byte("hi"),
+		Extra:           map[string]any{"b": nil, "a": []any{1.5, true}},
+		Raw:             &marshalRaw{},
+		When:            time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Number:          "1e3",
+		Node:            node,
+		Points:          map[int]string{10: "ten", 2: "two"},
+		Untagged:        true,
+		private:         1,
+	}
+
+	expected := `{"id":7,"name":"<tom>","count":"42","label":"\"x\"","-":"dash","ratio":0.1,"data":"aGk=",` +
+		`"extra":{"a":[1.5,true],"b":null},"point":"point","raw":{"raw":[1,2]},"missing":null,` +
+		`"when":"2024-01-02T03:04:05Z","number":1e3,"node":{"a":255},"points":{"10":"ten","2":"two"},` +
+		`"nested":[null,null],"Untagged":true}`
+
+	got, err := Marshal(&item, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if string(got) != expected {
+		t.Errorf("got %s, expected %s", got, expected)
+	}
+
+	// the output of encoding/json decodes to the same value
+	var fromJSON, fromJSONVX any
+	stdData, _ := json.Marshal(&item)
+	if err := json.Unmarshal(stdData, &fromJSON); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := json.Unmarshal(got, &fromJSONVX); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	delete(fromJSON.(map[string]any), "node")
+	delete(fromJSONVX.(map[string]any), "node")
+	if !reflect.DeepEqual(fromJSON, fromJSONVX) {
+		t.Errorf("got %v, expected %v as with encoding/json", fromJSONVX, fromJSON)
+	}
+}
+
+func TestMarshalValues(t *testing.T) {
+	var tests = []struct {
+		msg         string
+		input       any
+		cfg         *EncoderConfig
+		expected    string
+		expectedErr error
+	}{
+		{msg: "Marshal nil", input: nil, expected: `null`},
+		{msg: "Marshal nil slice", input: []int(nil), expected: `null`},
+		{msg: "Marshal empty slice", input: []int{}, expected: `[]`},
+		{msg: "Marshal array", input: [3]uint8{1, 2, 3}, expected: `[1,2,3]`},
+		{msg: "Marshal floats", input: []float64{1, -0.5, 1e21, 1e-7, 123456789}, expected: `[1,-0.5,1e+21,1e-7,123456789]`},
+		{msg: "Marshal float32", input: float32(3.14), expected: `3.14`},
+		{msg: "Marshal escapes", input: "a\"b\\c\n\u2028", expected: `"a\"b\\c\n\u2028"`},
+		{msg: "Marshal HTML escaped", input: "<a>", cfg: NewEncoderConfig(WithEscapeHTML(true)), expected: `"\u003ca\u003e"`},
+		{msg: "Marshal unquoted keys", input: map[string]int{"a": 1, "b-c": 2}, cfg: JSON5EncoderConfig(), expected: `{a:1,'b-c':2}`},
+		{msg: "Marshal trailing commas", input: map[string][]int{"a": {1}}, cfg: NewEncoderConfig(WithIndent("  "), WithDialect(JSON5Config())), expected: "{\n  a: [\n    1,\n  ],\n}"},
+		{msg: "Marshal NaN", input: math.NaN(), expectedErr: ErrUnsupportedValue},
+		{msg: "Marshal NaN in JSON5", input: []float64{math.NaN(), math.Inf(-1)}, cfg: JSON5EncoderConfig(), expected: `[NaN,-Infinity]`},
+		{msg: "Marshal channel", input: make(chan int), expectedErr: ErrUnsupportedValue},
+		{msg: "Marshal invalid json.Number", input: json.Number("12abc"), expectedErr: ErrUnsupportedValue},
+		{msg: "Marshal unsupported map key", input: map[[2]int]int{{1, 2}: 3}, expectedErr: ErrUnsupportedValue},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			got, err := Marshal(test.input, test.cfg)
+
+			if string(got) != test.expected || !errors.Is(err, test.expectedErr) {
+				t.Errorf("got (%q, %v), expected (%q, %v)", got, err, test.expected, test.expectedErr)
+			}
+		})
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	type cyclic struct {
+		Next *cyclic
+	}
+	loop := &cyclic{}
+	loop.Next = loop
+
+	if _, err := Marshal(loop, nil); !errors.Is(err, ErrUnsupportedValue) {
+		t.Errorf("got %v, expected %v", err, ErrUnsupportedValue)
+	}
+
+	shared := &marshalEmbedded{ID: 1}
+	if got, err := Marshal([]*marshalEmbedded{shared, shared}, nil); err != nil || string(got) != `[{"id":1},{"id":1}]` {
+		t.Errorf("got (%s, %v), expected shared pointers to be encoded twice", got, err)
+	}
+
+	if _, err := Marshal(marshalFailing{}, nil); err == nil || err.Error() != "calling MarshalJSON for type jsonvx.marshalFailing: boom" {
+		t.Errorf("got %v, expected the error of MarshalJSON", err)
+	}
+}
+
+func TestMarshalNode(t *testing.T) {
+	node, err := MarshalNode(map[string]any{"a": []int{1, 2}, "b": "x"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	item, err := node.(*Object).QueryPath("a", "1")
+	if err != nil {
+		t.Fatalf("unexpected query error: %v", err)
+	}
+
+	if num, _ := item.(*Number).Int64(); num != 2 {
+		t.Errorf("got %d, expected 2", num)
+	}
+
+	// decoding the tree gives the value back
+	var got map[string]any
+	if err := node.Decode(&got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if expected := map[string]any{"a": []any{1.0, 2.0}, "b": "x"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	var original unmarshalUser
+	input := `{id: 1, name: 'Ann', tags: ['a'], count: '3', meta: {k: [1, 'v']}, pair: [1, 2], raw: [1]}`
+	if err := Unmarshal([]byte(input), &original, JSON5Config()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := Marshal(original, JSON5EncoderConfig())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var decoded unmarshalUser
+	if err := Unmarshal(data, &decoded, JSON5Config()); err != nil {
+		t.Fatalf("unexpected error decoding %s: %v", data, err)
+	}
+
+	if !reflect.DeepEqual(decoded, original) {
+		t.Errorf("got %#v, expected %#v", decoded, original)
+	}
+}
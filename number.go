@@ -0,0 +1,191 @@
+package jsonvx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Errors returned by the arbitrary-precision Number accessors.
+var (
+	ErrNumberOverflow  = errors.New("number overflows the target type")
+	ErrNumberTruncated = errors.New("number cannot be represented without truncation")
+	ErrNumberNotFinite = errors.New("number is not finite")
+)
+
+// bigFloatPrec is the minimum mantissa precision, in bits, used by Number.BigFloat.
+const bigFloatPrec = 256
+
+// Int64 returns the number as an int64.
+// It fails with ErrNumberTruncated if the number has a fractional part, and with
+// ErrNumberOverflow if it does not fit into an int64.
+func (n *Number) Int64() (int64, error) {
+	intVal, err := n.BigInt()
+	if err != nil {
+		return 0, err
+	}
+
+	if !intVal.IsInt64() {
+		return 0, fmt.Errorf("%w: %s does not fit into int64", ErrNumberOverflow, n.Token.Literal)
+	}
+
+	return intVal.Int64(), nil
+}
+
+// Uint64 returns the number as a uint64.
+// It fails with ErrNumberTruncated if the number has a fractional part, and with
+// ErrNumberOverflow if it is negative or does not fit into a uint64.
+func (n *Number) Uint64() (uint64, error) {
+	intVal, err := n.BigInt()
+	if err != nil {
+		return 0, err
+	}
+
+	if !intVal.IsUint64() {
+		return 0, fmt.Errorf("%w: %s does not fit into uint64", ErrNumberOverflow, n.Token.Literal)
+	}
+
+	return intVal.Uint64(), nil
+}
+
+// BigInt returns the exact integer value of the number.
+// Literals such as `1e3` or `2.0` are accepted since they hold integer values,
+// whereas `1.5` fails with ErrNumberTruncated.
+func (n *Number) BigInt() (*big.Int, error) {
+	ratVal, err := n.bigRat()
+	if err != nil {
+		return nil, err
+	}
+
+	if !ratVal.IsInt() {
+		return nil, fmt.Errorf("%w: %s is not an integer", ErrNumberTruncated, n.Token.Literal)
+	}
+
+	return new(big.Int).Set(ratVal.Num()), nil
+}
+
+// BigFloat returns the number as a *big.Float.
+//
+// Integers are always represented exactly. Other values are rounded to at least 256 bits of precision;
+// like strconv.ParseFloat, the rounded value is still returned when the decimal literal has no exact
+// binary representation (e.g., `0.1`), together with an error wrapping ErrNumberTruncated.
+// Infinity is supported, while NaN fails with ErrNumberNotFinite.
+func (n *Number) BigFloat() (*big.Float, error) {
+	if n.Token == nil {
+		return nil, ErrNotNumber
+	}
+
+	if n.Token.SubKind == INF {
+		return new(big.Float).SetInf(n.Token.Literal[0] == '-'), nil
+	}
+
+	ratVal, err := n.bigRat()
+	if err != nil {
+		return nil, err
+	}
+
+	prec := uint(max(bigFloatPrec, ratVal.Num().BitLen()))
+	floatVal := new(big.Float).SetPrec(prec).SetRat(ratVal)
+
+	if floatVal.Acc() != big.Exact {
+		return floatVal, fmt.Errorf("%w: %s has no exact binary representation", ErrNumberTruncated, n.Token.Literal)
+	}
+
+	return floatVal, nil
+}
+
+// Decimal returns the number as a canonical decimal string that is valid under strict JSON.
+// Leading plus signs are dropped, point edge numbers are completed (`.5` becomes `0.5`, `5.` becomes `5`)
+// and hexadecimal numbers are converted to base 10, all without any loss of precision.
+// NaN and Infinity fail with ErrNumberNotFinite.
+func (n *Number) Decimal() (string, error) {
+	if n.Token == nil {
+		return "", ErrNotNumber
+	}
+
+	literal := string(n.Token.Literal)
+
+	switch n.Token.SubKind {
+	case INTEGER:
+		return strings.TrimPrefix(literal, "+"), nil
+	case FLOAT, SCI_NOT:
+		return canonicalDecimal(literal), nil
+	case HEX:
+		intVal, err := n.BigInt()
+		if err != nil {
+			return "", err
+		}
+		return intVal.String(), nil
+	case INF, NaN:
+		return "", fmt.Errorf("%w: %s", ErrNumberNotFinite, literal)
+	default:
+		return "", ErrNotNumber
+	}
+}
+
+// JSONNumber returns the number as a json.Number holding its canonical decimal string.
+// See Decimal for details.
+func (n *Number) JSONNumber() (json.Number, error) {
+	decimal, err := n.Decimal()
+	if err != nil {
+		return "", err
+	}
+
+	return json.Number(decimal), nil
+}
+
+// bigRat returns the exact rational value of a finite number.
+func (n *Number) bigRat() (*big.Rat, error) {
+	if n.Token == nil {
+		return nil, ErrNotNumber
+	}
+
+	switch n.Token.SubKind {
+	case INTEGER, FLOAT, SCI_NOT:
+		ratVal, ok := new(big.Rat).SetString(string(n.Token.Literal))
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrNumberOverflow, n.Token.Literal)
+		}
+		return ratVal, nil
+	case HEX:
+		neg, digits := splitHex(n.Token.Literal)
+		intVal, ok := new(big.Int).SetString(string(digits), 16)
+		if !ok {
+			return nil, ErrNotNumber
+		}
+		if neg {
+			intVal.Neg(intVal)
+		}
+		return new(big.Rat).SetInt(intVal), nil
+	case INF, NaN:
+		return nil, fmt.Errorf("%w: %s", ErrNumberNotFinite, n.Token.Literal)
+	default:
+		return nil, ErrNotNumber
+	}
+}
+
+// canonicalDecimal rewrites a decimal literal so it follows the strict JSON number grammar.
+func canonicalDecimal(literal string) string {
+	literal = strings.TrimPrefix(literal, "+")
+
+	sign := ""
+	if after, ok := strings.CutPrefix(literal, "-"); ok {
+		sign = "-"
+		literal = after
+	}
+
+	mantissa, exponent := literal, ""
+	if index := strings.IndexAny(literal, "eE"); index >= 0 {
+		mantissa, exponent = literal[:index], literal[index:]
+	}
+
+	if strings.HasPrefix(mantissa, ".") {
+		mantissa = "0" + mantissa
+	}
+
+	mantissa = strings.TrimSuffix(mantissa, ".")
+
+	return sign + mantissa + exponent
+}
@@ -0,0 +1,191 @@
+package jsonvx
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func parseNumber(t *testing.T, input string) *Number {
+	t.Helper()
+
+	parser := NewParser([]byte(input), JSON5Config())
+	node, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	num, ok := AsNumber(node)
+	if !ok {
+		t.Fatalf("expected *Number, got %T", node)
+	}
+
+	return num
+}
+
+func TestNumberInt64(t *testing.T) {
+	var tests = []struct {
+		msg         string
+		input       string
+		expected    int64
+		expectedErr error
+	}{
+		{msg: "Integer", input: "9007199254740993", expected: 9007199254740993},
+		{msg: "Max int64", input: "9223372036854775807", expected: 9223372036854775807},
+		{msg: "Min int64", input: "-9223372036854775808", expected: -9223372036854775808},
+		{msg: "Leading plus", input: "+42", expected: 42},
+		{msg: "Integral float", input: "2.0", expected: 2},
+		{msg: "Integral scientific notation", input: "1e3", expected: 1000},
+		{msg: "Trailing point", input: "5.", expected: 5},
+		{msg: "Hex", input: "-0x7FFFFFFFFFFFFFFF", expected: -0x7FFFFFFFFFFFFFFF},
+		{msg: "Overflow", input: "9223372036854775808", expectedErr: ErrNumberOverflow},
+		{msg: "Hex overflow", input: "0x8000000000000000", expectedErr: ErrNumberOverflow},
+		{msg: "Fraction", input: "1.5", expectedErr: ErrNumberTruncated},
+		{msg: "Negative exponent", input: "1e-3", expectedErr: ErrNumberTruncated},
+		{msg: "Leading point", input: ".5", expectedErr: ErrNumberTruncated},
+		{msg: "Infinity", input: "Infinity", expectedErr: ErrNumberNotFinite},
+		{msg: "NaN", input: "NaN", expectedErr: ErrNumberNotFinite},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			got, err := parseNumber(t, test.input).Int64()
+
+			if got != test.expected || !errors.Is(err, test.expectedErr) {
+				t.Errorf("got (%d, %v), expected (%d, %v)", got, err, test.expected, test.expectedErr)
+			}
+		})
+	}
+}
+
+func TestNumberUint64(t *testing.T) {
+	var tests = []struct {
+		msg         string
+		input       string
+		expected    uint64
+		expectedErr error
+	}{
+		{msg: "Max uint64", input: "18446744073709551615", expected: 18446744073709551615},
+		{msg: "Hex max uint64", input: "0xFFFFFFFFFFFFFFFF", expected: 0xFFFFFFFFFFFFFFFF},
+		{msg: "Negative zero", input: "-0", expected: 0},
+		{msg: "Negative", input: "-1", expectedErr: ErrNumberOverflow},
+		{msg: "Overflow", input: "18446744073709551616", expectedErr: ErrNumberOverflow},
+		{msg: "Fraction", input: "0.5", expectedErr: ErrNumberTruncated},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			got, err := parseNumber(t, test.input).Uint64()
+
+			if got != test.expected || !errors.Is(err, test.expectedErr) {
+				t.Errorf("got (%d, %v), expected (%d, %v)", got, err, test.expected, test.expectedErr)
+			}
+		})
+	}
+}
+
+func TestNumberBigInt(t *testing.T) {
+	var tests = []struct {
+		msg         string
+		input       string
+		expected    string
+		expectedErr error
+	}{
+		{msg: "Huge integer", input: "123456789012345678901234567890", expected: "123456789012345678901234567890"},
+		{msg: "Huge hex", input: "0x1000000000000000000000000", expected: "79228162514264337593543950336"},
+		{msg: "Huge scientific notation", input: "1.5e30", expected: "1500000000000000000000000000000"},
+		{msg: "Fraction", input: "1.25", expectedErr: ErrNumberTruncated},
+		{msg: "Negative infinity", input: "-Infinity", expectedErr: ErrNumberNotFinite},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			got, err := parseNumber(t, test.input).BigInt()
+
+			if (got == nil) != (test.expected == "") || (got != nil && got.String() != test.expected) || !errors.Is(err, test.expectedErr) {
+				t.Errorf("got (%v, %v), expected (%s, %v)", got, err, test.expected, test.expectedErr)
+			}
+		})
+	}
+}
+
+func TestNumberBigFloat(t *testing.T) {
+	var tests = []struct {
+		msg         string
+		input       string
+		expected    string
+		expectedErr error
+	}{
+		{msg: "Exact fraction", input: "0.5", expected: "0.5"},
+		{msg: "Huge integer", input: "123456789012345678901234567890", expected: "123456789012345678901234567890"},
+		{msg: "Huge hex", input: "0x1000000000000000000000000", expected: "79228162514264337593543950336"},
+		{msg: "Infinity", input: "+Infinity", expected: "+Inf"},
+		{msg: "Negative infinity", input: "-Infinity", expected: "-Inf"},
+		{msg: "Inexact fraction", input: "0.1", expected: "0.1", expectedErr: ErrNumberTruncated},
+		{msg: "NaN", input: "NaN", expectedErr: ErrNumberNotFinite},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			got, err := parseNumber(t, test.input).BigFloat()
+
+			var text string
+			if got != nil {
+				text = got.Text('g', 30)
+			}
+
+			if text != test.expected || !errors.Is(err, test.expectedErr) {
+				t.Errorf("got (%s, %v), expected (%s, %v)", text, err, test.expected, test.expectedErr)
+			}
+		})
+	}
+}
+
+func TestNumberDecimal(t *testing.T) {
+	var tests = []struct {
+		msg         string
+		input       string
+		expected    string
+		expectedErr error
+	}{
+		{msg: "Integer", input: "42", expected: "42"},
+		{msg: "Leading plus integer", input: "+42", expected: "42"},
+		{msg: "Float", input: "-1.50", expected: "-1.50"},
+		{msg: "Leading point", input: ".5", expected: "0.5"},
+		{msg: "Negative leading point", input: "-.5", expected: "-0.5"},
+		{msg: "Trailing point", input: "+5.", expected: "5"},
+		{msg: "Trailing point with exponent", input: "5.e3", expected: "5e3"},
+		{msg: "Scientific notation", input: "1E+400", expected: "1E+400"},
+		{msg: "Hex", input: "-0xFF", expected: "-255"},
+		{msg: "Infinity", input: "Infinity", expectedErr: ErrNumberNotFinite},
+		{msg: "NaN", input: "-NaN", expectedErr: ErrNumberNotFinite},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			num := parseNumber(t, test.input)
+
+			got, err := num.Decimal()
+			if got != test.expected || !errors.Is(err, test.expectedErr) {
+				t.Errorf("got (%q, %v), expected (%q, %v)", got, err, test.expected, test.expectedErr)
+			}
+
+			jsonNum, err := num.JSONNumber()
+			if string(jsonNum) != test.expected || !errors.Is(err, test.expectedErr) {
+				t.Errorf("got json.Number (%q, %v), expected (%q, %v)", jsonNum, err, test.expected, test.expectedErr)
+			}
+		})
+	}
+}
+
+func TestNumberBigIntIsCopy(t *testing.T) {
+	num := parseNumber(t, "7")
+
+	first, _ := num.BigInt()
+	first.Add(first, big.NewInt(1))
+
+	second, _ := num.BigInt()
+	if second.Int64() != 7 {
+		t.Errorf("got %s, expected 7", second)
+	}
+}
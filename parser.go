@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
-	"sort"
 )
 
 var (
@@ -12,17 +11,21 @@ var (
 	ErrJSONUnexpectedChar  = errors.New("unexpected character in JSON input")
 	ErrJSONNoContent       = errors.New("no meaningful content to parse")
 	ErrJSONMultipleContent = errors.New("multiple JSON values")
+	ErrJSONDuplicateKey    = errors.New("duplicate key in JSON object")
 )
 
 type Parser struct {
-	input  []byte
-	config *ParserConfig
-	tokens Tokens
-
-	curToken  Token
-	curPos    int
-	peekToken Token
-	peekPos   int
+	input      []byte
+	config     *ParserConfig
+	tokens     Tokens
+	source     func() Token // source, when set, supplies the tokens following p.tokens on demand.
+	dropSpaces bool         // dropSpaces, when set, drops the whitespace tokens without a line break supplied by source.
+
+	curToken    Token
+	curPos      int
+	peekToken   Token
+	peekPos     int
+	peekPending bool // peekPending reports whether peekToken is yet to be moved past curToken.
 }
 
 func NewParser(input []byte, config *ParserConfig) Parser {
@@ -52,7 +55,7 @@ func (p *Parser) Parse() (JSON, error) {
 		if lastIndex > 0 && (errors.Is(err, ErrIllegalToken) || errors.Is(err, ErrUnexpectedToken)) {
 			chunk := chunks[lastIndex]
 			illegalToken := tokens[chunk[0]]
-			return nil, WrapJSONUnexpectedCharError(illegalToken)
+			return nil, p.syntaxError(ErrJSONUnexpectedChar, illegalToken, "unexpected character after JSON data", "", EOF)
 		}
 
 	}
@@ -68,16 +71,17 @@ func (p *Parser) Parse() (JSON, error) {
 	}
 
 	chunk := chunks[0]
-	p.tokens = tokens[chunk[0] : chunk[1]+1]
-	p.curPos = -1
-	p.curToken = Token{Kind: EOF}
-	p.peekPos = -1
-	p.peekToken = Token{Kind: EOF}
+	p.reset(tokens[chunk[0]:chunk[1]+1], nil)
 
-	p.nextToken()
-	p.nextToken()
+	node, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
 
-	return p.parse()
+	// everything around the value, but the final EOF token, is trivia
+	p.attachTrivia(node, tokens[:chunk[0]], tokens[chunk[1]+1:len(tokens)-1], false)
+
+	return node, nil
 }
 
 func (p *Parser) parse() (JSON, error) {
@@ -105,11 +109,11 @@ func (p *Parser) parseDefault() (JSON, error) {
 	// fmt.Println("prev Token", p.prevToken)
 	// fmt.Println("current Token", p.curToken)
 	// fmt.Println("peek Token", p.peekToken)
-	return nil, WrapJSONUnexpectedCharError(p.curToken)
+	return nil, p.syntaxError(ErrJSONUnexpectedChar, p.curToken, "expected a value", "", valueKinds...)
 }
 
 func (p *Parser) parseIllegal() (JSON, error) {
-	return nil, WrapJSONUnexpectedCharError(p.curToken)
+	return nil, p.syntaxError(ErrJSONUnexpectedChar, p.curToken, "", "", valueKinds...)
 }
 
 func (p *Parser) parseNull() (JSON, error) {
@@ -121,7 +125,10 @@ func (p *Parser) parseBoolean() (JSON, error) {
 }
 
 func (p *Parser) parseString() (JSON, error) {
-	return newString(&p.tokens[p.curPos], p.nextToken), nil
+	str := newString(&p.tokens[p.curPos], p.nextToken)
+	str.config = p.config
+
+	return str, nil
 }
 
 func (p *Parser) parseNumber() (JSON, error) {
@@ -130,9 +137,12 @@ func (p *Parser) parseNumber() (JSON, error) {
 
 func (p *Parser) parseArray() (JSON, error) {
 	items := []JSON{}
+	openToken := &p.tokens[p.curPos]
 	p.nextToken()
 
-	p.ignoreWhitespacesOrComments()
+	leading := p.ignoreWhitespacesOrComments()
+	var closing Tokens
+	trailingComma := false
 
 	for !p.expectCurToken(RIGHT_SQUARE_BRACE) {
 		item, err := p.parse()
@@ -143,46 +153,80 @@ func (p *Parser) parseArray() (JSON, error) {
 
 		items = append(items, item)
 
-		p.ignoreWhitespacesOrComments()
+		trailing := p.ignoreWhitespacesOrComments()
+		p.attachTrivia(item, leading, trailing, len(items) > 1)
+		leading = nil
 
 		hasComma := p.expectCurToken(COMMA)
 		isClosingBracket := p.expectCurToken(RIGHT_SQUARE_BRACE)
-		isNextClosingBracket := p.expectPeekToken(RIGHT_SQUARE_BRACE, true)
-
-		isTrailingComma := hasComma && isNextClosingBracket
+		// the tokens after the closing bracket are not looked at, they may not have been read yet
+		isTrailingComma := hasComma && p.expectPeekToken(RIGHT_SQUARE_BRACE, true)
 		isValidArrayEnd := isClosingBracket || isTrailingComma
 
 		if !p.config.AllowTrailingCommaArray && isTrailingComma {
-			return nil, WrapJSONSyntaxError(p.curToken)
+			return nil, p.syntaxError(ErrJSONSyntax, p.curToken, "trailing comma is not allowed in arrays", "AllowTrailingCommaArray", valueKinds...)
 		}
 
 		if !isValidArrayEnd && !hasComma {
-			return nil, WrapJSONSyntaxError(p.curToken)
+			return nil, p.syntaxError(ErrJSONSyntax, p.curToken, "expected ',' or ']' after array element", "", COMMA, RIGHT_SQUARE_BRACE)
 		}
 
 		if isValidArrayEnd {
 			if isTrailingComma {
 				p.nextToken()
-				p.ignoreWhitespacesOrComments()
+				closing = p.ignoreWhitespacesOrComments()
+				p.attachSameLineComments(item, closing)
+				trailingComma = true
 			}
 			break
 		}
 
+		// the tokens of the items parsed so far are no longer looked at
 		p.nextToken()
-		p.ignoreWhitespacesOrComments()
+		p.discard()
+		leading = p.ignoreWhitespacesOrComments()
+		p.attachSameLineComments(item, leading)
+	}
+
+	if len(items) == 0 {
+		closing = leading
 	}
 
-	return newArray(items, p.nextToken), nil
+	closeToken := &p.tokens[p.curPos]
+	arr := newArray(items, p.nextToken)
+	arr.openToken, arr.closeToken = openToken, closeToken
+	arr.trailingComma = trailingComma
+	arr.closing = p.attachClosingTrivia(&arr.Trivia, closing, trailingComma)
+
+	return arr, nil
 }
 
 func (p *Parser) parseObject() (JSON, error) {
 	properties := []KeyValue{}
+	openToken := &p.tokens[p.curPos]
 	p.nextToken()
 
-	p.ignoreWhitespacesOrComments()
+	// seen maps each key to the position and token of its first occurrence,
+	// it is only needed when duplicate keys are not simply kept.
+	type occurrence struct {
+		index int
+		token Token
+	}
+	var seen map[string]occurrence
+	if p.config.DuplicateKeys != DuplicateKeysKeepAll {
+		seen = map[string]occurrence{}
+	}
+
+	keyLeading := p.ignoreWhitespacesOrComments()
+	var closing Tokens
+	trailingComma, afterComma := false, false
 
 	for !p.expectCurToken(RIGHT_CURLY_BRACE) {
 		keyToken := p.curToken
+		if p.expectCurToken(EOF) {
+			return nil, p.syntaxError(ErrJSONSyntax, keyToken, "expected property name", "", STRING)
+		}
+
 		jsonKey, err := p.parse()
 		if err != nil {
 			return nil, err
@@ -191,98 +235,167 @@ func (p *Parser) parseObject() (JSON, error) {
 		keyString, ok := jsonKey.(*String)
 
 		if !ok {
-			return nil, WrapJSONSyntaxError(keyToken)
+			return nil, p.syntaxError(ErrJSONSyntax, keyToken, "expected property name", "", STRING)
 		}
 
 		if !ok {
-			return nil, WrapJSONSyntaxError(keyToken)
+			return nil, p.syntaxError(ErrJSONSyntax, keyToken, "expected property name", "", STRING)
 		}
 
 		key := keyString.Token.Literal
 
 		if keyString.Token.SubKind != IDENT && len(key) < 2 {
-			return nil, WrapJSONSyntaxError(keyToken)
+			return nil, p.syntaxError(ErrJSONSyntax, keyToken, "expected property name", "", STRING)
 		}
 
-		p.ignoreWhitespacesOrComments()
+		keyTrailing := p.ignoreWhitespacesOrComments()
 
 		hasColon := p.expectCurToken(COLON)
 
 		if !hasColon {
-			return nil, WrapJSONSyntaxError(p.curToken)
+			return nil, p.syntaxError(ErrJSONSyntax, p.curToken, "expected ':' after property name", "", COLON)
 		}
 
 		p.nextToken()
 
-		p.ignoreWhitespacesOrComments()
+		valueLeading := p.ignoreWhitespacesOrComments()
 
 		value, err := p.parse()
 		if err != nil {
 			return nil, err
 		}
 
-		p.ignoreWhitespacesOrComments()
+		valueTrailing := p.ignoreWhitespacesOrComments()
+		p.attachTrivia(value, valueLeading, valueTrailing, false)
 
 		valueString, ok := value.(*String)
 
 		if ok && valueString.Token.Kind == STRING && valueString.Token.SubKind == IDENT {
-			return nil, WrapJSONSyntaxError(*valueString.Token)
+			return nil, p.syntaxError(ErrJSONSyntax, *valueString.Token, "unquoted strings are only allowed as object keys", "", valueKinds...)
 		}
 
-		keySlice := key
-		if keyString.Token.SubKind != IDENT {
-			keySlice = key[1 : len(key)-1]
+		keyValue, err := keyString.Value()
+		if err != nil {
+			return nil, p.syntaxError(ErrJSONSyntax, keyToken, "bad escape in property name", "", STRING)
 		}
 
-		properties = append(properties, KeyValue{key: keySlice, value: value})
+		first, isDuplicate := seen[keyValue]
+
+		switch {
+		case !isDuplicate:
+			if seen != nil {
+				seen[keyValue] = occurrence{index: len(properties), token: keyToken}
+			}
+			property := KeyValue{key: []byte(keyValue), value: value, keyToken: keyString.Token}
+			p.attach(&property.keyTrivia, keyLeading, keyTrailing, afterComma)
+			properties = append(properties, property)
+		case p.config.DuplicateKeys == DuplicateKeysError:
+			return nil, WrapJSONDuplicateKeyError(keyToken, first.token)
+		case p.config.DuplicateKeys == DuplicateKeysLastWins:
+			properties[first.index].value = value
+		}
 
 		hasComma := p.expectCurToken(COMMA)
 		isClosingBracket := p.expectCurToken(RIGHT_CURLY_BRACE)
-		isNextClosingBracket := p.expectPeekToken(RIGHT_CURLY_BRACE, true)
-
-		isTrailingComma := hasComma && isNextClosingBracket
+		// the tokens after the closing bracket are not looked at, they may not have been read yet
+		isTrailingComma := hasComma && p.expectPeekToken(RIGHT_CURLY_BRACE, true)
 		isValidArrayEnd := isClosingBracket || isTrailingComma
 
 		if !p.config.AllowTrailingCommaObject && isTrailingComma {
-			return nil, WrapJSONSyntaxError(p.curToken)
+			return nil, p.syntaxError(ErrJSONSyntax, p.curToken, "trailing comma is not allowed in objects", "AllowTrailingCommaObject", STRING)
 		}
 
 		if !isValidArrayEnd && !hasComma {
-			return nil, WrapJSONSyntaxError(p.curToken)
+			return nil, p.syntaxError(ErrJSONSyntax, p.curToken, "expected ',' or '}' after property value", "", COMMA, RIGHT_CURLY_BRACE)
 		}
 
 		if isValidArrayEnd {
 			if isTrailingComma {
 				p.nextToken()
-				p.ignoreWhitespacesOrComments()
+				closing = p.ignoreWhitespacesOrComments()
+				p.attachSameLineComments(value, closing)
+				trailingComma = true
 			}
 			break
 		}
 
+		// the tokens of the properties parsed so far are no longer looked at
 		p.nextToken()
-		p.ignoreWhitespacesOrComments()
+		p.discard()
+		keyLeading = p.ignoreWhitespacesOrComments()
+		p.attachSameLineComments(value, keyLeading)
+		afterComma = true
 	}
 
-	sort.Slice(properties, func(i, j int) bool {
-		return bytes.Compare(properties[i].key, properties[j].key) < 0
-	})
+	if len(properties) == 0 {
+		closing = keyLeading
+	}
+
+	closeToken := &p.tokens[p.curPos]
+	obj := newObject(properties, p.nextToken)
+	obj.openToken, obj.closeToken = openToken, closeToken
+	obj.trailingComma = trailingComma
+	obj.closing = p.attachClosingTrivia(&obj.Trivia, closing, trailingComma)
+
+	return obj, nil
+}
+
+// reset makes the parser start over with the given tokens, followed by those supplied by source, if any.
+func (p *Parser) reset(tokens Tokens, source func() Token) {
+	p.tokens = tokens
+	p.source = source
+	p.curPos = -1
+	p.curToken = Token{Kind: EOF}
+	p.peekPos = -1
+	p.peekToken = Token{Kind: EOF}
+	p.peekPending = false
 
-	return newObject(properties, p.nextToken), nil
+	p.nextToken()
+	p.nextToken()
 }
 
 func (p *Parser) nextToken() {
+	p.resolvePeek()
+
 	p.curToken = p.peekToken
 	p.curPos = p.peekPos
 
-	if p.peekPos+1 >= len(p.tokens) {
+	// the token after the current one is only read when it is looked at, so a value read from
+	// a stream is complete as soon as its last token has been read
+	p.peekPending = true
+}
+
+// resolvePeek moves peekToken to the token following curToken, if it has not been already.
+func (p *Parser) resolvePeek() {
+	if !p.peekPending {
+		return
+	}
+
+	p.peekPending = false
+
+	if !p.available(p.curPos + 1) {
 		p.peekToken = Token{Kind: EOF, SubKind: NONE}
 		p.peekPos = len(p.tokens)
 	} else {
-		p.peekPos++
+		p.peekPos = p.curPos + 1
 		p.peekToken = p.tokens[p.peekPos]
 	}
 }
 
+// discard drops the tokens preceding the current one, once they are no longer needed, so that
+// a value read from a source holds no more tokens than its nodes point to. Nodes and trivia keep
+// pointing to the dropped tokens, as the slice is only moved on.
+func (p *Parser) discard() {
+	if p.curPos <= 0 {
+		return
+	}
+
+	n := p.curPos
+	p.tokens = p.tokens[n:]
+	p.curPos -= n
+	p.peekPos -= n
+}
+
 func (p *Parser) expectCurToken(kind TokenKind) bool {
 	if p.curToken.Kind == kind {
 		return true
@@ -292,6 +405,8 @@ func (p *Parser) expectCurToken(kind TokenKind) bool {
 }
 
 func (p *Parser) expectPeekToken(kind TokenKind, ignoreWhitespaceOrComments bool) bool {
+	p.resolvePeek()
+
 	if p.peekToken.Kind == kind {
 		return true
 	}
@@ -306,7 +421,7 @@ func (p *Parser) expectPeekToken(kind TokenKind, ignoreWhitespaceOrComments bool
 
 	peekPos := p.peekPos + 1
 
-	for peekPos < len(p.tokens) {
+	for p.available(peekPos) {
 		peekToken := p.tokens[peekPos]
 
 		if peekToken.Kind == kind {
@@ -323,30 +438,77 @@ func (p *Parser) expectPeekToken(kind TokenKind, ignoreWhitespaceOrComments bool
 	return false
 }
 
-func (p *Parser) ignoreWhitespacesOrComments() {
+// available reports whether there is a token at index i, pulling tokens from the source as needed.
+// The source is no longer used once it has supplied an EOF token.
+func (p *Parser) available(i int) bool {
+	for i >= len(p.tokens) && p.source != nil {
+		token := p.source()
+		if p.dropSpaces && token.Kind == WHITESPACE && !bytes.ContainsAny(token.Literal, "\n\r") {
+			continue
+		}
+
+		p.tokens = append(p.tokens, token)
+
+		if token.Kind == EOF {
+			p.source = nil
+		}
+	}
+
+	return i < len(p.tokens)
+}
+
+// ignoreWhitespacesOrComments skips whitespace and comment tokens and returns them.
+func (p *Parser) ignoreWhitespacesOrComments() Tokens {
+	start := p.curPos
+
 	for p.expectCurToken(WHITESPACE) || p.expectCurToken(COMMENT) {
 		p.nextToken()
 	}
+
+	if start < 0 || start == p.curPos {
+		return nil
+	}
+
+	return p.tokens[start:p.curPos]
 }
 
-func WrapUnexpectedCharError(baseErr error, token Token) error {
-	return fmt.Errorf("%w: %q at line %d, column %d", baseErr, token.Literal, token.Line, token.Column)
+// WrapUnexpectedCharError returns a *SyntaxError for the offending token, falling under baseErr.
+func WrapUnexpectedCharError(baseErr error, token Token, expected ...TokenKind) error {
+	return newSyntaxError(baseErr, token, nil, expected...)
 }
-func WrapJSONUnexpectedCharError(token Token) error {
-	return WrapUnexpectedCharError(ErrJSONUnexpectedChar, token)
+
+// WrapJSONUnexpectedCharError returns a *SyntaxError falling under ErrJSONUnexpectedChar.
+func WrapJSONUnexpectedCharError(token Token, expected ...TokenKind) error {
+	return WrapUnexpectedCharError(ErrJSONUnexpectedChar, token, expected...)
 }
 
-func WrapJSONSyntaxError(token Token) error {
-	return WrapUnexpectedCharError(ErrJSONSyntax, token)
+// WrapJSONSyntaxError returns a *SyntaxError falling under ErrJSONSyntax.
+func WrapJSONSyntaxError(token Token, expected ...TokenKind) error {
+	return WrapUnexpectedCharError(ErrJSONSyntax, token, expected...)
 }
 
+// WrapJSONMultipleContentError returns a *SyntaxError falling under ErrJSONMultipleContent,
+// for a value found after the end of the first one.
 func WrapJSONMultipleContentError(token Token) error {
-	return fmt.Errorf("%w: extra value %q at line %d, column %d",
-		ErrJSONMultipleContent,
+	err := newSyntaxError(ErrJSONMultipleContent, token, nil, EOF)
+	err.detail = fmt.Sprintf("extra value %q at line %d, column %d", token.Literal, token.Line, token.Column)
+
+	return err
+}
+
+// WrapJSONDuplicateKeyError returns a *SyntaxError falling under ErrJSONDuplicateKey,
+// for a key already defined at firstToken.
+func WrapJSONDuplicateKeyError(token, firstToken Token) error {
+	err := newSyntaxError(ErrJSONDuplicateKey, token, nil)
+	err.detail = fmt.Sprintf("%s at line %d, column %d, first defined at line %d, column %d",
 		token.Literal,
 		token.Line,
 		token.Column,
+		firstToken.Line,
+		firstToken.Column,
 	)
+
+	return err
 }
 
 // var (
@@ -386,38 +548,6 @@ func WrapJSONMultipleContentError(token Token) error {
 // 	objectPool.Put(&m)
 // }
 
-// SyntaxError: JSON.parse: unterminated string literal
-// SyntaxError: JSON.parse: bad control character in string literal
-// SyntaxError: JSON.parse: bad character in string literal
-// SyntaxError: JSON.parse: bad Unicode escape
-// SyntaxError: JSON.parse: bad escape character
-// SyntaxError: JSON.parse: unterminated string
-// SyntaxError: JSON.parse: no number after minus sign
-// SyntaxError: JSON.parse: unexpected non-digit
-// SyntaxError: JSON.parse: missing digits after decimal point
-// SyntaxError: JSON.parse: unterminated fractional number/
-// SyntaxError: JSON.parse: missing digits after exponent indicator
-// SyntaxError: JSON.parse: missing digits after exponent sign
-// SyntaxError: JSON.parse: exponent part is missing a number
-// SyntaxError: JSON.parse: unexpected end of data
-// SyntaxError: JSON.parse: unexpected keyword
-// SyntaxError: JSON.parse: unexpected character
-// SyntaxError: JSON.parse: end of data while reading object contents
-// SyntaxError: JSON.parse: expected property name or '}'
-// SyntaxError: JSON.parse: end of data when ',' or ']' was expected
-// SyntaxError: JSON.parse: expected ',' or ']' after array element
-// SyntaxError: JSON.parse: end of data when property name was expected
-// SyntaxError: JSON.parse: expected double-quoted property name
-// SyntaxError: JSON.parse: end of data after property name when ':' was expected
-// SyntaxError: JSON.parse: expected ':' after property name in object
-// SyntaxError: JSON.parse: end of data after property value in object
-// SyntaxError: JSON.parse: expected ',' or '}' after property value in object
-// SyntaxError: JSON.parse: expected ',' or '}' after property-value pair in object literal
-// SyntaxError: JSON.parse: property names must be double-quoted strings
-// SyntaxError: JSON.parse: expected property name or '}'
-// SyntaxError: JSON.parse: unexpected character
-// SyntaxError: JSON.parse: unexpected non-whitespace character after JSON data
-
 // ✅ Option 1: 123+4i or 123-4i
 // json
 // Copy
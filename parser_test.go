@@ -232,3 +232,35 @@ func TestJSONParserObject(t *testing.T) {
 	}
 	runJSONParserTests(t, tests)
 }
+
+func TestJSONParserDuplicateKeys(t *testing.T) {
+	input := []byte(`{"a": 1, "b": 2, "a": 3}`)
+	first := newKeyValue([]byte(`a`), &Number{Token: newTokenPtr(NUMBER, INTEGER, []byte(`1`), 1, 7, nil)})
+	second := newKeyValue([]byte(`b`), &Number{Token: newTokenPtr(NUMBER, INTEGER, []byte(`2`), 1, 15, nil)})
+	last := newKeyValue([]byte(`a`), &Number{Token: newTokenPtr(NUMBER, INTEGER, []byte(`3`), 1, 23, nil)})
+
+	var tests = []ParserTest{
+		{msg: "Parse duplicate keys, keeping all", input: input, expectedNode: &Object{Properties: []KeyValue{first, second, last}}, expectedErr: nil, cfg: NewParserConfig(WithDuplicateKeys(DuplicateKeysKeepAll))},
+		{msg: "Parse duplicate keys, first wins", input: input, expectedNode: &Object{Properties: []KeyValue{first, second}}, expectedErr: nil, cfg: NewParserConfig(WithDuplicateKeys(DuplicateKeysFirstWins))},
+		{msg: "Parse duplicate keys, last wins", input: input, expectedNode: &Object{Properties: []KeyValue{newKeyValue([]byte(`a`), last.value), second}}, expectedErr: nil, cfg: NewParserConfig(WithDuplicateKeys(DuplicateKeysLastWins))},
+		{msg: "Parse duplicate keys, as error", input: input, expectedNode: nil, expectedErr: ErrJSONDuplicateKey, cfg: NewParserConfig(WithDuplicateKeys(DuplicateKeysError))},
+		{msg: "Parse escaped duplicate keys, as error", input: []byte(`{"a": 1, "\u0061": 2}`), expectedNode: nil, expectedErr: ErrJSONDuplicateKey, cfg: NewParserConfig(WithDuplicateKeys(DuplicateKeysError))},
+		{msg: "Parse nested duplicate keys, as error", input: []byte(`{"a": {"b": 1, "b": 2}}`), expectedNode: nil, expectedErr: ErrJSONDuplicateKey, cfg: NewParserConfig(WithDuplicateKeys(DuplicateKeysError))},
+		{msg: "Parse same keys in sibling objects, as error", input: []byte(`[{"a": 1}, {"a": 2}]`), expectedNode: &Array{Items: []JSON{
+			&Object{Properties: []KeyValue{newKeyValue([]byte(`a`), &Number{Token: newTokenPtr(NUMBER, INTEGER, []byte(`1`), 1, 8, nil)})}},
+			&Object{Properties: []KeyValue{newKeyValue([]byte(`a`), &Number{Token: newTokenPtr(NUMBER, INTEGER, []byte(`2`), 1, 18, nil)})}},
+		}}, expectedErr: nil, cfg: NewParserConfig(WithDuplicateKeys(DuplicateKeysError))},
+	}
+
+	runJSONParserTests(t, tests)
+}
+
+func TestJSONParserDuplicateKeyPositions(t *testing.T) {
+	parser := NewParser([]byte("{\n  \"a\": 1,\n  \"a\": 2\n}"), NewParserConfig(WithDuplicateKeys(DuplicateKeysError)))
+	_, err := parser.Parse()
+
+	expected := `duplicate key in JSON object: "a" at line 3, column 3, first defined at line 2, column 3`
+	if err == nil || err.Error() != expected {
+		t.Errorf("got %v, expected %s", err, expected)
+	}
+}
@@ -33,6 +33,35 @@ type ParserConfig struct {
 
 	AllowLineComments  bool // AllowLineComments enables the use of single-line comments (// ...).
 	AllowBlockComments bool // AllowBlockComments enables the use of block comments (/* ... */).
+
+	DuplicateKeys DuplicateKeyPolicy // DuplicateKeys controls how keys that appear more than once in the same object are handled.
+
+	PreserveTrivia bool // PreserveTrivia keeps the whitespace and comments surrounding each node, so Print can reproduce the input byte for byte.
+}
+
+// DuplicateKeyPolicy controls how the parser handles a key that appears more than once in the same object.
+type DuplicateKeyPolicy int
+
+const (
+	DuplicateKeysKeepAll   DuplicateKeyPolicy = iota // DuplicateKeysKeepAll keeps every occurrence, QueryPath returns the first one.
+	DuplicateKeysError                               // DuplicateKeysError fails parsing, reporting the position of both occurrences.
+	DuplicateKeysFirstWins                           // DuplicateKeysFirstWins keeps the first occurrence and drops the others.
+	DuplicateKeysLastWins                            // DuplicateKeysLastWins keeps the value of the last occurrence, at the position of the first.
+)
+
+// String returns a string representation of the DuplicateKeyPolicy.
+func (d DuplicateKeyPolicy) String() string {
+	m := map[DuplicateKeyPolicy]string{
+		DuplicateKeysKeepAll:   "DuplicateKeysKeepAll",
+		DuplicateKeysError:     "DuplicateKeysError",
+		DuplicateKeysFirstWins: "DuplicateKeysFirstWins",
+		DuplicateKeysLastWins:  "DuplicateKeysLastWins",
+	}
+
+	if str, ok := m[d]; ok {
+		return str
+	}
+	return "UNKNOWN"
 }
 
 // NewParserConfig creates a new ParserConfig instance, optionally applying one or more configuration options.
@@ -53,10 +82,25 @@ func (c *ParserConfig) String() string {
 	var b strings.Builder
 	b.WriteString("ParserConfig{\n")
 
-	configFields := []struct {
-		name  string
-		value bool
-	}{
+	for _, f := range c.flags() {
+		b.WriteString(fmt.Sprintf("  %s: %v,\n", f.name, f.value))
+	}
+
+	b.WriteString(fmt.Sprintf("  DuplicateKeys: %s,\n", c.DuplicateKeys))
+
+	b.WriteString("}")
+	return b.String()
+}
+
+// configFlag is a boolean option of a ParserConfig, along with its name.
+type configFlag struct {
+	name  string
+	value bool
+}
+
+// flags returns the boolean options of the configuration, in declaration order.
+func (c *ParserConfig) flags() []configFlag {
+	return []configFlag{
 		{"AllowExtraWS", c.AllowExtraWS},
 		{"AllowHexNumbers", c.AllowHexNumbers},
 		{"AllowPointEdgeNumbers", c.AllowPointEdgeNumbers},
@@ -71,14 +115,8 @@ func (c *ParserConfig) String() string {
 		{"AllowTrailingCommaObject", c.AllowTrailingCommaObject},
 		{"AllowLineComments", c.AllowLineComments},
 		{"AllowBlockComments", c.AllowBlockComments},
+		{"PreserveTrivia", c.PreserveTrivia},
 	}
-
-	for _, f := range configFields {
-		b.WriteString(fmt.Sprintf("  %s: %v,\n", f.name, f.value))
-	}
-
-	b.WriteString("}")
-	return b.String()
 }
 
 // WithAllowExtraWS is the functional option setters for the AllowExtraWS flag.
@@ -168,6 +206,20 @@ func WithAllowBlockComments(allow bool) func(*ParserConfig) {
 	}
 }
 
+// WithDuplicateKeys is the functional option setter for the DuplicateKeys policy.
+func WithDuplicateKeys(policy DuplicateKeyPolicy) func(*ParserConfig) {
+	return func(c *ParserConfig) {
+		c.DuplicateKeys = policy
+	}
+}
+
+// WithPreserveTrivia is the functional option setter for the PreserveTrivia flag.
+func WithPreserveTrivia(preserve bool) func(*ParserConfig) {
+	return func(c *ParserConfig) {
+		c.PreserveTrivia = preserve
+	}
+}
+
 // JSON5Config returns a ParserConfig with all features enabled for JSON5 compatibility.
 func JSON5Config() *ParserConfig {
 	return &ParserConfig{
@@ -0,0 +1,287 @@
+package jsonvx
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidPointer is the error for a JSON Pointer that is not valid under RFC 6901.
+var ErrInvalidPointer = errors.New("invalid JSON pointer")
+
+// Pointer is an RFC 6901 JSON Pointer, held as its unescaped reference tokens:
+// `/friends/0/nets` is Pointer{"friends", "0", "nets"} and the empty pointer, "", refers to the whole document.
+//
+// A Pointer is a plain slice of keys and indices, so the paths of TokenReader.Path and
+// DecodeError.Path convert to it directly.
+type Pointer []string
+
+// ParsePointer parses the string representation of a JSON Pointer, unescaping `~1` to `/` and `~0` to `~`.
+// It fails with an error wrapping ErrInvalidPointer if str is neither empty nor starts with '/',
+// or if a '~' is not followed by '0' or '1'.
+func ParsePointer(str string) (Pointer, error) {
+	if str == "" {
+		return Pointer{}, nil
+	}
+
+	if str[0] != '/' {
+		return nil, fmt.Errorf("%w: %q must start with '/'", ErrInvalidPointer, str)
+	}
+
+	tokens := strings.Split(str[1:], "/")
+	for i, token := range tokens {
+		unescaped, ok := unescapePointerToken(token)
+		if !ok {
+			return nil, fmt.Errorf("%w: %q has an invalid escape in %q", ErrInvalidPointer, str, token)
+		}
+		tokens[i] = unescaped
+	}
+
+	return Pointer(tokens), nil
+}
+
+// unescapePointerToken replaces `~1` with `/` and `~0` with `~`, it reports false for any other use of '~'.
+func unescapePointerToken(token string) (string, bool) {
+	if !strings.Contains(token, "~") {
+		return token, true
+	}
+
+	var builder strings.Builder
+	for i := 0; i < len(token); i++ {
+		if token[i] != '~' {
+			builder.WriteByte(token[i])
+			continue
+		}
+
+		if i+1 == len(token) {
+			return "", false
+		}
+
+		switch token[i+1] {
+		case '0':
+			builder.WriteByte('~')
+		case '1':
+			builder.WriteByte('/')
+		default:
+			return "", false
+		}
+		i++
+	}
+
+	return builder.String(), true
+}
+
+// String returns the string representation of the pointer, escaping `~` to `~0` and `/` to `~1`.
+func (p Pointer) String() string {
+	var builder strings.Builder
+
+	replacer := strings.NewReplacer("~", "~0", "/", "~1")
+	for _, token := range p {
+		builder.WriteByte('/')
+		replacer.WriteString(&builder, token)
+	}
+
+	return builder.String()
+}
+
+// Append returns a new pointer, referring to the child of p with the given key or index.
+func (p Pointer) Append(tokens ...string) Pointer {
+	return append(slices.Clip(p), tokens...)
+}
+
+// Parent returns the pointer to the array or object holding the value p refers to,
+// and false for the empty pointer.
+func (p Pointer) Parent() (Pointer, bool) {
+	if len(p) == 0 {
+		return nil, false
+	}
+
+	return p[: len(p)-1 : len(p)-1], true
+}
+
+// Resolve returns the value p refers to within node.
+//
+// It fails with the errors of QueryPath: ErrKeyNotFound for a missing member, ErrExpectedIndex for
+// an array index that is not a non-negative integer without leading zeros, ErrEmptyArray and
+// ErrIndexOutOfRange for a missing item, including the `-` past-the-end item, and
+// ErrQueryExceedsDepth when a token is left after reaching a scalar value.
+func (p Pointer) Resolve(node JSON) (JSON, error) {
+	current := node
+
+	for _, token := range p {
+		switch val := current.(type) {
+		case *Array:
+			if isNilNode(val) {
+				return nil, ErrInvalidJSONType
+			}
+
+			index, err := pointerIndex(token)
+			if err != nil {
+				return nil, err
+			}
+
+			if val.Len() == 0 {
+				return nil, ErrEmptyArray
+			}
+
+			if index < 0 || index >= val.Len() {
+				return nil, fmt.Errorf("%w: %s", ErrIndexOutOfRange, token)
+			}
+
+			current = val.Items[index]
+		case *Object:
+			if isNilNode(val) {
+				return nil, ErrInvalidJSONType
+			}
+
+			index, ok := val.lookup([]byte(token))
+			if !ok {
+				return nil, fmt.Errorf("%w: %q", ErrKeyNotFound, token)
+			}
+
+			current = val.Properties[index].value
+		case *Null, *Boolean, *Number, *String:
+			return nil, ErrQueryExceedsDepth
+		default:
+			return nil, ErrInvalidJSONType
+		}
+	}
+
+	if isNilNode(current) {
+		return nil, ErrInvalidJSONType
+	}
+
+	return current, nil
+}
+
+// Set writes value at the location p refers to within node, which must be an array or an object.
+//
+// The parent of the location must exist. A member of an object is replaced, or added when missing,
+// as with Object.Set; an item of an array is replaced, as with Array.Set, while the `-` token appends
+// value to the array. The empty pointer, which refers to node itself, cannot be set.
+func (p Pointer) Set(node JSON, value JSON) error {
+	parentPtr, ok := p.Parent()
+	if !ok {
+		return fmt.Errorf("%w: cannot set the root value", ErrInvalidPointer)
+	}
+
+	parent, err := parentPtr.Resolve(node)
+	if err != nil {
+		return err
+	}
+
+	token := p[len(p)-1]
+
+	switch val := parent.(type) {
+	case *Array:
+		if token == "-" {
+			val.Items = append(val.Items, value)
+			return nil
+		}
+
+		index, err := pointerIndex(token)
+		if err != nil {
+			return err
+		}
+
+		return val.Set(index, value)
+	case *Object:
+		val.Set(token, value)
+		return nil
+	case *Null, *Boolean, *Number, *String:
+		return ErrQueryExceedsDepth
+	default:
+		return ErrInvalidJSONType
+	}
+}
+
+// pointerIndex parses an array index token, which RFC 6901 restricts to decimal digits without
+// leading zeros. The `-` token, the item past the end of the array, is returned as -1.
+func pointerIndex(token string) (int, error) {
+	if token == "-" {
+		return -1, nil
+	}
+
+	if token == "" || (token[0] == '0' && len(token) > 1) || strings.TrimLeft(token, "0123456789") != "" {
+		return 0, fmt.Errorf("%w: %q", ErrExpectedIndex, token)
+	}
+
+	index, err := strconv.Atoi(token)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrIndexOutOfRange, token)
+	}
+
+	return index, nil
+}
+
+// Pointer returns the value the JSON Pointer ptr, such as `/0/nets/1`, refers to within the array.
+// See ParsePointer and Pointer.Resolve for the errors.
+func (a *Array) Pointer(ptr string) (JSON, error) {
+	return resolvePointer(a, ptr)
+}
+
+// Pointer returns the value the JSON Pointer ptr, such as `/friends/0/nets/1`, refers to within the object.
+// See ParsePointer and Pointer.Resolve for the errors.
+func (o *Object) Pointer(ptr string) (JSON, error) {
+	return resolvePointer(o, ptr)
+}
+
+func resolvePointer(node JSON, ptr string) (JSON, error) {
+	pointer, err := ParsePointer(ptr)
+	if err != nil {
+		return nil, err
+	}
+
+	return pointer.Resolve(node)
+}
+
+// Pointer returns the JSON Pointer of the matched node, relative to the queried one.
+func (m PathMatch) Pointer() Pointer {
+	pointer := make(Pointer, len(m.steps))
+	for i, step := range m.steps {
+		if step.isIndex {
+			pointer[i] = strconv.Itoa(step.index)
+		} else {
+			pointer[i] = step.name
+		}
+	}
+
+	return pointer
+}
+
+// WalkCallback defines the function signature for walking a tree of nodes.
+// - node: the current node
+// - ptr: the JSON Pointer of the node from the walked one
+// Returning false skips the items or properties of node.
+type WalkCallback func(node JSON, ptr Pointer) bool
+
+// Walk calls the given callback for node and each of its descendants, in document order.
+// Every callback gets a pointer of its own, which it can keep.
+func Walk(node JSON, cb WalkCallback) {
+	walk(node, Pointer{}, cb)
+}
+
+func walk(node JSON, ptr Pointer, cb WalkCallback) {
+	if !cb(node, ptr) {
+		return
+	}
+
+	switch val := node.(type) {
+	case *Array:
+		if val == nil {
+			return
+		}
+		for i, item := range val.Items {
+			walk(item, ptr.Append(strconv.Itoa(i)), cb)
+		}
+	case *Object:
+		if val == nil {
+			return
+		}
+		for _, prop := range val.Properties {
+			walk(prop.value, ptr.Append(string(prop.key)), cb)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package jsonvx
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParsePointer(t *testing.T) {
+	var tests = []struct {
+		msg         string
+		input       string
+		expected    Pointer
+		expectedErr error
+	}{
+		{msg: "Parse empty pointer", input: ``, expected: Pointer{}},
+		{msg: "Parse root member with empty key", input: `/`, expected: Pointer{""}},
+		{msg: "Parse pointer", input: `/friends/0/nets`, expected: Pointer{"friends", "0", "nets"}},
+		{msg: "Parse escapes", input: `/a~1b/m~0n/~01`, expected: Pointer{"a/b", "m~n", "~1"}},
+		{msg: "Parse empty tokens", input: `/a//`, expected: Pointer{"a", "", ""}},
+		{msg: "Parse pointer without leading slash", input: `a/b`, expectedErr: ErrInvalidPointer},
+		{msg: "Parse invalid escape", input: `/a~2`, expectedErr: ErrInvalidPointer},
+		{msg: "Parse trailing tilde", input: `/a~`, expectedErr: ErrInvalidPointer},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			got, err := ParsePointer(test.input)
+
+			if !reflect.DeepEqual(got, test.expected) || !errors.Is(err, test.expectedErr) {
+				t.Errorf("got (%q, %v), expected (%q, %v)", got, err, test.expected, test.expectedErr)
+			}
+
+			if err == nil && got.String() != test.input {
+				t.Errorf("got %q, expected the pointer to be written back as %q", got.String(), test.input)
+			}
+		})
+	}
+}
+
+func TestPointerResolve(t *testing.T) {
+	input := `{
+		"foo": ["bar", "baz"],
+		"": 0,
+		"a/b": 1,
+		"c%d": 2,
+		"e^f": 3,
+		"g|h": 4,
+		"i\\j": 5,
+		"k\"l": 6,
+		" ": 7,
+		"m~n": 8,
+		"empty": [],
+		"nested": {"list": [{"x": true}]}
+	}`
+
+	var tests = []struct {
+		msg         string
+		pointer     string
+		expected    string
+		expectedErr error
+	}{
+		{msg: "Resolve whole document", pointer: ``, expected: `{"foo":["bar","baz"],"":0,"a/b":1,"c%d":2,"e^f":3,"g|h":4,"i\\j":5,"k\"l":6," ":7,"m~n":8,"empty":[],"nested":{"list":[{"x":true}]}}`},
+		{msg: "Resolve array", pointer: `/foo`, expected: `["bar","baz"]`},
+		{msg: "Resolve item", pointer: `/foo/0`, expected: `"bar"`},
+		{msg: "Resolve empty key", pointer: `/`, expected: `0`},
+		{msg: "Resolve escaped slash", pointer: `/a~1b`, expected: `1`},
+		{msg: "Resolve percent", pointer: `/c%d`, expected: `2`},
+		{msg: "Resolve caret", pointer: `/e^f`, expected: `3`},
+		{msg: "Resolve pipe", pointer: `/g|h`, expected: `4`},
+		{msg: "Resolve backslash", pointer: `/i\j`, expected: `5`},
+		{msg: "Resolve double quote", pointer: `/k"l`, expected: `6`},
+		{msg: "Resolve space", pointer: `/ `, expected: `7`},
+		{msg: "Resolve escaped tilde", pointer: `/m~0n`, expected: `8`},
+		{msg: "Resolve nested", pointer: `/nested/list/0/x`, expected: `true`},
+		{msg: "Resolve missing key", pointer: `/missing`, expectedErr: ErrKeyNotFound},
+		{msg: "Resolve index out of range", pointer: `/foo/2`, expectedErr: ErrIndexOutOfRange},
+		{msg: "Resolve past the end", pointer: `/foo/-`, expectedErr: ErrIndexOutOfRange},
+		{msg: "Resolve leading zero", pointer: `/foo/01`, expectedErr: ErrExpectedIndex},
+		{msg: "Resolve negative index", pointer: `/foo/-1`, expectedErr: ErrExpectedIndex},
+		{msg: "Resolve key in array", pointer: `/foo/bar`, expectedErr: ErrExpectedIndex},
+		{msg: "Resolve empty array", pointer: `/empty/0`, expectedErr: ErrEmptyArray},
+		{msg: "Resolve beyond scalar", pointer: `/foo/0/x`, expectedErr: ErrQueryExceedsDepth},
+		{msg: "Resolve invalid pointer", pointer: `foo`, expectedErr: ErrInvalidPointer},
+	}
+
+	parser := NewParser([]byte(input), nil)
+	node, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	obj, _ := AsObject(node)
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			got, err := obj.Pointer(test.pointer)
+
+			if !errors.Is(err, test.expectedErr) {
+				t.Fatalf("got error %v, expected %v", err, test.expectedErr)
+			}
+
+			if test.expectedErr != nil {
+				return
+			}
+
+			data, err := Encode(got, nil)
+			if err != nil {
+				t.Fatalf("unexpected encode error: %v", err)
+			}
+
+			if string(data) != test.expected {
+				t.Errorf("got %s, expected %s", data, test.expected)
+			}
+		})
+	}
+}
+
+func TestPointerSet(t *testing.T) {
+	var tests = []struct {
+		msg         string
+		input       string
+		pointer     Pointer
+		expected    string
+		expectedErr error
+	}{
+		{msg: "Set member", input: `{"a": 1}`, pointer: Pointer{"a"}, expected: `{"a":true}`},
+		{msg: "Set new member", input: `{"a": 1}`, pointer: Pointer{"b"}, expected: `{"a":1,"b":true}`},
+		{msg: "Set item", input: `[1, 2]`, pointer: Pointer{"1"}, expected: `[1,true]`},
+		{msg: "Set past the end", input: `{"a": [1]}`, pointer: Pointer{"a", "-"}, expected: `{"a":[1,true]}`},
+		{msg: "Set past the end of empty array", input: `[]`, pointer: Pointer{"-"}, expected: `[true]`},
+		{msg: "Set index out of range", input: `[1]`, pointer: Pointer{"1"}, expectedErr: ErrIndexOutOfRange},
+		{msg: "Set missing parent", input: `{}`, pointer: Pointer{"a", "b"}, expectedErr: ErrKeyNotFound},
+		{msg: "Set in scalar", input: `{"a": 1}`, pointer: Pointer{"a", "b"}, expectedErr: ErrQueryExceedsDepth},
+		{msg: "Set root", input: `{}`, pointer: Pointer{}, expectedErr: ErrInvalidPointer},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			parser := NewParser([]byte(test.input), nil)
+			node, err := parser.Parse()
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+
+			err = test.pointer.Set(node, marshalBoolean(true))
+			if !errors.Is(err, test.expectedErr) {
+				t.Fatalf("got error %v, expected %v", err, test.expectedErr)
+			}
+
+			if test.expectedErr != nil {
+				return
+			}
+
+			data, err := Encode(node, nil)
+			if err != nil {
+				t.Fatalf("unexpected encode error: %v", err)
+			}
+
+			if string(data) != test.expected {
+				t.Errorf("got %s, expected %s", data, test.expected)
+			}
+		})
+	}
+}
+
+func TestWalk(t *testing.T) {
+	parser := NewParser([]byte(`{"a/b": [1, {"~": 2}], "c": {"d": 3}}`), nil)
+	node, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	var got []string
+	Walk(node, func(n JSON, ptr Pointer) bool {
+		got = append(got, ptr.String())
+
+		// every pointer resolves back to its node
+		if resolved, err := ptr.Resolve(node); err != nil || resolved != n {
+			t.Errorf("got (%v, %v) resolving %s, expected %v", resolved, err, ptr, n)
+		}
+
+		_, isObject := n.(*Object)
+		return !isObject || len(ptr) == 0
+	})
+
+	expected := []string{``, `/a~1b`, `/a~1b/0`, `/a~1b/1`, `/c`}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %q, expected %q", got, expected)
+	}
+}
+
+func TestPathMatchPointer(t *testing.T) {
+	parser := NewParser([]byte(`{"store": {"a~b": [{"x": 1}, {"x": 2}]}}`), nil)
+	node, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	matches, err := QueryJSONPath(node, `$..x`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, match := range matches {
+		got = append(got, match.Pointer().String())
+	}
+
+	expected := []string{`/store/a~0b/0/x`, `/store/a~0b/1/x`}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %q, expected %q", got, expected)
+	}
+}
@@ -0,0 +1,300 @@
+package jsonvx
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// pathTrie merges the paths given to QueryPaths, so that their common prefixes are only walked once.
+type pathTrie struct {
+	targets  []int                // targets holds the positions of the paths ending here.
+	keys     []string             // keys holds the keys of children, in the order they were first seen.
+	children map[string]*pathTrie // children holds the subtries by key.
+}
+
+// newPathTrie builds the trie of the given paths.
+func newPathTrie(paths [][]string) *pathTrie {
+	root := &pathTrie{}
+
+	for i, path := range paths {
+		t := root
+		for _, key := range path {
+			child, ok := t.children[key]
+			if !ok {
+				if t.children == nil {
+					t.children = map[string]*pathTrie{}
+				}
+				child = &pathTrie{}
+				t.children[key] = child
+				t.keys = append(t.keys, key)
+			}
+			t = child
+		}
+		t.targets = append(t.targets, i)
+	}
+
+	return root
+}
+
+// resolve records node as the result of the paths ending here.
+func (t *pathTrie) resolve(node JSON, results []JSON, errs []error) {
+	for _, i := range t.targets {
+		switch node.(type) {
+		case *Null, *Boolean, *Number, *String, *Array, *Object:
+			results[i], errs[i] = node, nil
+		default:
+			results[i], errs[i] = nil, ErrInvalidJSONType
+		}
+	}
+}
+
+// fail records err for every path going through t.
+func (t *pathTrie) fail(err error, results []JSON, errs []error) {
+	for _, i := range t.targets {
+		results[i], errs[i] = nil, err
+	}
+
+	for _, child := range t.children {
+		child.fail(err, results, errs)
+	}
+}
+
+// QueryPaths retrieves several nested items at once, each path being a list of segments as accepted
+// by QueryPath. The tree is walked once, paths sharing a prefix walking it together, and the result
+// and error of each path are at its position in the returned slices.
+func (a *Array) QueryPaths(paths [][]string) ([]JSON, []error) {
+	return queryPaths(a, paths)
+}
+
+// QueryPaths retrieves several nested values at once, each path being a list of segments as accepted
+// by QueryPath. The tree is walked once, paths sharing a prefix walking it together, and the result
+// and error of each path are at its position in the returned slices.
+func (o *Object) QueryPaths(paths [][]string) ([]JSON, []error) {
+	return queryPaths(o, paths)
+}
+
+func queryPaths(node JSON, paths [][]string) ([]JSON, []error) {
+	results, errs := make([]JSON, len(paths)), make([]error, len(paths))
+
+	queryTrie(node, newPathTrie(paths), results, errs)
+
+	return results, errs
+}
+
+// queryTrie resolves the paths of t within node, failing them with the errors of QueryPath.
+func queryTrie(node JSON, t *pathTrie, results []JSON, errs []error) {
+	t.resolve(node, results, errs)
+
+	if len(t.keys) == 0 {
+		return
+	}
+
+	switch val := node.(type) {
+	case *Array:
+		for _, key := range t.keys {
+			child := t.children[key]
+
+			index, err := arrayIndex(key, val.Len())
+			if err != nil {
+				child.fail(err, results, errs)
+				continue
+			}
+
+			queryTrie(val.Items[index], child, results, errs)
+		}
+	case *Object:
+		for _, key := range t.keys {
+			child := t.children[key]
+
+			index, ok := val.lookup([]byte(key))
+			if !ok {
+				child.fail(fmt.Errorf("%w: %q", ErrKeyNotFound, key), results, errs)
+				continue
+			}
+
+			queryTrie(val.Properties[index].value, child, results, errs)
+		}
+	case *Null, *Boolean, *Number, *String:
+		for _, child := range t.children {
+			child.fail(ErrQueryExceedsDepth, results, errs)
+		}
+	default:
+		for _, child := range t.children {
+			child.fail(ErrInvalidJSONType, results, errs)
+		}
+	}
+}
+
+// arrayIndex parses a QueryPath segment as an index into an array of the given length.
+func arrayIndex(key string, length int) (int, error) {
+	index, err := strconv.Atoi(key)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrExpectedIndex, key)
+	}
+
+	if length == 0 {
+		return 0, ErrEmptyArray
+	}
+
+	if index < 0 || index >= length {
+		return 0, fmt.Errorf("%w: %d", ErrIndexOutOfRange, index)
+	}
+
+	return index, nil
+}
+
+// QueryPaths reads the next value, like ReadValue, and retrieves several nested values from it at once,
+// each path being a list of segments as accepted by QueryPath. Only the requested values are built, the
+// rest of the document being skipped, and the result and error of each path are at its position in the
+// returned slices, with the errors QueryPath would return.
+//
+// Within an object holding a key more than once, the first occurrence is used, unless the configuration
+// has DuplicateKeysLastWins. An error reading the document fails every path not resolved yet.
+func (r *TokenReader) QueryPaths(paths [][]string) ([]JSON, []error) {
+	results, errs := make([]JSON, len(paths)), make([]error, len(paths))
+	t := newPathTrie(paths)
+
+	event, err := r.Next()
+	if err == nil {
+		if event.Kind == EventEndObject || event.Kind == EventEndArray {
+			err = fmt.Errorf("%w: got %s", ErrNoValue, event.Kind)
+		} else {
+			err = r.queryTrie(event, t, results, errs)
+		}
+	}
+
+	if err != nil {
+		for i := range paths {
+			if results[i] == nil && errs[i] == nil {
+				errs[i] = err
+			}
+		}
+	}
+
+	return results, errs
+}
+
+// queryTrie resolves the paths of t within the value starting with event, reading the value in full.
+// Values are only built when a path ends at them.
+func (r *TokenReader) queryTrie(event Event, t *pathTrie, results []JSON, errs []error) error {
+	if len(t.targets) > 0 {
+		node, err := r.build(event)
+		if err != nil {
+			return err
+		}
+
+		queryTrie(node, t, results, errs)
+		return nil
+	}
+
+	switch event.Kind {
+	case EventBeginObject:
+		return r.queryObject(t, results, errs)
+	case EventBeginArray:
+		return r.queryArray(t, results, errs)
+	default:
+		for _, child := range t.children {
+			child.fail(ErrQueryExceedsDepth, results, errs)
+		}
+		return nil
+	}
+}
+
+func (r *TokenReader) queryObject(t *pathTrie, results []JSON, errs []error) error {
+	found := map[string]bool{}
+
+	for {
+		event, err := r.Next()
+		if err != nil {
+			return err
+		}
+
+		if event.Kind == EventEndObject {
+			break
+		}
+
+		child, ok := t.children[event.Key]
+		if !ok || (found[event.Key] && r.config.DuplicateKeys != DuplicateKeysLastWins) {
+			if err := r.Skip(); err != nil {
+				return err
+			}
+			continue
+		}
+		found[event.Key] = true
+
+		value, err := r.Next()
+		if err != nil {
+			return err
+		}
+
+		// a later occurrence of the key replaces the results of an earlier one
+		child.fail(nil, results, errs)
+
+		if err := r.queryTrie(value, child, results, errs); err != nil {
+			return err
+		}
+	}
+
+	for _, key := range t.keys {
+		if !found[key] {
+			t.children[key].fail(fmt.Errorf("%w: %q", ErrKeyNotFound, key), results, errs)
+		}
+	}
+
+	return nil
+}
+
+func (r *TokenReader) queryArray(t *pathTrie, results []JSON, errs []error) error {
+	// the keys parsing to the same index, like "1" and "+1", read the item together
+	wanted := map[int][]*pathTrie{}
+	for _, key := range t.keys {
+		index, err := strconv.Atoi(key)
+		if err != nil {
+			t.children[key].fail(fmt.Errorf("%w: %q", ErrExpectedIndex, key), results, errs)
+			continue
+		}
+		wanted[index] = append(wanted[index], t.children[key])
+	}
+
+	length := 0
+	for ; ; length++ {
+		event, err := r.Next()
+		if err != nil {
+			return err
+		}
+
+		if event.Kind == EventEndArray {
+			break
+		}
+
+		children := wanted[length]
+		delete(wanted, length)
+
+		switch {
+		case len(children) == 1:
+			err = r.queryTrie(event, children[0], results, errs)
+		case len(children) > 1:
+			var node JSON
+			if node, err = r.build(event); err == nil {
+				for _, child := range children {
+					queryTrie(node, child, results, errs)
+				}
+			}
+		case event.Kind == EventBeginObject || event.Kind == EventBeginArray:
+			err = r.Skip()
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	for index, children := range wanted {
+		_, err := arrayIndex(strconv.Itoa(index), length)
+		for _, child := range children {
+			child.fail(err, results, errs)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package jsonvx
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestQueryPaths(t *testing.T) {
+	data := []byte(`{
+		"name": {"first": "Tom", "last": "Anderson"},
+		"age": 37,
+		"children": ["Sara", "Alex", "Jack"],
+		"empty": [],
+		"fav.movie": "Deer Hunter",
+		"friends": [
+			{"first": "Dale", "last": "Murphy", "age": 44, "nets": ["ig", "fb", "tw"]},
+			{"first": "Roger", "last": "Craig", "age": 68, "nets": ["fb", "tw"]},
+			{"first": "Jane", "last": "Murphy", "age": 47, "nets": ["ig", "tw"]}
+		]
+	}`)
+
+	paths := [][]string{
+		{"name", "first"},
+		{"name", "last"},
+		{"name"},
+		{"age"},
+		{"children", "1"},
+		{"children", "+1"},
+		{"children", "1"},
+		{"children", "3"},
+		{"children", "-1"},
+		{"children", "x"},
+		{"empty", "0"},
+		{"fav.movie"},
+		{"friends", "0", "nets", "2"},
+		{"friends", "2", "nets", "0"},
+		{"friends", "1", "first"},
+		{"friends", "1"},
+		{"age", "years"},
+		{"missing", "key"},
+		{},
+	}
+
+	parser := NewParser(data, nil)
+	node, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	obj, _ := AsObject(node)
+
+	results, errs := obj.QueryPaths(paths)
+	streamResults, streamErrs := NewTokenReader(bytes.NewReader(data), nil).QueryPaths(paths)
+
+	for i, path := range paths {
+		expected, expectedErr := obj.QueryPath(path...)
+
+		checkResult := func(name string, got JSON, err error) {
+			if expectedErr != nil {
+				if err == nil || err.Error() != expectedErr.Error() {
+					t.Errorf("%s %q: got error %v, expected %v", name, path, err, expectedErr)
+				}
+				return
+			}
+
+			gotData, _ := Encode(got, nil)
+			expectedData, _ := Encode(expected, nil)
+			if err != nil || !bytes.Equal(gotData, expectedData) {
+				t.Errorf("%s %q: got (%s, %v), expected %s", name, path, gotData, err, expectedData)
+			}
+		}
+
+		checkResult("tree", results[i], errs[i])
+		checkResult("stream", streamResults[i], streamErrs[i])
+
+		// the tree version returns the nodes of the tree
+		if expectedErr == nil && results[i] != expected {
+			t.Errorf("tree %q: got %p, expected the node %p", path, results[i], expected)
+		}
+	}
+}
+
+func TestTokenReaderQueryPaths(t *testing.T) {
+	var tests = []struct {
+		msg          string
+		input        string
+		cfg          *ParserConfig
+		paths        [][]string
+		expected     []string
+		expectedErrs []error
+	}{
+		{
+			msg:          "Query duplicate keys",
+			input:        `{"a": 1, "a": 2}`,
+			paths:        [][]string{{"a"}},
+			expected:     []string{`1`},
+			expectedErrs: []error{nil},
+		},
+		{
+			msg:          "Query duplicate keys with last wins",
+			input:        `{"a": {"b": 1}, "a": {"c": 2}}`,
+			cfg:          NewParserConfig(WithDuplicateKeys(DuplicateKeysLastWins)),
+			paths:        [][]string{{"a", "b"}, {"a", "c"}},
+			expected:     []string{``, `2`},
+			expectedErrs: []error{ErrKeyNotFound, nil},
+		},
+		{
+			msg:          "Query relaxed input",
+			input:        "// comment\n{a: [1, {b: 'x]'}], /* c */ c: 0xFF,}",
+			cfg:          JSON5Config(),
+			paths:        [][]string{{"c"}, {"a", "1", "b"}},
+			expected:     []string{`255`, `"x]"`},
+			expectedErrs: []error{nil, nil},
+		},
+		{
+			msg:          "Query root array",
+			input:        `[[1, 2], [3]]`,
+			paths:        [][]string{{"1", "0"}, {"0", "2"}, {"2"}},
+			expected:     []string{`3`, ``, ``},
+			expectedErrs: []error{nil, ErrIndexOutOfRange, ErrIndexOutOfRange},
+		},
+		{
+			msg:          "Query syntax error",
+			input:        `{"a": 1, "b": }`,
+			paths:        [][]string{{"a"}, {"b"}},
+			expected:     []string{`1`, ``},
+			expectedErrs: []error{nil, ErrJSONUnexpectedChar},
+		},
+		{
+			msg:          "Query end of container",
+			input:        `]`,
+			paths:        [][]string{{"a"}},
+			expected:     []string{``},
+			expectedErrs: []error{ErrJSONUnexpectedChar},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			reader := NewTokenReader(bytes.NewReader([]byte(test.input)), test.cfg)
+			results, errs := reader.QueryPaths(test.paths)
+
+			for i := range test.paths {
+				var got []byte
+				if results[i] != nil {
+					got, _ = Encode(results[i], nil)
+				}
+
+				if string(got) != test.expected[i] || !errors.Is(errs[i], test.expectedErrs[i]) {
+					t.Errorf("path %q: got (%s, %v), expected (%s, %v)", test.paths[i], got, errs[i], test.expected[i], test.expectedErrs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTokenReaderQueryPathsSkips(t *testing.T) {
+	reader := NewTokenReader(bytes.NewReader([]byte(`{"big": [1, [2, {"x": 3}]], "a": 1, "c": {"d": [4]}}`)), nil)
+
+	results, errs := reader.QueryPaths([][]string{{"a"}})
+	if errs[0] != nil {
+		t.Fatalf("unexpected error: %v", errs[0])
+	}
+
+	if got, _ := Encode(results[0], nil); string(got) != `1` {
+		t.Errorf("got %s, expected 1", got)
+	}
+
+	// the whole document has been read
+	if _, err := reader.Next(); err != io.EOF {
+		t.Errorf("got %v, expected %v", err, io.EOF)
+	}
+}
@@ -0,0 +1,479 @@
+package jsonvx
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// ErrorNode is a placeholder for a part of the input that could not be parsed.
+// It is only produced by Parser.ParseTolerant.
+type ErrorNode struct {
+	Tokens Tokens       // Tokens holds the tokens skipped over, it is empty when a value is missing (e.g., `[1,,2]`).
+	Err    *SyntaxError // Err is the error the node stands for.
+	Trivia
+}
+
+func (e *ErrorNode) String() string {
+	if e.Err == nil {
+		return "\033[1;31m<error>\033[0m"
+	}
+
+	return fmt.Sprintf("\033[1;31m<error: %s>\033[0m", e.Err.Message)
+}
+
+func (e *ErrorNode) Equal(e2 JSON) bool {
+	if e == nil || e2 == nil {
+		return e == e2
+	}
+
+	other, ok := AsErrorNode(e2)
+	if !ok || other == nil || len(e.Tokens) != len(other.Tokens) {
+		return false
+	}
+
+	for i := range e.Tokens {
+		if !e.Tokens[i].Equal(&other.Tokens[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Span returns the range of the input the skipped tokens come from.
+// For a missing value, it is the empty range where the value was expected.
+func (e *ErrorNode) Span() Span {
+	if len(e.Tokens) > 0 {
+		return tokensSpan(&e.Tokens[0], &e.Tokens[len(e.Tokens)-1])
+	}
+
+	if e.Err == nil {
+		return Span{}
+	}
+
+	start := e.Err.Span().Start
+	return Span{Start: start, End: start}
+}
+
+func (e *ErrorNode) trivia() *Trivia { return &e.Trivia }
+
+// AsErrorNode safely casts a JSON to an *ErrorNode.
+func AsErrorNode(j JSON) (*ErrorNode, bool) {
+	errorNode, ok := j.(*ErrorNode)
+	return errorNode, ok
+}
+
+// ParseTolerant parses the input like Parse, but does not stop at the first error.
+//
+// Whenever the input is malformed, the error is recorded and parsing resumes at the next `,`, `]`
+// or `}` boundary. The parts of the input that could not be parsed are replaced by *ErrorNode
+// placeholders in the returned tree, while properties whose key cannot be read are left out.
+// The errors are returned in the order they were found; the tree is complete and error-free
+// when there are none, and nil when the input holds no value at all.
+func (p *Parser) ParseTolerant() (JSON, []*SyntaxError) {
+	r := recoveringParser{Parser: p}
+
+	l := NewLexer(p.input, p.config)
+	p.reset(l.tolerantTokens(), nil)
+
+	leading := p.ignoreWhitespacesOrComments()
+
+	if p.expectCurToken(EOF) {
+		noContent := newSyntaxError(ErrJSONNoContent, p.curToken, p.config, valueKinds...)
+		noContent.Message = "expected a value"
+		return nil, []*SyntaxError{noContent}
+	}
+
+	root := r.parseValue()
+	trailing := p.ignoreWhitespacesOrComments()
+
+	if !p.expectCurToken(EOF) {
+		if isValueStart(p.curToken) {
+			r.addError(WrapJSONMultipleContentError(p.curToken))
+		} else {
+			r.addError(p.syntaxError(ErrJSONUnexpectedChar, p.curToken, "unexpected character after JSON data", "", EOF))
+		}
+	}
+
+	p.attachTrivia(root, leading, trailing, false)
+
+	return root, r.errs
+}
+
+// recoveringParser is the Parser state used by ParseTolerant.
+type recoveringParser struct {
+	*Parser
+	errs []*SyntaxError
+}
+
+// addError records an error returned by the Parser helpers. A token is only reported once,
+// e.g. the `}` in `[1}` both follows an item and leaves the array unclosed.
+func (r *recoveringParser) addError(err error) *SyntaxError {
+	syntaxErr := err.(*SyntaxError)
+
+	if n := len(r.errs); n > 0 && r.errs[n-1].Offset == syntaxErr.Offset {
+		return r.errs[n-1]
+	}
+
+	r.errs = append(r.errs, syntaxErr)
+
+	return syntaxErr
+}
+
+// parseValue parses the value starting at the current token. Illegal and misplaced tokens are
+// reported, and replaced by an *ErrorNode.
+func (r *recoveringParser) parseValue() JSON {
+	token := r.curToken
+
+	switch token.Kind {
+	case NULL, BOOLEAN, NUMBER:
+		node, _ := r.parse()
+		return node
+	case STRING:
+		node, _ := r.parse()
+		return node
+	case LEFT_SQUARE_BRACE:
+		return r.parseArray()
+	case LEFT_CURLY_BRACE:
+		return r.parseObject()
+	case ILLEGAL:
+		err := r.addError(r.syntaxError(ErrJSONUnexpectedChar, token, "", "", valueKinds...))
+		r.nextToken()
+		return &ErrorNode{Tokens: Tokens{token}, Err: err}
+	default:
+		err := r.addError(r.syntaxError(ErrJSONUnexpectedChar, token, "expected a value", "", valueKinds...))
+		return &ErrorNode{Err: err}
+	}
+}
+
+func (r *recoveringParser) parseArray() JSON {
+	items := []JSON{}
+	openToken := &r.tokens[r.curPos]
+	r.nextToken()
+
+	var closeToken *Token
+	var closing Tokens
+	leading := r.ignoreWhitespacesOrComments()
+	trailingComma := false
+
+	for {
+		if r.expectCurToken(RIGHT_SQUARE_BRACE) {
+			break
+		}
+
+		if r.expectCurToken(EOF) || r.expectCurToken(RIGHT_CURLY_BRACE) {
+			r.addError(r.syntaxError(ErrJSONSyntax, r.curToken, "expected ']' to close the array", "", RIGHT_SQUARE_BRACE))
+			break
+		}
+
+		item := r.parseValue()
+		trailing := r.ignoreWhitespacesOrComments()
+		r.attachTrivia(item, leading, trailing, len(items) > 0)
+		items = append(items, item)
+		leading = nil
+
+		if r.expectCurToken(EOF) || r.expectCurToken(RIGHT_CURLY_BRACE) {
+			// reported as an unclosed array
+			continue
+		}
+
+		if !r.expectCurToken(COMMA) && !r.expectCurToken(RIGHT_SQUARE_BRACE) {
+			err, reported := missingValueError(item, r.curToken)
+			if !reported {
+				err = r.addError(r.syntaxError(ErrJSONSyntax, r.curToken, "expected ',' or ']' after array element", "", COMMA, RIGHT_SQUARE_BRACE))
+			}
+
+			if !isValueStart(r.curToken) {
+				if skipped := r.skipToBoundary(); len(skipped) > 0 {
+					items = append(items, &ErrorNode{Tokens: skipped, Err: err})
+				}
+			}
+
+			if !r.expectCurToken(COMMA) {
+				// the comma is missing, the next item or the end of the array follows
+				continue
+			}
+		}
+
+		if r.expectCurToken(RIGHT_SQUARE_BRACE) {
+			break
+		}
+
+		commaToken := r.curToken
+		r.nextToken()
+		leading = r.ignoreWhitespacesOrComments()
+		r.attachSameLineComments(item, leading)
+
+		if r.expectCurToken(RIGHT_SQUARE_BRACE) {
+			if !r.config.AllowTrailingCommaArray {
+				r.addError(r.syntaxError(ErrJSONSyntax, commaToken, "trailing comma is not allowed in arrays", "AllowTrailingCommaArray", valueKinds...))
+			}
+			closing, leading = leading, nil
+			trailingComma = true
+			break
+		}
+	}
+
+	if len(items) == 0 {
+		closing = leading
+	}
+
+	if r.expectCurToken(RIGHT_SQUARE_BRACE) {
+		closeToken = &r.tokens[r.curPos]
+		r.nextToken()
+	}
+
+	arr := newArray(items, nil)
+	arr.openToken, arr.closeToken = openToken, closeToken
+	arr.trailingComma = trailingComma
+	arr.closing = r.attachClosingTrivia(&arr.Trivia, closing, trailingComma)
+
+	return arr
+}
+
+func (r *recoveringParser) parseObject() JSON {
+	properties := []KeyValue{}
+	openToken := &r.tokens[r.curPos]
+	r.nextToken()
+
+	type occurrence struct {
+		index int
+		token Token
+	}
+	seen := map[string]occurrence{}
+
+	var closeToken *Token
+	var closing Tokens
+	var value JSON
+	keyLeading := r.ignoreWhitespacesOrComments()
+	trailingComma, afterComma := false, false
+
+	for {
+		if r.expectCurToken(RIGHT_CURLY_BRACE) {
+			break
+		}
+
+		if r.expectCurToken(EOF) || r.expectCurToken(RIGHT_SQUARE_BRACE) {
+			r.addError(r.syntaxError(ErrJSONSyntax, r.curToken, "expected '}' to close the object", "", RIGHT_CURLY_BRACE))
+			break
+		}
+
+		value = nil
+		property, ok := r.parseProperty(keyLeading, afterComma)
+
+		if ok {
+			value = property.value
+			keyValue := string(property.key)
+			first, isDuplicate := seen[keyValue]
+
+			switch {
+			case !isDuplicate || r.config.DuplicateKeys == DuplicateKeysKeepAll:
+				seen[keyValue] = occurrence{index: len(properties), token: *property.keyToken}
+				properties = append(properties, property)
+			case r.config.DuplicateKeys == DuplicateKeysError:
+				r.addError(WrapJSONDuplicateKeyError(*property.keyToken, first.token))
+			case r.config.DuplicateKeys == DuplicateKeysLastWins:
+				properties[first.index].value = property.value
+			}
+		}
+
+		if r.expectCurToken(EOF) || r.expectCurToken(RIGHT_SQUARE_BRACE) {
+			// reported as an unclosed object
+			keyLeading = nil
+			continue
+		}
+
+		if !r.expectCurToken(COMMA) && !r.expectCurToken(RIGHT_CURLY_BRACE) {
+			// a property was skipped when it could not be read, its error has been reported already
+			if _, reported := missingValueError(value, r.curToken); ok && !reported {
+				r.addError(r.syntaxError(ErrJSONSyntax, r.curToken, "expected ',' or '}' after property value", "", COMMA, RIGHT_CURLY_BRACE))
+			}
+
+			if !r.expectCurToken(STRING) || !ok {
+				r.skipToBoundary()
+			}
+
+			if !r.expectCurToken(COMMA) {
+				// the comma is missing, the next property or the end of the object follows
+				keyLeading, afterComma = nil, false
+				continue
+			}
+		}
+
+		if r.expectCurToken(RIGHT_CURLY_BRACE) {
+			break
+		}
+
+		commaToken := r.curToken
+		r.nextToken()
+		keyLeading = r.ignoreWhitespacesOrComments()
+		r.attachSameLineComments(value, keyLeading)
+		afterComma = true
+
+		if r.expectCurToken(RIGHT_CURLY_BRACE) {
+			if !r.config.AllowTrailingCommaObject {
+				r.addError(r.syntaxError(ErrJSONSyntax, commaToken, "trailing comma is not allowed in objects", "AllowTrailingCommaObject", STRING))
+			}
+			closing, keyLeading = keyLeading, nil
+			trailingComma = true
+			break
+		}
+	}
+
+	if len(properties) == 0 {
+		closing = keyLeading
+	}
+
+	if r.expectCurToken(RIGHT_CURLY_BRACE) {
+		closeToken = &r.tokens[r.curPos]
+		r.nextToken()
+	}
+
+	obj := newObject(properties, nil)
+	obj.openToken, obj.closeToken = openToken, closeToken
+	obj.trailingComma = trailingComma
+	obj.closing = r.attachClosingTrivia(&obj.Trivia, closing, trailingComma)
+
+	return obj
+}
+
+// parseProperty parses a key, its colon and its value. It reports false when the key cannot be
+// read, leaving the current token on the offending token.
+func (r *recoveringParser) parseProperty(keyLeading Tokens, afterComma bool) (KeyValue, bool) {
+	keyToken := r.curToken
+
+	if keyToken.Kind != STRING {
+		message := "expected property name"
+		if keyToken.Kind == ILLEGAL {
+			message = ""
+		}
+
+		r.addError(r.syntaxError(ErrJSONSyntax, keyToken, message, "", STRING))
+		return KeyValue{}, false
+	}
+
+	keyString, _ := r.parse()
+	key, err := keyString.(*String).Value()
+	if err != nil {
+		r.addError(r.syntaxError(ErrJSONSyntax, keyToken, "bad escape in property name", "", STRING))
+		key = string(keyToken.Literal)
+	}
+
+	property := KeyValue{key: []byte(key), keyToken: keyString.(*String).Token}
+	keyTrailing := r.ignoreWhitespacesOrComments()
+	r.attach(&property.keyTrivia, keyLeading, keyTrailing, afterComma)
+
+	if r.expectCurToken(COLON) {
+		r.nextToken()
+	} else {
+		err := r.addError(r.syntaxError(ErrJSONSyntax, r.curToken, "expected ':' after property name", "", COLON))
+
+		if !isValueStart(r.curToken) {
+			// the value is missing as well
+			property.value = &ErrorNode{Err: err}
+			return property, true
+		}
+	}
+
+	valueLeading := r.ignoreWhitespacesOrComments()
+
+	// as with Parse, unquoted strings are accepted anywhere but as property values
+	if token := r.curToken; token.Kind == STRING && token.SubKind == IDENT {
+		err := r.addError(r.syntaxError(ErrJSONSyntax, token, "unquoted strings are only allowed as object keys", "", valueKinds...))
+		r.nextToken()
+		property.value = &ErrorNode{Tokens: Tokens{token}, Err: err}
+	} else {
+		property.value = r.parseValue()
+	}
+	valueTrailing := r.ignoreWhitespacesOrComments()
+	r.attachTrivia(property.value, valueLeading, valueTrailing, false)
+
+	return property, true
+}
+
+// skipToBoundary skips tokens up to the next `,`, `]`, `}` or the end of the input, along with any
+// array or object they open, and returns the skipped tokens without the trailing whitespace and comments.
+func (r *recoveringParser) skipToBoundary() Tokens {
+	start := r.curPos
+	end := start
+	depth := 0
+
+	for !r.expectCurToken(EOF) {
+		switch r.curToken.Kind {
+		case LEFT_SQUARE_BRACE, LEFT_CURLY_BRACE:
+			depth++
+		case RIGHT_SQUARE_BRACE, RIGHT_CURLY_BRACE:
+			if depth == 0 {
+				return r.tokens[start:end]
+			}
+			depth--
+		case COMMA:
+			if depth == 0 {
+				return r.tokens[start:end]
+			}
+		}
+
+		if r.curToken.Kind != WHITESPACE && r.curToken.Kind != COMMENT {
+			end = r.curPos + 1
+		}
+
+		r.nextToken()
+	}
+
+	return r.tokens[start:end]
+}
+
+// missingValueError returns the error of a missing value, when it has been reported at token already.
+func missingValueError(node JSON, token Token) (*SyntaxError, bool) {
+	errorNode, ok := node.(*ErrorNode)
+	if !ok || len(errorNode.Tokens) > 0 || errorNode.Err == nil {
+		return nil, false
+	}
+
+	return errorNode.Err, errorNode.Err.Offset == token.Offset
+}
+
+// isValueStart reports whether a value can start with token.
+func isValueStart(token Token) bool {
+	switch token.Kind {
+	case NULL, BOOLEAN, STRING, NUMBER, LEFT_SQUARE_BRACE, LEFT_CURLY_BRACE:
+		return true
+	default:
+		return false
+	}
+}
+
+// recoveryLength returns the number of bytes of an ILLEGAL token, whose literal runs up to the end
+// of the input, that the lexer skips before resuming: a malformed string up to its closing quote
+// or to the end of its line, a disallowed comment as a whole, and the malformed text otherwise.
+func recoveryLength(token Token, cfg *ParserConfig) int {
+	literal := token.Literal
+
+	switch token.SubKind {
+	case INVALID_STRING, INVALID_HEX_STRING, INVALID_NEWLINE_STRING, INVALID_ESCAPED_STRING:
+		for i := 1; i < len(literal); i++ {
+			switch literal[i] {
+			case '\\':
+				i++
+			case literal[0]:
+				return i + 1
+			case '\n', '\r':
+				return i
+			}
+		}
+		return len(literal)
+	case INVALID_LINE_COMMENT:
+		if index := bytes.IndexAny(literal, "\r\n"); index >= 0 {
+			return index
+		}
+		return len(literal)
+	case INVALID_BLOCK_COMMENT:
+		if index := bytes.Index(literal[min(2, len(literal)):], []byte("*/")); index >= 0 {
+			return index + 4
+		}
+		return len(literal)
+	default:
+		length := newSyntaxError(ErrJSONUnexpectedChar, token, cfg).length
+		return max(min(length, len(literal)), len(firstRune(literal)), 1)
+	}
+}
@@ -0,0 +1,168 @@
+package jsonvx
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseTolerant(t *testing.T) {
+	var tests = []struct {
+		msg               string
+		input             []byte
+		cfg               *ParserConfig
+		expectedShape     string
+		expectedPositions []string
+		expectedMessages  []string
+	}{
+		{msg: "Valid input", input: []byte(`{"a": [1, true, null], "b": "c"}`), expectedShape: `{"a":[1,true,null],"b":"c"}`},
+		{msg: "Missing comma in array", input: []byte(`[1 2, 3]`), expectedShape: `[1,2,3]`, expectedPositions: []string{"1:4"}, expectedMessages: []string{"expected ',' or ']' after array element"}},
+		{msg: "Missing value in array", input: []byte(`[1,,2]`), expectedShape: `[1,!,2]`, expectedPositions: []string{"1:4"}, expectedMessages: []string{"expected a value"}},
+		{msg: "Misplaced colon in array", input: []byte(`[1 : 2, 3]`), expectedShape: `[1,!,3]`, expectedPositions: []string{"1:4"}, expectedMessages: []string{"expected ',' or ']' after array element"}},
+		{msg: "Illegal values in array", input: []byte("[.5, +1,\n 0x1, \"\\q\", 4]"), expectedShape: `[!,!,!,!,4]`, expectedPositions: []string{"1:2", "1:6", "2:2", "2:7"}, expectedMessages: []string{"missing digits before decimal point", "numbers cannot start with '+'", "hexadecimal numbers are not allowed", `bad escape character \q`}},
+		{msg: "Unterminated string", input: []byte("[\"abc\n, 2]"), expectedShape: `[!,2]`, expectedPositions: []string{"1:2"}, expectedMessages: []string{"unterminated string literal"}},
+		{msg: "Repeated illegal characters", input: []byte(`[@@@, 1]`), expectedShape: `[!,!,1]`, expectedPositions: []string{"1:2", "1:3"}, expectedMessages: []string{"unexpected character", "expected ',' or ']' after array element"}},
+		{msg: "Trailing comma in array", input: []byte(`[1, 2,]`), expectedShape: `[1,2]`, expectedPositions: []string{"1:6"}, expectedMessages: []string{"trailing comma is not allowed in arrays"}},
+		{msg: "Unclosed array", input: []byte(`[1, 2`), expectedShape: `[1,2]`, expectedPositions: []string{"1:6"}, expectedMessages: []string{"expected ']' to close the array"}},
+		{msg: "Mismatched bracket", input: []byte(`[1}`), expectedShape: `[1]`, expectedPositions: []string{"1:3"}, expectedMessages: []string{"expected ']' to close the array"}},
+		{msg: "Missing comma in object", input: []byte(`{"a": 1 "b": 2}`), expectedShape: `{"a":1,"b":2}`, expectedPositions: []string{"1:9"}, expectedMessages: []string{"expected ',' or '}' after property value"}},
+		{msg: "Missing colon", input: []byte(`{"a" 1, "b": 2}`), expectedShape: `{"a":1,"b":2}`, expectedPositions: []string{"1:6"}, expectedMessages: []string{"expected ':' after property name"}},
+		{msg: "Missing colon and value", input: []byte(`{"a", "b": 2}`), expectedShape: `{"a":!,"b":2}`, expectedPositions: []string{"1:5"}, expectedMessages: []string{"expected ':' after property name"}},
+		{msg: "Repeated colon", input: []byte(`{"a":: 1}`), expectedShape: `{"a":!}`, expectedPositions: []string{"1:6"}, expectedMessages: []string{"expected a value"}},
+		{msg: "Invalid key", input: []byte(`{1: 2, "a": 3}`), expectedShape: `{"a":3}`, expectedPositions: []string{"1:2"}, expectedMessages: []string{"expected property name"}},
+		{msg: "Unquoted key and value", input: []byte(`{a: b}`), expectedShape: `{}`, expectedPositions: []string{"1:2"}, expectedMessages: []string{"strings must be quoted"}},
+		{msg: "Unquoted value", input: []byte(`{"a": b}`), cfg: NewParserConfig(WithAllowUnquoted(true)), expectedShape: `{"a":!}`, expectedPositions: []string{"1:7"}, expectedMessages: []string{"unquoted strings are only allowed as object keys"}},
+		{msg: "Errors in nested containers", input: []byte("{\"a\": [1, 2, \"b\": 3],\n \"c\": {\"d\" 01}}"), expectedShape: `{"a":[1,2,"b",!],"c":{"d":!}}`, expectedPositions: []string{"1:17", "2:12"}, expectedMessages: []string{"expected ',' or ']' after array element", "numbers cannot have leading zeros"}},
+		{msg: "Mismatched bracket in object", input: []byte(`{"a": [1, 2}, "b": 3`), expectedShape: `{"a":[1,2]}`, expectedPositions: []string{"1:12", "1:13"}, expectedMessages: []string{"expected ']' to close the array", "unexpected character after JSON data"}},
+		{msg: "Duplicate key", input: []byte(`{"a": 1, "a": 2, "b": 3}`), cfg: NewParserConfig(WithDuplicateKeys(DuplicateKeysError)), expectedShape: `{"a":1,"b":3}`, expectedPositions: []string{"1:10"}, expectedMessages: []string{""}},
+		{msg: "Multiple values", input: []byte("1\n2"), expectedShape: `1`, expectedPositions: []string{"2:1"}, expectedMessages: []string{""}},
+		{msg: "No content", input: []byte(" \n "), expectedShape: ``, expectedPositions: []string{"2:2"}, expectedMessages: []string{"expected a value"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			parser := NewParser(test.input, test.cfg)
+			node, errs := parser.ParseTolerant()
+
+			if got := recoveredShape(node); got != test.expectedShape {
+				t.Errorf("got shape %s, expected %s", got, test.expectedShape)
+			}
+
+			var positions, messages []string
+			for _, err := range errs {
+				positions = append(positions, err.Span().Start.String())
+				messages = append(messages, err.Message)
+			}
+
+			if !slices.Equal(positions, test.expectedPositions) {
+				t.Errorf("got error positions %v, expected %v", positions, test.expectedPositions)
+			}
+
+			if !slices.Equal(messages, test.expectedMessages) {
+				t.Errorf("got error messages %q, expected %q", messages, test.expectedMessages)
+			}
+		})
+	}
+}
+
+func TestParseTolerantMatchesParse(t *testing.T) {
+	var tests = []struct {
+		msg   string
+		input []byte
+		cfg   *ParserConfig
+	}{
+		{msg: "Scalar", input: []byte(` "abc" `)},
+		{msg: "Nested containers", input: []byte(`{"a": [1, {"b": null}], "c": {}}`)},
+		{msg: "Relaxed syntax", input: []byte("{a: 'b', /* c */ \"d\": [0x1, .5,],}"), cfg: NewParserConfig(WithAllowUnquoted(true), WithAllowSingleQuotes(true), WithAllowBlockComments(true), WithAllowHexNumbers(true), WithAllowPointEdgeNumbers(true), WithAllowTrailingCommaArray(true), WithAllowTrailingCommaObject(true))},
+		{msg: "Top-level unquoted string", input: []byte(` abc // c`), cfg: JSON5Config()},
+		{msg: "Unquoted string item", input: []byte(`[abc, {d: [e]}]`), cfg: JSON5Config()},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			parser := NewParser(test.input, test.cfg)
+			expected, err := parser.Parse()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			tolerant := NewParser(test.input, test.cfg)
+			got, errs := tolerant.ParseTolerant()
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			if !got.Equal(expected) {
+				t.Errorf("got %s, expected %s", got, expected)
+			}
+		})
+	}
+}
+
+func TestParseTolerantErrorNode(t *testing.T) {
+	parser := NewParser([]byte(`[1, 0x1 2, 3]`), nil)
+	node, errs := parser.ParseTolerant()
+
+	if len(errs) != 2 {
+		t.Fatalf("got %d errors, expected 2", len(errs))
+	}
+
+	if !errors.Is(errs[0], ErrJSONUnexpectedChar) || errs[0].Code != INVALID_HEX_NUMBER {
+		t.Errorf("got %v, expected an invalid hex number error", errs[0])
+	}
+
+	arr, _ := AsArray(node)
+	errorNode, ok := AsErrorNode(arr.Items[1])
+	if !ok {
+		t.Fatalf("got %T, expected *ErrorNode", arr.Items[1])
+	}
+
+	if errorNode.Err != errs[0] {
+		t.Errorf("got error %v, expected %v", errorNode.Err, errs[0])
+	}
+
+	if got := errorNode.Span().String(); got != "1:5-1:8" {
+		t.Errorf("got span %s, expected 1:5-1:8", got)
+	}
+}
+
+// recoveredShape renders a tree compactly, error nodes being written as `!`.
+func recoveredShape(node JSON) string {
+	var builder strings.Builder
+
+	var write func(node JSON)
+	write = func(node JSON) {
+		switch val := node.(type) {
+		case *Array:
+			builder.WriteByte('[')
+			for i, item := range val.Items {
+				if i > 0 {
+					builder.WriteByte(',')
+				}
+				write(item)
+			}
+			builder.WriteByte(']')
+		case *Object:
+			builder.WriteByte('{')
+			for i, prop := range val.Properties {
+				if i > 0 {
+					builder.WriteByte(',')
+				}
+				builder.WriteString(`"` + string(prop.key) + `":`)
+				write(prop.value)
+			}
+			builder.WriteByte('}')
+		case *ErrorNode:
+			builder.WriteByte('!')
+		case nil:
+		default:
+			var buf strings.Builder
+			Print(&buf, node)
+			builder.WriteString(strings.TrimSpace(buf.String()))
+		}
+	}
+
+	write(node)
+	return builder.String()
+}
@@ -0,0 +1,222 @@
+package jsonvx
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"iter"
+)
+
+// ErrJSONRecord is the error for a record of a JSON sequence that is not framed as its format requires.
+var ErrJSONRecord = errors.New("malformed JSON record")
+
+// recordSeparator is the byte starting every record of a JSON text sequence (RFC 7464).
+const recordSeparator = 0x1E
+
+// SequenceFormat is the framing of the values of a multi-document input.
+type SequenceFormat int
+
+const (
+	SequenceConcatenated SequenceFormat = iota // SequenceConcatenated reads values one after the other, separated by optional whitespace and comments (e.g., `{"a":1}{"a":2}`).
+	SequenceJSONLines                          // SequenceJSONLines reads one value per line, as in JSON Lines and NDJSON, blank lines being skipped.
+	SequenceJSONText                           // SequenceJSONText reads RFC 7464 JSON text sequences, each value being preceded by a record separator (0x1E).
+)
+
+// String returns a string representation of the SequenceFormat.
+func (f SequenceFormat) String() string {
+	m := map[SequenceFormat]string{
+		SequenceConcatenated: "SequenceConcatenated",
+		SequenceJSONLines:    "SequenceJSONLines",
+		SequenceJSONText:     "SequenceJSONText",
+	}
+
+	if str, ok := m[f]; ok {
+		return str
+	}
+	return "UNKNOWN"
+}
+
+// RecordError is the error for a value of a multi-document input that could not be read.
+type RecordError struct {
+	Record int   // Record is the number of the record, starting from 1.
+	Line   int   // Line is the line the record starts on.
+	Err    error // Err is the underlying error, usually a *SyntaxError.
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("record %d at line %d: %v", e.Record, e.Line, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// Values returns an iterator over the top-level values of the input, framed according to format.
+//
+// A value failing to parse is yielded as a nil JSON along with a *RecordError. With SequenceJSONLines
+// and SequenceJSONText, reading then resumes at the next line or record separator; with
+// SequenceConcatenated, where there is no way to tell where the next value starts, the iteration stops.
+func (p *Parser) Values(format SequenceFormat) iter.Seq2[JSON, error] {
+	return NewDecoder(bytes.NewReader(p.input), p.config).Values(format)
+}
+
+// Values returns an iterator over the top-level values read from the input, framed according to format,
+// like Parser.Values. Each value is yielded as soon as its last token has been read, so records can be
+// processed as they arrive, e.g. from a pipe. An error returned by the reader ends the iteration.
+func (d *Decoder) Values(format SequenceFormat) iter.Seq2[JSON, error] {
+	return func(yield func(JSON, error) bool) {
+		s := sequenceState{Decoder: d, format: format}
+
+		p := &d.parser
+		p.reset(nil, d.token)
+		// a top-level number, boolean or null must be followed by whitespace in JSON text sequences
+		p.dropSpaces = !d.config.PreserveTrivia && format != SequenceJSONText
+		defer p.reset(nil, nil)
+
+		for {
+			node, more, err := s.next()
+
+			if !more {
+				if d.err != nil {
+					yield(nil, d.err)
+				}
+				return
+			}
+
+			if d.err != nil {
+				yield(nil, d.err)
+				return
+			}
+
+			if err != nil {
+				err = &RecordError{Record: s.record, Line: s.line, Err: err}
+			}
+
+			if !yield(node, err) {
+				return
+			}
+
+			if err != nil {
+				if format == SequenceConcatenated {
+					return
+				}
+				s.skipRecord()
+			}
+		}
+	}
+}
+
+// sequenceState is the Decoder state used by Values.
+type sequenceState struct {
+	*Decoder
+	format SequenceFormat
+	record int // record is the number of the current record.
+	line   int // line is the line the current record starts on.
+}
+
+// next reads the next record, it reports false once the input is exhausted.
+func (s *sequenceState) next() (JSON, bool, error) {
+	p := &s.parser
+	p.discard()
+
+	leading := p.ignoreWhitespacesOrComments()
+
+	if s.format == SequenceJSONText {
+		if !isRecordSeparator(p.curToken) && !p.expectCurToken(EOF) {
+			s.record, s.line = s.record+1, p.curToken.Line
+			return nil, true, s.recordError(p.curToken, "expected a record separator before the value")
+		}
+
+		// empty records are skipped
+		for isRecordSeparator(p.curToken) {
+			p.nextToken()
+			leading = p.ignoreWhitespacesOrComments()
+		}
+	}
+
+	if p.expectCurToken(EOF) {
+		return nil, false, nil
+	}
+
+	s.record, s.line = s.record+1, p.curToken.Line
+
+	if s.format == SequenceJSONLines && s.record > 1 && !containsNewline(leading) {
+		return nil, true, s.recordError(p.curToken, "expected a newline before the value")
+	}
+
+	node, err := p.parse()
+	if err != nil {
+		var syntaxErr *SyntaxError
+		if s.format == SequenceJSONText && errors.As(err, &syntaxErr) && isRecordSeparator(syntaxErr.Token) {
+			return nil, true, s.recordError(syntaxErr.Token, "unexpected record separator, the record is truncated")
+		}
+		return nil, true, err
+	}
+
+	switch s.format {
+	case SequenceJSONLines:
+		if span := node.Span(); span.Start.Line != span.End.Line {
+			return nil, true, s.recordError(p.tokens[p.curPos-1], "value must fit on a single line")
+		}
+	case SequenceJSONText:
+		// a top-level number, boolean or null cut short could be mistaken for a valid one
+		if isScalar(node) && !p.expectCurToken(WHITESPACE) {
+			return nil, true, s.recordError(p.curToken, "expected whitespace after the value, the record may be truncated")
+		}
+	}
+
+	p.attachTrivia(node, leading, nil, false)
+
+	return node, true, nil
+}
+
+// skipRecord skips the tokens up to the start of the next record, after an error.
+func (s *sequenceState) skipRecord() {
+	p := &s.parser
+
+	for !p.expectCurToken(EOF) {
+		switch {
+		case s.format == SequenceJSONLines && p.expectCurToken(WHITESPACE) && containsNewline(Tokens{p.curToken}):
+			return
+		case s.format == SequenceJSONText && isRecordSeparator(p.curToken):
+			return
+		}
+
+		p.nextToken()
+	}
+}
+
+// recordError returns a *SyntaxError under ErrJSONRecord, for a record not framed as its format requires.
+func (s *sequenceState) recordError(token Token, message string) error {
+	err := newSyntaxError(ErrJSONRecord, token, s.config)
+	err.Message, err.Hint = message, ""
+	err.length = min(err.length, len(firstRune(token.Literal)))
+
+	return err
+}
+
+// isRecordSeparator reports whether token is the record separator of JSON text sequences.
+func isRecordSeparator(token Token) bool {
+	return token.Kind == ILLEGAL && token.SubKind == INVALID_CHARACTER && len(token.Literal) > 0 && token.Literal[0] == recordSeparator
+}
+
+// isScalar reports whether node is a number, a boolean or null.
+func isScalar(node JSON) bool {
+	switch node.(type) {
+	case *Number, *Boolean, *Null:
+		return true
+	default:
+		return false
+	}
+}
+
+// containsNewline reports whether a line feed appears among the whitespace tokens.
+func containsNewline(tokens Tokens) bool {
+	for _, token := range tokens {
+		if token.Kind == WHITESPACE && bytes.IndexByte(token.Literal, '\n') >= 0 {
+			return true
+		}
+	}
+
+	return false
+}
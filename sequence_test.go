@@ -0,0 +1,158 @@
+package jsonvx
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+func TestValues(t *testing.T) {
+	var tests = []struct {
+		msg            string
+		format         SequenceFormat
+		input          string
+		cfg            *ParserConfig
+		expectedValues []string
+		expectedErrors []string
+	}{
+		{msg: "Concatenated values", format: SequenceConcatenated, input: "{\"a\":1}{\"a\":2} 3\n[4]\"x\"truefalse", expectedValues: []string{`{"a":1}`, `{"a":2}`, `3`, `[4]`, `"x"`, `true`, `false`}},
+		{msg: "Concatenated values with comments", format: SequenceConcatenated, input: "// first\n1 /* second */ 2", cfg: NewParserConfig(WithAllowLineComments(true), WithAllowBlockComments(true)), expectedValues: []string{`1`, `2`}},
+		{msg: "Concatenated values stop at an error", format: SequenceConcatenated, input: "1 2 ] 3", expectedValues: []string{`1`, `2`}, expectedErrors: []string{"3:1 expected a value"}},
+		{msg: "Empty input", format: SequenceConcatenated, input: " \n "},
+		{msg: "JSON Lines", format: SequenceJSONLines, input: "{\"a\":1}\n\n{\"a\":2}\r\n[3]\n", expectedValues: []string{`{"a":1}`, `{"a":2}`, `[3]`}},
+		{msg: "JSON Lines without final newline", format: SequenceJSONLines, input: "1\n2", expectedValues: []string{`1`, `2`}},
+		{msg: "JSON Lines with two values on a line", format: SequenceJSONLines, input: "1\n2 3\n4", expectedValues: []string{`1`, `2`, `4`}, expectedErrors: []string{"3:2 expected a newline before the value"}},
+		{msg: "JSON Lines with a value on several lines", format: SequenceJSONLines, input: "[1,\n2]\n3", expectedValues: []string{`3`}, expectedErrors: []string{"1:1 value must fit on a single line"}},
+		{msg: "JSON Lines with malformed lines", format: SequenceJSONLines, input: "{\"a\": 01}\n@@\n{\"b\": [1 2]}\n{\"ok\": true}", expectedValues: []string{`{"ok":true}`}, expectedErrors: []string{"1:1 numbers cannot have leading zeros", "2:2 unexpected character", "3:3 expected ',' or ']' after array element"}},
+		{msg: "JSON text sequence", format: SequenceJSONText, input: "\x1e{\"a\":1}\n\x1e\x1e[2]\n\x1e3\n", expectedValues: []string{`{"a":1}`, `[2]`, `3`}},
+		{msg: "JSON text sequence with truncated records", format: SequenceJSONText, input: "\x1e1\x1e{\"a\":\x1e2\n", expectedValues: []string{`2`}, expectedErrors: []string{"1:1 expected whitespace after the value, the record may be truncated", "2:1 unexpected record separator, the record is truncated"}},
+		{msg: "JSON text sequence without record separator", format: SequenceJSONText, input: "1\n\x1e2\n", expectedValues: []string{`2`}, expectedErrors: []string{"1:1 expected a record separator before the value"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			parser := NewParser([]byte(test.input), test.cfg)
+
+			var values, errs []string
+			for node, err := range parser.Values(test.format) {
+				if err == nil {
+					values = append(values, recoveredShape(node))
+					continue
+				}
+
+				if node != nil {
+					t.Errorf("got %s along with error %v, expected nil", node, err)
+				}
+
+				var recordErr *RecordError
+				var syntaxErr *SyntaxError
+				if !errors.As(err, &recordErr) || !errors.As(err, &syntaxErr) {
+					t.Fatalf("got %T, expected a *RecordError wrapping a *SyntaxError", err)
+				}
+				errs = append(errs, fmt.Sprintf("%d:%d %s", recordErr.Record, recordErr.Line, syntaxErr.Message))
+			}
+
+			if !slices.Equal(values, test.expectedValues) {
+				t.Errorf("got values %v, expected %v", values, test.expectedValues)
+			}
+
+			if !slices.Equal(errs, test.expectedErrors) {
+				t.Errorf("got errors %q, expected %q", errs, test.expectedErrors)
+			}
+		})
+	}
+}
+
+func TestValuesRecordPositions(t *testing.T) {
+	input := "{\"a\": 1}\n{\"a\": 2}\n{\"a\": 0x3}\n"
+
+	var errs []error
+	for _, err := range NewDecoder(iotest.OneByteReader(strings.NewReader(input)), nil).Values(SequenceJSONLines) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) != 1 {
+		t.Fatalf("got %d errors, expected 1", len(errs))
+	}
+
+	var syntaxErr *SyntaxError
+	if !errors.As(errs[0], &syntaxErr) || !errors.Is(errs[0], ErrJSONUnexpectedChar) {
+		t.Fatalf("got %v, expected an unexpected character error", errs[0])
+	}
+
+	if syntaxErr.Line != 3 || syntaxErr.Column != 7 || syntaxErr.Offset != 24 {
+		t.Errorf("got position %d:%d (%d), expected 3:7 (24)", syntaxErr.Line, syntaxErr.Column, syntaxErr.Offset)
+	}
+
+	expected := "record 3 at line 3: " + syntaxErr.Error()
+	if errs[0].Error() != expected {
+		t.Errorf("got %q, expected %q", errs[0].Error(), expected)
+	}
+}
+
+func TestValuesReaderError(t *testing.T) {
+	readErr := errors.New("connection reset")
+	reader := io.MultiReader(strings.NewReader("1\n2\n"), iotest.ErrReader(readErr))
+
+	var values []string
+	var err error
+	for node, nodeErr := range NewDecoder(reader, nil).Values(SequenceJSONLines) {
+		if nodeErr != nil {
+			err = nodeErr
+			continue
+		}
+		values = append(values, recoveredShape(node))
+	}
+
+	if !slices.Equal(values, []string{"1", "2"}) {
+		t.Errorf("got values %v, expected [1 2]", values)
+	}
+
+	if !errors.Is(err, readErr) {
+		t.Errorf("got %v, expected %v", err, readErr)
+	}
+}
+
+func TestValuesFromPipe(t *testing.T) {
+	reader, writer := io.Pipe()
+
+	next, stop := iter.Pull2(NewDecoder(reader, nil).Values(SequenceJSONLines))
+	defer stop()
+	defer reader.Close()
+
+	records := []string{"{\"id\": 1}\n", "{\"id\": 2}\n"}
+
+	for i, record := range records {
+		go writer.Write([]byte(record))
+
+		// the record must be yielded before the next one is written
+		done := make(chan string, 1)
+		go func() {
+			node, err, _ := next()
+			if err != nil {
+				done <- err.Error()
+				return
+			}
+			done <- recoveredShape(node)
+		}()
+
+		select {
+		case got := <-done:
+			if expected := fmt.Sprintf(`{"id":%d}`, i+1); got != expected {
+				t.Errorf("got %s, expected %s", got, expected)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("record %d was not yielded before the next one was written", i+1)
+		}
+	}
+
+	writer.Close()
+}
@@ -0,0 +1,271 @@
+package jsonvx
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ANSI escape sequences used by colored snippets.
+const (
+	ansiReset = "\033[0m"
+	ansiBold  = "\033[1m"
+	ansiRed   = "\033[31m"
+	ansiBlue  = "\033[34m"
+	ansiCyan  = "\033[36m"
+)
+
+// SnippetConfig defines how FormatError renders the source snippet of a parse error.
+type SnippetConfig struct {
+	ContextLines int  // ContextLines is the number of lines shown before and after the offending line.
+	TabWidth     int  // TabWidth is the number of columns between tab stops, used to expand tabs.
+	Color        bool // Color highlights the output with ANSI escape sequences, for terminals.
+}
+
+// NewSnippetConfig creates a new SnippetConfig instance, optionally applying one or more configuration options.
+// By default, 2 lines of context are shown, tabs are expanded to 4 columns and the output is plain text.
+func NewSnippetConfig(opts ...func(*SnippetConfig)) *SnippetConfig {
+	cfg := &SnippetConfig{ContextLines: 2, TabWidth: 4}
+
+	for _, o := range opts {
+		o(cfg)
+	}
+
+	return cfg
+}
+
+// WithContextLines is the functional option setter for the number of context lines.
+func WithContextLines(lines int) func(*SnippetConfig) {
+	return func(c *SnippetConfig) {
+		c.ContextLines = lines
+	}
+}
+
+// WithTabWidth is the functional option setter for the tab width.
+func WithTabWidth(width int) func(*SnippetConfig) {
+	return func(c *SnippetConfig) {
+		c.TabWidth = width
+	}
+}
+
+// WithColor is the functional option setter for the Color flag.
+func WithColor(color bool) func(*SnippetConfig) {
+	return func(c *SnippetConfig) {
+		c.Color = color
+	}
+}
+
+// FormatError renders a parse error along with the lines of input around it, the offending text
+// being underlined with `^~~~`:
+//
+//	error: unexpected character in JSON input: missing digits before decimal point
+//	 --> 2:3
+//	  |
+//	1 | [
+//	2 |   .5
+//	  |   ^~
+//	3 | ]
+//	  = hint: enable ParserConfig.AllowPointEdgeNumbers to accept it
+//
+// Tabs are expanded and multi-byte characters are measured by their display width, so the underline
+// lines up with the text. Errors that carry no position, i.e. that are not a *SyntaxError, are rendered
+// on a single line. A nil configuration uses the defaults of NewSnippetConfig.
+func FormatError(err error, input []byte, cfg *SnippetConfig) string {
+	if err == nil {
+		return ""
+	}
+
+	if cfg == nil {
+		cfg = NewSnippetConfig()
+	}
+
+	s := snippetState{config: cfg}
+
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		s.writeHeader(err.Error())
+		return s.buf.String()
+	}
+
+	header := syntaxErr.Err.Error()
+	if syntaxErr.Message != "" {
+		header += ": " + syntaxErr.Message
+	}
+
+	span := syntaxErr.Span()
+	lines := bytes.Split(input, []byte("\n"))
+
+	// the offending line, as a 0-based index, clamped to the input
+	line := min(max(span.Start.Line-1, 0), len(lines)-1)
+	first := max(line-cfg.ContextLines, 0)
+	last := min(line+cfg.ContextLines, len(lines)-1)
+	width := len(strconv.Itoa(last + 1))
+
+	s.writeHeader(header)
+	s.writeLocation(width, span.Start)
+	s.writeGutter(width, "")
+	s.buf.WriteByte('\n')
+
+	lineStart := lineOffset(input, span.Start.Offset)
+
+	for i := first; i <= last; i++ {
+		text := bytes.TrimSuffix(lines[i], []byte("\r"))
+
+		s.writeGutter(width, strconv.Itoa(i+1))
+		if len(text) > 0 {
+			s.buf.WriteByte(' ')
+			s.buf.WriteString(s.expandTabs(text))
+		}
+		s.buf.WriteByte('\n')
+
+		if i == line {
+			start := min(max(span.Start.Offset-lineStart, 0), len(text))
+			end := len(text)
+			if span.End.Line == span.Start.Line {
+				end = min(max(span.End.Offset-lineStart, start), len(text))
+			}
+
+			s.writeUnderline(width, text, start, end)
+		}
+	}
+
+	if syntaxErr.Hint != "" {
+		s.buf.WriteString(strings.Repeat(" ", width+1))
+		s.colorize(ansiCyan, "= hint: ")
+		s.buf.WriteString(syntaxErr.Hint)
+		s.buf.WriteByte('\n')
+	}
+
+	return s.buf.String()
+}
+
+// snippetState holds the output of a single FormatError call.
+type snippetState struct {
+	buf    bytes.Buffer
+	config *SnippetConfig
+}
+
+func (s *snippetState) writeHeader(message string) {
+	s.colorize(ansiBold+ansiRed, "error")
+	s.colorize(ansiBold, ": "+message)
+	s.buf.WriteByte('\n')
+}
+
+func (s *snippetState) writeLocation(width int, start Position) {
+	s.buf.WriteString(strings.Repeat(" ", width))
+	s.colorize(ansiBlue, "--> ")
+	s.buf.WriteString(start.String())
+	s.buf.WriteByte('\n')
+}
+
+// writeGutter writes the line number column, label being right-aligned on width columns.
+func (s *snippetState) writeGutter(width int, label string) {
+	s.colorize(ansiBlue, fmt.Sprintf("%*s |", width, label))
+}
+
+// writeUnderline writes the `^~~~` line under the bytes text[start:end].
+func (s *snippetState) writeUnderline(width int, text []byte, start, end int) {
+	offset := s.visualWidth(text[:start], 0)
+	length := max(s.visualWidth(text[start:end], offset), 1)
+
+	s.writeGutter(width, "")
+	s.buf.WriteByte(' ')
+	s.buf.WriteString(strings.Repeat(" ", offset))
+	s.colorize(ansiBold+ansiRed, "^"+strings.Repeat("~", length-1))
+	s.buf.WriteByte('\n')
+}
+
+// colorize writes text, wrapped in the given ANSI escape sequence when Color is enabled.
+func (s *snippetState) colorize(code, text string) {
+	if !s.config.Color {
+		s.buf.WriteString(text)
+		return
+	}
+
+	s.buf.WriteString(code)
+	s.buf.WriteString(text)
+	s.buf.WriteString(ansiReset)
+}
+
+// expandTabs replaces the tabs of text with spaces, up to the next tab stop.
+func (s *snippetState) expandTabs(text []byte) string {
+	var builder strings.Builder
+	column := 0
+
+	for len(text) > 0 {
+		r, size := utf8.DecodeRune(text)
+
+		if r == '\t' {
+			spaces := s.tabSize(column)
+			builder.WriteString(strings.Repeat(" ", spaces))
+			column += spaces
+		} else {
+			builder.Write(text[:size])
+			column += runeWidth(r)
+		}
+
+		text = text[size:]
+	}
+
+	return builder.String()
+}
+
+// visualWidth returns the number of columns text takes up on screen, starting at the given column.
+func (s *snippetState) visualWidth(text []byte, column int) int {
+	width := 0
+
+	for len(text) > 0 {
+		r, size := utf8.DecodeRune(text)
+
+		if r == '\t' {
+			width += s.tabSize(column + width)
+		} else {
+			width += runeWidth(r)
+		}
+
+		text = text[size:]
+	}
+
+	return width
+}
+
+// tabSize returns the number of columns a tab at the given column takes up.
+func (s *snippetState) tabSize(column int) int {
+	if s.config.TabWidth <= 0 {
+		return 1
+	}
+
+	return s.config.TabWidth - column%s.config.TabWidth
+}
+
+// runeWidth returns the number of columns r takes up in a monospaced terminal: 0 for combining
+// marks, 2 for wide East Asian characters and emoji, and 1 otherwise.
+func runeWidth(r rune) int {
+	switch {
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	case r >= 0x1100 && r <= 0x115F,
+		r >= 0x2E80 && r <= 0xA4CF && r != 0x303F,
+		r >= 0xAC00 && r <= 0xD7A3,
+		r >= 0xF900 && r <= 0xFAFF,
+		r >= 0xFE30 && r <= 0xFE4F,
+		r >= 0xFF00 && r <= 0xFF60,
+		r >= 0xFFE0 && r <= 0xFFE6,
+		r >= 0x1F300 && r <= 0x1F64F,
+		r >= 0x1F900 && r <= 0x1F9FF,
+		r >= 0x20000 && r <= 0x3FFFD:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// lineOffset returns the byte offset of the start of the line holding the given offset.
+func lineOffset(input []byte, offset int) int {
+	offset = min(max(offset, 0), len(input))
+	return bytes.LastIndexByte(input[:offset], '\n') + 1
+}
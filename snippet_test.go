@@ -0,0 +1,131 @@
+package jsonvx
+
+import (
+	"testing"
+)
+
+func TestFormatError(t *testing.T) {
+	var tests = []struct {
+		msg      string
+		input    string
+		parser   *ParserConfig
+		cfg      *SnippetConfig
+		expected string
+	}{
+		{
+			msg:   "Format error with context and hint",
+			input: "[\n  1,\n  .5\n]",
+			expected: "error: unexpected character in JSON input: missing digits before decimal point\n" +
+				" --> 3:3\n" +
+				"  |\n" +
+				"1 | [\n" +
+				"2 |   1,\n" +
+				"3 |   .5\n" +
+				"  |   ^~\n" +
+				"4 | ]\n" +
+				"  = hint: enable ParserConfig.AllowPointEdgeNumbers to accept it\n",
+		},
+		{
+			msg:   "Format error with limited context",
+			input: "[\n  1,\n  .5\n]",
+			cfg:   NewSnippetConfig(WithContextLines(0)),
+			expected: "error: unexpected character in JSON input: missing digits before decimal point\n" +
+				" --> 3:3\n" +
+				"  |\n" +
+				"3 |   .5\n" +
+				"  |   ^~\n" +
+				"  = hint: enable ParserConfig.AllowPointEdgeNumbers to accept it\n",
+		},
+		{
+			msg:   "Format error after tabs",
+			input: "{\n\t\"a\":\t1 2}",
+			cfg:   NewSnippetConfig(WithTabWidth(4)),
+			expected: "error: JSON syntax error: expected ',' or '}' after property value\n" +
+				" --> 2:9\n" +
+				"  |\n" +
+				"1 | {\n" +
+				"2 |     \"a\":    1 2}\n" +
+				"  |               ^\n",
+		},
+		{
+			msg:   "Format error after multi-byte characters",
+			input: "[\"\u00e9\u4e2d\", 1 2]",
+			expected: "error: JSON syntax error: expected ',' or ']' after array element\n" +
+				" --> 1:13\n" +
+				"  |\n" +
+				"1 | [\"\u00e9\u4e2d\", 1 2]\n" +
+				"  |           ^\n",
+		},
+		{
+			msg:    "Format error under multi-byte token",
+			input:  "{\"\u00e9\": 1, \"\u00e9\": 2}",
+			parser: NewParserConfig(WithDuplicateKeys(DuplicateKeysError)),
+			expected: "error: duplicate key in JSON object\n" +
+				" --> 1:11\n" +
+				"  |\n" +
+				"1 | {\"\u00e9\": 1, \"\u00e9\": 2}\n" +
+				"  |          ^~~\n",
+		},
+		{
+			msg:   "Format error at end of input",
+			input: "[1,",
+			expected: "error: unexpected character in JSON input: expected a value\n" +
+				" --> 1:4\n" +
+				"  |\n" +
+				"1 | [1,\n" +
+				"  |    ^\n",
+		},
+		{
+			msg:      "Format error without position",
+			input:    "",
+			expected: "error: no meaningful content to parse\n",
+		},
+		{
+			msg:   "Format colored error",
+			input: "01",
+			cfg:   NewSnippetConfig(WithColor(true)),
+			expected: "\033[1m\033[31merror\033[0m\033[1m: unexpected character in JSON input: numbers cannot have leading zeros\033[0m\n" +
+				" \033[34m--> \033[0m1:1\n" +
+				"\033[34m  |\033[0m\n" +
+				"\033[34m1 |\033[0m 01\n" +
+				"\033[34m  |\033[0m \033[1m\033[31m^~\033[0m\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			parser := NewParser([]byte(test.input), test.parser)
+			_, err := parser.Parse()
+			if err == nil {
+				t.Fatalf("expected a parse error")
+			}
+
+			if got := FormatError(err, []byte(test.input), test.cfg); got != test.expected {
+				t.Errorf("got:\n%s\nexpected:\n%s", got, test.expected)
+			}
+		})
+	}
+}
+
+func TestRuneWidth(t *testing.T) {
+	var tests = []struct {
+		msg      string
+		input    rune
+		expected int
+	}{
+		{msg: "ASCII letter", input: 'a', expected: 1},
+		{msg: "Latin letter", input: '\u00e9', expected: 1},
+		{msg: "Combining mark", input: '\u0301', expected: 0},
+		{msg: "CJK ideograph", input: '\u4e2d', expected: 2},
+		{msg: "Hangul syllable", input: '\uac00', expected: 2},
+		{msg: "Emoji", input: '\U0001F600', expected: 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			if got := runeWidth(test.input); got != test.expected {
+				t.Errorf("got %d, expected %d", got, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package jsonvx
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Position describes a location in the input.
+type Position struct {
+	Line   int // Line is the line number (1-based index).
+	Column int // Column is the byte column in the line (1-based index).
+	Offset int // Offset is the byte offset in the input (0-based index).
+}
+
+// String returns the position formatted as "line:column".
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Span describes the range of the input a node was parsed from.
+// Start is the position of its first byte and End the position right after its last byte,
+// so input[Start.Offset:End.Offset] is the source text of the node.
+//
+// Nodes that were not parsed from an input, such as those created by hand, have a zero Span.
+type Span struct {
+	Start Position
+	End   Position
+}
+
+// IsZero reports whether the span holds no position, i.e. the node was not parsed from an input.
+func (s Span) IsZero() bool {
+	return s == Span{}
+}
+
+// String returns the span formatted as "line:column-line:column".
+func (s Span) String() string {
+	return fmt.Sprintf("%s-%s", s.Start, s.End)
+}
+
+// Start returns the position of the first byte of the token.
+func (t *Token) Start() Position {
+	return Position{Line: t.Line, Column: t.Column, Offset: t.Offset}
+}
+
+// End returns the position right after the last byte of the token.
+func (t *Token) End() Position {
+	end := Position{Line: t.Line, Column: t.Column + len(t.Literal), Offset: t.Offset + len(t.Literal)}
+
+	if lines := bytes.Count(t.Literal, []byte("\n")); lines > 0 {
+		end.Line += lines
+		end.Column = len(t.Literal) - bytes.LastIndexByte(t.Literal, '\n')
+	}
+
+	return end
+}
+
+// tokenSpan returns the span of a token, or a zero Span for a nil token.
+func tokenSpan(token *Token) Span {
+	if token == nil {
+		return Span{}
+	}
+
+	return Span{Start: token.Start(), End: token.End()}
+}
+
+// tokensSpan returns the span from the start of the first token to the end of the last one.
+func tokensSpan(first, last *Token) Span {
+	if first == nil || last == nil {
+		return Span{}
+	}
+
+	return Span{Start: first.Start(), End: last.End()}
+}
+
+// Span returns the range of the input the null was parsed from.
+func (n *Null) Span() Span {
+	return tokenSpan(n.Token)
+}
+
+// Span returns the range of the input the boolean was parsed from.
+func (b *Boolean) Span() Span {
+	return tokenSpan(b.Token)
+}
+
+// Span returns the range of the input the string was parsed from, quotes included.
+func (s *String) Span() Span {
+	return tokenSpan(s.Token)
+}
+
+// Span returns the range of the input the number was parsed from.
+func (n *Number) Span() Span {
+	return tokenSpan(n.Token)
+}
+
+// Span returns the range of the input the array was parsed from, from its opening to its closing bracket.
+func (a *Array) Span() Span {
+	return tokensSpan(a.openToken, a.closeToken)
+}
+
+// Span returns the range of the input the object was parsed from, from its opening to its closing brace.
+func (o *Object) Span() Span {
+	return tokensSpan(o.openToken, o.closeToken)
+}
+
+// KeySpan returns the range of the input the key was parsed from, quotes included.
+func (kv *KeyValue) KeySpan() Span {
+	return tokenSpan(kv.keyToken)
+}
+
+// Span returns the range of the input the property was parsed from, from the start of its key
+// to the end of its value.
+func (kv *KeyValue) Span() Span {
+	if kv.keyToken == nil || kv.value == nil {
+		return Span{}
+	}
+
+	valueSpan := kv.value.Span()
+	if valueSpan.IsZero() {
+		return Span{}
+	}
+
+	return Span{Start: kv.keyToken.Start(), End: valueSpan.End}
+}
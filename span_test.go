@@ -0,0 +1,159 @@
+package jsonvx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTokenOffsets(t *testing.T) {
+	input := []byte("{\n  \"a\": /* c */ [1, 'x\\\ny'],\n}")
+	l := NewLexer(input, JSON5Config())
+
+	for _, token := range l.Tokens() {
+		if token.Kind == EOF {
+			if token.Offset != len(input) {
+				t.Errorf("got EOF offset %d, expected %d", token.Offset, len(input))
+			}
+			continue
+		}
+
+		if got := string(input[token.Offset : token.Offset+len(token.Literal)]); got != string(token.Literal) {
+			t.Errorf("got %q at offset %d, expected %q", got, token.Offset, token.Literal)
+		}
+	}
+}
+
+func TestSpans(t *testing.T) {
+	input := "{\n  \"name\": 'multi\\\nline',\n  list: [1, true, null],\n  empty: {}\n}"
+
+	parser := NewParser([]byte(input), JSON5Config())
+	node, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	obj := node.(*Object)
+	name, _ := obj.QueryPath("name")
+	list, _ := obj.QueryPath("list")
+	empty, _ := obj.QueryPath("empty")
+
+	var tests = []struct {
+		msg      string
+		span     Span
+		expected Span
+		source   string
+	}{
+		{
+			msg:      "Object span",
+			span:     obj.Span(),
+			expected: Span{Start: Position{Line: 1, Column: 1, Offset: 0}, End: Position{Line: 6, Column: 2, Offset: len(input)}},
+			source:   input,
+		},
+		{
+			msg:      "Multi-line string span",
+			span:     name.Span(),
+			expected: Span{Start: Position{Line: 2, Column: 11, Offset: 12}, End: Position{Line: 3, Column: 6, Offset: 25}},
+			source:   "'multi\\\nline'",
+		},
+		{
+			msg:      "Array span",
+			span:     list.Span(),
+			expected: Span{Start: Position{Line: 4, Column: 9, Offset: 35}, End: Position{Line: 4, Column: 24, Offset: 50}},
+			source:   "[1, true, null]",
+		},
+		{
+			msg:      "Scalar item span",
+			span:     list.(*Array).Items[1].Span(),
+			expected: Span{Start: Position{Line: 4, Column: 13, Offset: 39}, End: Position{Line: 4, Column: 17, Offset: 43}},
+			source:   "true",
+		},
+		{
+			msg:      "Empty object span",
+			span:     empty.Span(),
+			expected: Span{Start: Position{Line: 5, Column: 10, Offset: 61}, End: Position{Line: 5, Column: 12, Offset: 63}},
+			source:   "{}",
+		},
+		{
+			msg:      "Key span",
+			span:     obj.Properties[1].KeySpan(),
+			expected: Span{Start: Position{Line: 4, Column: 3, Offset: 29}, End: Position{Line: 4, Column: 7, Offset: 33}},
+			source:   "list",
+		},
+		{
+			msg:      "Property span",
+			span:     obj.Properties[0].Span(),
+			expected: Span{Start: Position{Line: 2, Column: 3, Offset: 4}, End: Position{Line: 3, Column: 6, Offset: 25}},
+			source:   "\"name\": 'multi\\\nline'",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			if test.span != test.expected {
+				t.Errorf("got %v (%+v), expected %v (%+v)", test.span, test.span, test.expected, test.expected)
+			}
+
+			if got := input[test.span.Start.Offset:test.span.End.Offset]; got != test.source {
+				t.Errorf("got source %q, expected %q", got, test.source)
+			}
+		})
+	}
+}
+
+func TestZeroSpan(t *testing.T) {
+	obj := &Object{}
+	obj.Set("a", &Array{})
+
+	if !obj.Span().IsZero() || !obj.Properties[0].Span().IsZero() || !obj.Properties[0].KeySpan().IsZero() {
+		t.Errorf("expected zero spans for nodes that were not parsed")
+	}
+}
+
+func TestSpansAfterIdent(t *testing.T) {
+	input := "{a\n: 1,\nb\n:\n[x]}"
+
+	tokens := NewLexer([]byte("x\n["), JSON5Config()).Tokens()
+	if got := [2]int{tokens[1].Line, tokens[1].Column}; got != [2]int{1, 2} {
+		t.Errorf("got line feed at %d:%d, expected 1:2", got[0], got[1])
+	}
+	if got := [2]int{tokens[2].Line, tokens[2].Column}; got != [2]int{2, 1} {
+		t.Errorf("got bracket at %d:%d, expected 2:1", got[0], got[1])
+	}
+
+	parser := NewParser([]byte(input), JSON5Config())
+	node, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	obj := node.(*Object)
+	value, _ := obj.QueryPath("a")
+
+	var tests = []struct {
+		msg      string
+		span     Span
+		expected Span
+	}{
+		{msg: "Object span", span: obj.Span(), expected: Span{Start: Position{Line: 1, Column: 1, Offset: 0}, End: Position{Line: 5, Column: 5, Offset: len(input)}}},
+		{msg: "Key span", span: obj.Properties[0].KeySpan(), expected: Span{Start: Position{Line: 1, Column: 2, Offset: 1}, End: Position{Line: 1, Column: 3, Offset: 2}}},
+		{msg: "Value span", span: value.Span(), expected: Span{Start: Position{Line: 2, Column: 3, Offset: 5}, End: Position{Line: 2, Column: 4, Offset: 6}}},
+		{msg: "Second key span", span: obj.Properties[1].KeySpan(), expected: Span{Start: Position{Line: 3, Column: 1, Offset: 8}, End: Position{Line: 3, Column: 2, Offset: 9}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			if test.span != test.expected {
+				t.Errorf("got %v, expected %v", test.span, test.expected)
+			}
+		})
+	}
+
+	// the decoder, which counts lines on its own, agrees with the parser
+	decoded, err := NewDecoder(strings.NewReader(input), JSON5Config()).Decode()
+	if err != nil {
+		t.Fatalf("unexpected decode error: %v", err)
+	}
+	if decoded.Span() != obj.Span() {
+		t.Errorf("got decoder span %v, expected %v", decoded.Span(), obj.Span())
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"math"
 	"strings"
 )
 
@@ -149,8 +150,9 @@ type Token struct {
 	Kind    TokenKind    // The general kind of the token (e.g., STRING, NUMBER).
 	SubKind TokenSubKind // The specific sub kind within a kind (e.g., INTEGER vs FLOAT).
 	Literal []byte       // The literal value of the token.
-	Line    int          // The line number where the token appears (1-based index).
-	Column  int          // The column position in the line (0-based index).
+	Line    int          // The line number where the token starts (1-based index).
+	Column  int          // The byte column where the token starts in its line (1-based index).
+	Offset  int          // The byte offset where the token starts in the input (0-based index).
 }
 
 // newToken creates and returns a new Token.
@@ -205,7 +207,11 @@ func (t *Token) Value() any {
 	case STRING:
 		switch t.SubKind {
 		case SINGLE_QUOTED, DOUBLE_QUOTED:
-			return quoteValue(t.Literal)
+			val, err := unquoteValue(t.Literal)
+			if err != nil {
+				return nil
+			}
+			return val
 		case IDENT:
 			return string(t.Literal)
 		default:
@@ -213,12 +219,33 @@ func (t *Token) Value() any {
 		}
 	case NUMBER:
 		switch t.SubKind {
-		case INTEGER, HEX:
-			val, _ := ToInt(t.Literal)
-			return val
+		case INTEGER:
+			// an integer out of the range of int64 is only approximated by a float64
+			if val, err := ToInt(t.Literal); err == nil {
+				return val
+			}
+			if val, err := ToFloat(t.Literal); err == nil {
+				return val
+			}
+			return nil
+		case HEX:
+			if val, err := ToHex(t.Literal); err == nil {
+				return val
+			}
+			if val, ok := hexToFloat(t.Literal); ok {
+				return val
+			}
+			return nil
 		case FLOAT, SCI_NOT:
 			val, _ := ToFloat(t.Literal)
 			return val
+		case INF:
+			if bytes.HasPrefix(t.Literal, []byte("-")) {
+				return math.Inf(-1)
+			}
+			return math.Inf(1)
+		case NaN:
+			return math.NaN()
 		default:
 			return nil
 		}
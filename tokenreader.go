@@ -0,0 +1,452 @@
+package jsonvx
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// ErrNoValue is returned by TokenReader.ReadValue when the container ends instead of holding another value.
+var ErrNoValue = errors.New("no value to read")
+
+// EventKind identifies the structural events produced by a TokenReader.
+type EventKind int
+
+const (
+	EventBeginObject EventKind = iota // EventBeginObject is produced for the opening brace of an object.
+	EventEndObject                    // EventEndObject is produced for the closing brace of an object.
+	EventBeginArray                   // EventBeginArray is produced for the opening bracket of an array.
+	EventEndArray                     // EventEndArray is produced for the closing bracket of an array.
+	EventKey                          // EventKey is produced for the key of a property.
+	EventValue                        // EventValue is produced for a null, boolean, string or number value.
+)
+
+// String returns a string representation of the EventKind.
+func (k EventKind) String() string {
+	m := map[EventKind]string{
+		EventBeginObject: "EventBeginObject",
+		EventEndObject:   "EventEndObject",
+		EventBeginArray:  "EventBeginArray",
+		EventEndArray:    "EventEndArray",
+		EventKey:         "EventKey",
+		EventValue:       "EventValue",
+	}
+
+	if str, ok := m[k]; ok {
+		return str
+	}
+	return "UNKNOWN"
+}
+
+// Event is a structural event of a JSON document, as produced by TokenReader.Next.
+type Event struct {
+	Kind  EventKind // Kind is the kind of the event.
+	Token Token     // Token is the token the event comes from: a bracket, a key or a scalar value.
+	Key   string    // Key is the unescaped key, for EventKey events.
+}
+
+// Node returns the value of an EventValue event as a *Null, *Boolean, *String or *Number, and nil
+// for other events.
+func (e Event) Node() JSON {
+	if e.Kind != EventValue {
+		return nil
+	}
+
+	token := e.Token
+
+	switch token.Kind {
+	case NULL:
+		return newNull(&token, nil)
+	case BOOLEAN:
+		return newBoolean(&token, nil)
+	case STRING:
+		return newString(&token, nil)
+	case NUMBER:
+		return newNumber(&token, nil)
+	default:
+		return nil
+	}
+}
+
+// readerState is what a TokenReader expects next inside a container.
+type readerState int
+
+const (
+	stateFirst     readerState = iota // stateFirst expects the first item or key, or the end of the container.
+	stateKey                          // stateKey expects a key, after a comma.
+	stateColon                        // stateColon expects the colon following a key.
+	stateValue                        // stateValue expects a value, after a colon or a comma in an array.
+	stateSeparator                    // stateSeparator expects a comma or the end of the container.
+)
+
+// frame is an array or object a TokenReader is in.
+type frame struct {
+	object bool
+	state  readerState
+	index  int    // index is the position of the current item of an array, -1 before the first one.
+	key    string // key is the key of the current property of an object.
+	hasKey bool   // hasKey reports whether the object has a current property.
+	comma  Token  // comma is the last comma read, for reporting trailing commas.
+
+	seen map[string]Token // seen holds the keys read so far, when duplicate keys are reported.
+}
+
+// TokenReader reads a JSON document as a stream of structural events, without building its tree.
+//
+// Whitespace and comments are skipped, and the document is checked against the grammar as it is read,
+// with the same ParserConfig options and errors as Parser. As the input is read through a Decoder, only
+// the token being read is held in memory, which makes it possible to pull a few fields out of documents
+// too large for Parse. DuplicateKeysError is honoured, while keys are otherwise reported as they appear.
+type TokenReader struct {
+	decoder *Decoder
+	config  *ParserConfig
+	stack   []frame
+	started bool  // started reports whether the root value has been started.
+	last    Event // last is the last event returned by Next.
+	err     error // err is the error that stopped the reader, if any.
+}
+
+// NewTokenReader creates a new TokenReader reading from r, using the given configuration.
+// A nil configuration uses the defaults of NewParserConfig.
+func NewTokenReader(r io.Reader, config *ParserConfig) *TokenReader {
+	decoder := NewDecoder(r, config)
+
+	return &TokenReader{decoder: decoder, config: decoder.config}
+}
+
+// Next returns the next event of the document. Once the document has been read in full, it returns io.EOF;
+// any other error is a *SyntaxError, or the error returned by the reader, and it is returned again by
+// every following call.
+func (r *TokenReader) Next() (Event, error) {
+	if r.err != nil {
+		return Event{}, r.err
+	}
+
+	event, err := r.next()
+	if err != nil {
+		r.err = err
+		return Event{}, err
+	}
+
+	r.last = event
+	return event, nil
+}
+
+func (r *TokenReader) next() (Event, error) {
+	for {
+		token, err := r.token()
+		if err != nil {
+			return Event{}, err
+		}
+
+		if len(r.stack) == 0 {
+			return r.root(token)
+		}
+
+		top := &r.stack[len(r.stack)-1]
+
+		switch top.state {
+		case stateFirst, stateKey:
+			if top.object && token.Kind == RIGHT_CURLY_BRACE || !top.object && token.Kind == RIGHT_SQUARE_BRACE {
+				return r.end(token)
+			}
+
+			if top.object {
+				return r.key(token)
+			}
+
+			return r.value(token)
+		case stateColon:
+			if token.Kind != COLON {
+				return Event{}, r.syntaxError(ErrJSONSyntax, token, "expected ':' after property name", "", COLON)
+			}
+
+			top.state = stateValue
+		case stateValue:
+			return r.value(token)
+		case stateSeparator:
+			switch {
+			case token.Kind == COMMA:
+				top.state, top.comma = stateKey, token
+			case top.object && token.Kind == RIGHT_CURLY_BRACE, !top.object && token.Kind == RIGHT_SQUARE_BRACE:
+				return r.end(token)
+			case top.object:
+				return Event{}, r.syntaxError(ErrJSONSyntax, token, "expected ',' or '}' after property value", "", COMMA, RIGHT_CURLY_BRACE)
+			default:
+				return Event{}, r.syntaxError(ErrJSONSyntax, token, "expected ',' or ']' after array element", "", COMMA, RIGHT_SQUARE_BRACE)
+			}
+		}
+	}
+}
+
+// root handles a token found outside of any container.
+func (r *TokenReader) root(token Token) (Event, error) {
+	if !r.started {
+		if token.Kind == EOF {
+			return Event{}, ErrJSONNoContent
+		}
+
+		r.started = true
+		return r.value(token)
+	}
+
+	switch {
+	case token.Kind == EOF:
+		return Event{}, io.EOF
+	case isValueStart(token):
+		return Event{}, WrapJSONMultipleContentError(token)
+	default:
+		return Event{}, r.syntaxError(ErrJSONUnexpectedChar, token, "unexpected character after JSON data", "", EOF)
+	}
+}
+
+// value handles a token expected to start a value.
+func (r *TokenReader) value(token Token) (Event, error) {
+	switch token.Kind {
+	case NULL, BOOLEAN, NUMBER, STRING:
+		if token.SubKind == IDENT {
+			return Event{}, r.syntaxError(ErrJSONSyntax, token, "unquoted strings are only allowed as object keys", "", valueKinds...)
+		}
+
+		r.advance()
+		return Event{Kind: EventValue, Token: token}, nil
+	case LEFT_SQUARE_BRACE:
+		r.advance()
+		r.stack = append(r.stack, frame{index: -1})
+		return Event{Kind: EventBeginArray, Token: token}, nil
+	case LEFT_CURLY_BRACE:
+		r.advance()
+
+		var seen map[string]Token
+		if r.config.DuplicateKeys == DuplicateKeysError {
+			seen = map[string]Token{}
+		}
+
+		r.stack = append(r.stack, frame{object: true, index: -1, seen: seen})
+		return Event{Kind: EventBeginObject, Token: token}, nil
+	case RIGHT_SQUARE_BRACE, RIGHT_CURLY_BRACE:
+		return Event{}, r.syntaxError(ErrJSONUnexpectedChar, token, "expected a value", "", valueKinds...)
+	case ILLEGAL:
+		return Event{}, r.syntaxError(ErrJSONUnexpectedChar, token, "", "", valueKinds...)
+	default:
+		return Event{}, r.syntaxError(ErrJSONUnexpectedChar, token, "expected a value", "", valueKinds...)
+	}
+}
+
+// advance moves the current container on to its next item, as a value starts.
+func (r *TokenReader) advance() {
+	if len(r.stack) == 0 {
+		return
+	}
+
+	top := &r.stack[len(r.stack)-1]
+	if !top.object {
+		top.index++
+	}
+	top.state = stateSeparator
+}
+
+// key handles a token expected to be the key of a property.
+func (r *TokenReader) key(token Token) (Event, error) {
+	top := &r.stack[len(r.stack)-1]
+
+	if token.Kind != STRING {
+		message := "expected property name"
+		if token.Kind == ILLEGAL {
+			message = ""
+		}
+
+		return Event{}, r.syntaxError(ErrJSONSyntax, token, message, "", STRING)
+	}
+
+	key, err := newString(&token, nil).Value()
+	if err != nil {
+		return Event{}, r.syntaxError(ErrJSONSyntax, token, "bad escape in property name", "", STRING)
+	}
+
+	if top.seen != nil {
+		if first, ok := top.seen[key]; ok {
+			return Event{}, WrapJSONDuplicateKeyError(token, first)
+		}
+		top.seen[key] = token
+	}
+
+	top.key, top.hasKey, top.state = key, true, stateColon
+	return Event{Kind: EventKey, Token: token, Key: key}, nil
+}
+
+// end handles the closing bracket of the current container.
+func (r *TokenReader) end(token Token) (Event, error) {
+	top := r.stack[len(r.stack)-1]
+
+	if top.state == stateKey {
+		if top.object && !r.config.AllowTrailingCommaObject {
+			return Event{}, r.syntaxError(ErrJSONSyntax, top.comma, "trailing comma is not allowed in objects", "AllowTrailingCommaObject", STRING)
+		}
+
+		if !top.object && !r.config.AllowTrailingCommaArray {
+			return Event{}, r.syntaxError(ErrJSONSyntax, top.comma, "trailing comma is not allowed in arrays", "AllowTrailingCommaArray", valueKinds...)
+		}
+	}
+
+	r.stack = r.stack[:len(r.stack)-1]
+
+	if top.object {
+		return Event{Kind: EventEndObject, Token: token}, nil
+	}
+	return Event{Kind: EventEndArray, Token: token}, nil
+}
+
+// token returns the next token that is neither whitespace nor a comment.
+func (r *TokenReader) token() (Token, error) {
+	for {
+		token := r.decoder.token()
+
+		if token.Kind == EOF && r.decoder.err != nil {
+			return Token{}, r.decoder.err
+		}
+
+		if token.Kind != WHITESPACE && token.Kind != COMMENT {
+			return token, nil
+		}
+	}
+}
+
+func (r *TokenReader) syntaxError(err error, token Token, message, flag string, expected ...TokenKind) error {
+	return r.decoder.parser.syntaxError(err, token, message, flag, expected...)
+}
+
+// Skip jumps past a whole subtree without reporting its events:
+//   - after an EventKey, it skips the value of the property;
+//   - after an EventBeginObject or EventBeginArray, it skips the rest of the container, its end included;
+//   - before the first event, it skips the whole document;
+//   - otherwise, it skips the rest of the container the last event is in, its end included.
+func (r *TokenReader) Skip() error {
+	if r.err != nil {
+		return r.err
+	}
+
+	if !r.started || r.last.Kind == EventKey {
+		event, err := r.Next()
+		if err != nil {
+			return err
+		}
+
+		if event.Kind != EventBeginObject && event.Kind != EventBeginArray {
+			return nil
+		}
+	}
+
+	depth := r.Depth()
+
+	for depth > 0 && r.Depth() >= depth {
+		if _, err := r.Next(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReadValue reads the next value and returns it as a tree, like Parse would: after an EventKey, the value
+// of the property, and before the first event, the whole document. Only the value is held in memory.
+// When the current container ends instead, its end is read and ErrNoValue is returned.
+func (r *TokenReader) ReadValue() (JSON, error) {
+	event, err := r.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	return r.build(event)
+}
+
+// build returns the tree of the value starting with event.
+func (r *TokenReader) build(event Event) (JSON, error) {
+	switch event.Kind {
+	case EventValue:
+		return event.Node(), nil
+	case EventBeginArray:
+		openToken := event.Token
+		items := []JSON{}
+
+		for {
+			event, err := r.Next()
+			if err != nil {
+				return nil, err
+			}
+
+			if event.Kind == EventEndArray {
+				arr := newArray(items, nil)
+				arr.openToken, arr.closeToken = &openToken, &event.Token
+				return arr, nil
+			}
+
+			item, err := r.build(event)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+	case EventBeginObject:
+		openToken := event.Token
+		properties := []KeyValue{}
+		positions := map[string]int{}
+
+		for {
+			event, err := r.Next()
+			if err != nil {
+				return nil, err
+			}
+
+			if event.Kind == EventEndObject {
+				obj := newObject(properties, nil)
+				obj.openToken, obj.closeToken = &openToken, &event.Token
+				return obj, nil
+			}
+
+			keyToken := event.Token
+			value, err := r.ReadValue()
+			if err != nil {
+				return nil, err
+			}
+
+			position, isDuplicate := positions[event.Key]
+
+			switch {
+			case !isDuplicate || r.config.DuplicateKeys == DuplicateKeysKeepAll:
+				if !isDuplicate {
+					positions[event.Key] = len(properties)
+				}
+				properties = append(properties, KeyValue{key: []byte(event.Key), value: value, keyToken: &keyToken})
+			case r.config.DuplicateKeys == DuplicateKeysLastWins:
+				properties[position].value = value
+			}
+		}
+	default:
+		return nil, fmt.Errorf("%w: got %s", ErrNoValue, event.Kind)
+	}
+}
+
+// Depth returns the number of objects and arrays the last event is in, the containers started by
+// EventBeginObject and EventBeginArray included.
+func (r *TokenReader) Depth() int {
+	return len(r.stack)
+}
+
+// Path returns the location of the last event as QueryPath segments: the keys of the properties and the
+// indices of the array items leading to it. It is empty for the root value.
+func (r *TokenReader) Path() []string {
+	path := make([]string, 0, len(r.stack))
+
+	for _, f := range r.stack {
+		switch {
+		case f.object && f.hasKey:
+			path = append(path, f.key)
+		case !f.object && f.index >= 0:
+			path = append(path, strconv.Itoa(f.index))
+		}
+	}
+
+	return path
+}
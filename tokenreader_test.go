@@ -0,0 +1,267 @@
+package jsonvx
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// readEvents returns the events of the input as "kind literal depth path" strings, followed by
+// the error that stopped the reader, if any other than io.EOF.
+func readEvents(t *testing.T, r *TokenReader) []string {
+	t.Helper()
+
+	var events []string
+	for {
+		event, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return events
+		}
+
+		if err != nil {
+			return append(events, err.Error())
+		}
+
+		events = append(events, fmt.Sprintf("%s %s %d %s", event.Kind, event.Token.Literal, r.Depth(), strings.Join(r.Path(), ".")))
+	}
+}
+
+func TestTokenReaderEvents(t *testing.T) {
+	var tests = []struct {
+		msg      string
+		input    string
+		cfg      *ParserConfig
+		expected []string
+	}{
+		{msg: "Scalar", input: ` 42 `, expected: []string{"EventValue 42 0 "}},
+		{msg: "Empty containers", input: `[{}, []]`, expected: []string{
+			"EventBeginArray [ 1 ",
+			"EventBeginObject { 2 0",
+			"EventEndObject } 1 0",
+			"EventBeginArray [ 2 1",
+			"EventEndArray ] 1 1",
+			"EventEndArray ] 0 ",
+		}},
+		{msg: "Nested containers", input: `{"a": [1, {"b": null}], "c": "d"}`, expected: []string{
+			"EventBeginObject { 1 ",
+			`EventKey "a" 1 a`,
+			"EventBeginArray [ 2 a",
+			"EventValue 1 2 a.0",
+			"EventBeginObject { 3 a.1",
+			`EventKey "b" 3 a.1.b`,
+			"EventValue null 3 a.1.b",
+			"EventEndObject } 2 a.1",
+			"EventEndArray ] 1 a",
+			`EventKey "c" 1 c`,
+			`EventValue "d" 1 c`,
+			"EventEndObject } 0 ",
+		}},
+		{msg: "Relaxed syntax", input: "// config\n{name: 'x', list: [0x1, .5,], /* end */}", cfg: JSON5Config(), expected: []string{
+			"EventBeginObject { 1 ",
+			"EventKey name 1 name",
+			"EventValue 'x' 1 name",
+			"EventKey list 1 list",
+			"EventBeginArray [ 2 list",
+			"EventValue 0x1 2 list.0",
+			"EventValue .5 2 list.1",
+			"EventEndArray ] 1 list",
+			"EventEndObject } 0 ",
+		}},
+		{msg: "Escaped key", input: `{"a.b": true}`, expected: []string{
+			"EventBeginObject { 1 ",
+			`EventKey "a.b" 1 a.b`,
+			"EventValue true 1 a.b",
+			"EventEndObject } 0 ",
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			r := NewTokenReader(iotest.OneByteReader(strings.NewReader(test.input)), test.cfg)
+
+			if got := readEvents(t, r); !slices.Equal(got, test.expected) {
+				t.Errorf("got events\n%s\nexpected\n%s", strings.Join(got, "\n"), strings.Join(test.expected, "\n"))
+			}
+		})
+	}
+}
+
+func TestTokenReaderErrors(t *testing.T) {
+	var tests = []struct {
+		msg   string
+		input string
+		cfg   *ParserConfig
+	}{
+		{msg: "No content", input: ` `},
+		{msg: "Missing comma", input: "[1\n 2]"},
+		{msg: "Missing colon", input: `{"a" 1}`},
+		{msg: "Invalid key", input: `{1: 2}`},
+		{msg: "Unquoted value", input: `{a: b}`, cfg: NewParserConfig(WithAllowUnquoted(true))},
+		{msg: "Trailing comma in array", input: `[1, 2,]`},
+		{msg: "Trailing comma in object", input: `{"a": 1,}`},
+		{msg: "Disallowed hex number", input: "[1,\n 0x1]"},
+		{msg: "Unterminated string", input: `{"a": "b`},
+		{msg: "Unclosed array", input: `[1, 2`},
+		{msg: "Mismatched bracket", input: `[1}`},
+		{msg: "Multiple values", input: "1\n2"},
+		{msg: "Unexpected character after value", input: `null x`},
+		{msg: "Duplicate key", input: `{"a": 1, "a": 2}`, cfg: NewParserConfig(WithDuplicateKeys(DuplicateKeysError))},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			parser := NewParser([]byte(test.input), test.cfg)
+			_, expected := parser.Parse()
+			if expected == nil {
+				t.Fatalf("expected a parse error")
+			}
+
+			r := NewTokenReader(strings.NewReader(test.input), test.cfg)
+			events := readEvents(t, r)
+
+			if got := events[len(events)-1]; got != expected.Error() {
+				t.Errorf("got %s, expected %s", got, expected)
+			}
+
+			if _, err := r.Next(); err == nil || err.Error() != expected.Error() {
+				t.Errorf("got %v after the error, expected %v", err, expected)
+			}
+		})
+	}
+}
+
+func TestTokenReaderSkip(t *testing.T) {
+	input := `{"skip": {"x": [1, 2, {}]}, "keep": [true, {"y": null}, 3], "rest": {"a": 1, "b": 2}, "last": 4}`
+
+	r := NewTokenReader(strings.NewReader(input), nil)
+	var got []string
+
+	for {
+		event, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		switch {
+		case event.Kind == EventKey && event.Key == "skip":
+			// skips the value of the key
+			if err := r.Skip(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		case event.Kind == EventBeginObject && r.Depth() == 3:
+			// skips the rest of the object just started
+			if err := r.Skip(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		case event.Kind == EventKey && event.Key == "a":
+			// skips the rest of the enclosing object, after reading the value of the key
+			if _, err := r.Next(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := r.Skip(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+
+		got = append(got, fmt.Sprintf("%s %s", event.Token.Literal, strings.Join(r.Path(), ".")))
+	}
+
+	expected := []string{
+		"{ ",
+		`"skip" skip`,
+		`"keep" keep`,
+		"[ keep",
+		"true keep.0",
+		"{ keep.1",
+		"3 keep.2",
+		"] keep",
+		`"rest" rest`,
+		"{ rest",
+		`"a" rest`,
+		`"last" last`,
+		"4 last",
+		"} ",
+	}
+
+	if !slices.Equal(got, expected) {
+		t.Errorf("got events\n%s\nexpected\n%s", strings.Join(got, "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+func TestTokenReaderSkipDocument(t *testing.T) {
+	r := NewTokenReader(strings.NewReader(`{"a": [1, 2]} `), nil)
+
+	if err := r.Skip(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := r.Next(); !errors.Is(err, io.EOF) {
+		t.Errorf("got %v, expected io.EOF", err)
+	}
+}
+
+func TestTokenReaderReadValue(t *testing.T) {
+	input := []byte(`{"meta": {"count": 2}, "items": [{"id": 1, "tags": ["a"]}, {"id": 2, "tags": []}], "end": true}`)
+
+	parser := NewParser(input, nil)
+	root, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	obj, _ := AsObject(root)
+
+	r := NewTokenReader(strings.NewReader(string(input)), nil)
+	if _, err := r.Next(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for {
+		event, err := r.Next()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if event.Kind == EventEndObject {
+			break
+		}
+
+		value, err := r.ReadValue()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected, _ := obj.QueryPath(event.Key)
+		if !value.Equal(expected) {
+			t.Errorf("got %s for %s, expected %s", value, event.Key, expected)
+		}
+
+		if value.Span() != expected.Span() {
+			t.Errorf("got span %s for %s, expected %s", value.Span(), event.Key, expected.Span())
+		}
+	}
+
+	if _, err := r.ReadValue(); !errors.Is(err, io.EOF) {
+		t.Errorf("got %v, expected io.EOF", err)
+	}
+}
+
+func TestTokenReaderReadValueAtEnd(t *testing.T) {
+	r := NewTokenReader(strings.NewReader(`[1]`), nil)
+	r.Next()
+	r.Next()
+
+	if _, err := r.ReadValue(); !errors.Is(err, ErrNoValue) {
+		t.Errorf("got %v, expected %v", err, ErrNoValue)
+	}
+
+	if r.Depth() != 0 {
+		t.Errorf("got depth %d, expected 0", r.Depth())
+	}
+}
@@ -0,0 +1,648 @@
+package jsonvx
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Errors returned by Unmarshal and Decode.
+var (
+	ErrInvalidDecodeTarget = errors.New("decode target must be a non-nil pointer")
+	ErrDecodeType          = errors.New("JSON value does not match the Go type")
+)
+
+var (
+	jsonNumberType      = reflect.TypeFor[json.Number]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// DecodeError describes a JSON value that could not be stored into a Go value.
+type DecodeError struct {
+	Err   error        // Err is the cause of the failure, e.g. ErrDecodeType, ErrNumberOverflow or the error of an UnmarshalJSON method.
+	Value string       // Value is the kind of the JSON value (e.g., "number" or "object").
+	Type  reflect.Type // Type is the Go type the value was decoded into.
+	Path  []string     // Path is the query path of the value from the decoded node, as accepted by QueryPath.
+	Span  Span         // Span is the range of the input the value was parsed from, it is zero for nodes created by hand.
+}
+
+// Error returns the JSON kind and Go type involved, followed by the position and the path of the value, and the cause.
+func (e *DecodeError) Error() string {
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, "cannot decode %s into Go value of type %s", e.Value, e.Type)
+
+	if !e.Span.IsZero() {
+		fmt.Fprintf(&builder, " at line %d, column %d", e.Span.Start.Line, e.Span.Start.Column)
+	}
+
+	if len(e.Path) > 0 {
+		fmt.Fprintf(&builder, " (path %v)", e.Path)
+	}
+
+	fmt.Fprintf(&builder, ": %v", e.Err)
+
+	return builder.String()
+}
+
+// Unwrap returns the cause of the failure.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Unmarshal parses data according to cfg and stores the result in the value pointed to by v.
+// A nil configuration uses the defaults of NewParserConfig.
+//
+// Values are stored the way encoding/json does it, whatever relaxed syntax they were written in:
+//   - objects into structs, whose fields are matched by their `json` tag or name, case-insensitively
+//     if there is no exact match, and into maps with string, integer or encoding.TextUnmarshaler keys;
+//   - arrays into slices and arrays;
+//   - strings into strings, []byte (from base64) and encoding.TextUnmarshaler;
+//   - numbers into integers, floats and json.Number, hexadecimal numbers, NaN and Infinity included;
+//     integers only take numbers written without a fraction or an exponent;
+//   - booleans into bools, and null into pointers, maps, slices and interfaces, which are set to nil;
+//   - any value into an empty interface, as map[string]any, []any, string, float64, bool or nil.
+//
+// Pointers are allocated as needed, and a type implementing json.Unmarshaler is handed the value
+// encoded as strict JSON. With the `string` tag option, a boolean, number or string field is read
+// from a JSON string holding its value, parsed with the configuration of the enclosing document.
+//
+// A value that does not fit its Go type is skipped and decoding goes on; the first such failure is
+// then returned as a *DecodeError. Syntax errors are returned before anything is stored.
+func Unmarshal(data []byte, v any, cfg *ParserConfig) error {
+	if err := checkDecodeTarget(v); err != nil {
+		return err
+	}
+
+	parser := NewParser(data, cfg)
+
+	node, err := parser.Parse()
+	if err != nil {
+		return err
+	}
+
+	return decodeNode(node, v)
+}
+
+// Decode stores the null in the value pointed to by v, see Unmarshal.
+func (n *Null) Decode(v any) error {
+	return decodeNode(n, v)
+}
+
+// Decode stores the boolean in the value pointed to by v, see Unmarshal.
+func (b *Boolean) Decode(v any) error {
+	return decodeNode(b, v)
+}
+
+// Decode stores the string in the value pointed to by v, see Unmarshal.
+func (s *String) Decode(v any) error {
+	return decodeNode(s, v)
+}
+
+// Decode stores the number in the value pointed to by v, see Unmarshal.
+func (n *Number) Decode(v any) error {
+	return decodeNode(n, v)
+}
+
+// Decode stores the array in the value pointed to by v, see Unmarshal.
+func (a *Array) Decode(v any) error {
+	return decodeNode(a, v)
+}
+
+// Decode stores the object in the value pointed to by v, see Unmarshal.
+func (o *Object) Decode(v any) error {
+	return decodeNode(o, v)
+}
+
+// Decode fails with a *DecodeError wrapping the error the node stands for.
+func (e *ErrorNode) Decode(v any) error {
+	return decodeNode(e, v)
+}
+
+// checkDecodeTarget reports an error if v is not a non-nil pointer.
+func checkDecodeTarget(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("%w: %T", ErrInvalidDecodeTarget, v)
+	}
+
+	return nil
+}
+
+// decodeNode stores node in the value pointed to by v.
+func decodeNode(node JSON, v any) error {
+	if err := checkDecodeTarget(v); err != nil {
+		return err
+	}
+
+	var d decodeState
+	d.decode(node, reflect.ValueOf(v))
+
+	return d.err
+}
+
+// decodeState is the state of a call to Unmarshal or Decode.
+type decodeState struct {
+	path []string
+	err  error // err is the first failure met.
+
+	quotedNode JSON // quotedNode is the string holding the value being decoded for a `string` field, if any.
+}
+
+// saveError records the failure to decode node into a value of type typ, unless one was recorded already.
+func (d *decodeState) saveError(node JSON, typ reflect.Type, err error) {
+	if d.err != nil {
+		return
+	}
+
+	if d.quotedNode != nil {
+		node = d.quotedNode
+	}
+
+	decodeErr := &DecodeError{Err: err, Value: jsonKind(node), Type: typ, Path: cloneStrings(d.path)}
+	if !isNilNode(node) {
+		decodeErr.Span = node.Span()
+	}
+
+	d.err = decodeErr
+}
+
+// decode stores node into v.
+func (d *decodeState) decode(node JSON, v reflect.Value) {
+	if isNilNode(node) {
+		d.saveError(node, v.Type(), ErrInvalidJSONType)
+		return
+	}
+
+	if errNode, ok := node.(*ErrorNode); ok {
+		if errNode.Err == nil {
+			d.saveError(node, v.Type(), ErrInvalidJSONType)
+		} else {
+			d.saveError(node, v.Type(), errNode.Err)
+		}
+		return
+	}
+
+	_, isNull := node.(*Null)
+
+	u, tu, pv := indirect(v, isNull)
+
+	if u != nil {
+		data, err := Encode(node, nil)
+		if err == nil {
+			err = u.UnmarshalJSON(data)
+		}
+		if err != nil {
+			d.saveError(node, v.Type(), err)
+		}
+		return
+	}
+
+	if tu != nil && !isNull {
+		str, ok := node.(*String)
+		if !ok {
+			d.saveError(node, v.Type(), ErrDecodeType)
+			return
+		}
+
+		strVal, err := str.Value()
+		if err == nil {
+			err = tu.UnmarshalText([]byte(strVal))
+		}
+		if err != nil {
+			d.saveError(node, v.Type(), err)
+		}
+		return
+	}
+
+	switch val := node.(type) {
+	case *Null:
+		switch pv.Kind() {
+		case reflect.Interface, reflect.Pointer, reflect.Map, reflect.Slice:
+			pv.SetZero()
+		}
+	case *Boolean:
+		d.decodeBoolean(val, pv)
+	case *String:
+		d.decodeString(val, pv)
+	case *Number:
+		d.decodeNumber(val, pv)
+	case *Array:
+		d.decodeArray(val, pv)
+	case *Object:
+		d.decodeObject(val, pv)
+	default:
+		d.saveError(node, pv.Type(), ErrInvalidJSONType)
+	}
+}
+
+func (d *decodeState) decodeBoolean(b *Boolean, v reflect.Value) {
+	boolVal, err := b.Value()
+	if err != nil {
+		d.saveError(b, v.Type(), err)
+		return
+	}
+
+	switch {
+	case v.Kind() == reflect.Bool:
+		v.SetBool(boolVal)
+	case isEmptyInterface(v):
+		v.Set(reflect.ValueOf(boolVal))
+	default:
+		d.saveError(b, v.Type(), ErrDecodeType)
+	}
+}
+
+func (d *decodeState) decodeString(s *String, v reflect.Value) {
+	strVal, err := s.Value()
+	if err != nil {
+		d.saveError(s, v.Type(), err)
+		return
+	}
+
+	switch {
+	case v.Kind() == reflect.String && v.Type() != jsonNumberType:
+		v.SetString(strVal)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		bytesVal, err := base64.StdEncoding.DecodeString(strVal)
+		if err != nil {
+			d.saveError(s, v.Type(), err)
+			return
+		}
+		v.SetBytes(bytesVal)
+	case isEmptyInterface(v):
+		v.Set(reflect.ValueOf(strVal))
+	default:
+		d.saveError(s, v.Type(), ErrDecodeType)
+	}
+}
+
+// checkIntegerLiteral reports an error unless n is written as an integer, as encoding/json requires
+// for integer targets: `2.0` and `1e3` are rejected even though they hold integral values.
+func checkIntegerLiteral(n *Number) error {
+	if n.Token.SubKind != INTEGER && n.Token.SubKind != HEX {
+		return fmt.Errorf("%w: %s is not an integer", ErrNumberTruncated, n.Token.Literal)
+	}
+
+	return nil
+}
+
+func (d *decodeState) decodeNumber(n *Number, v reflect.Value) {
+	var err error
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if err = checkIntegerLiteral(n); err != nil {
+			break
+		}
+
+		var intVal int64
+		if intVal, err = n.Int64(); err == nil {
+			if v.OverflowInt(intVal) {
+				err = fmt.Errorf("%w: %s does not fit into %s", ErrNumberOverflow, n.Token.Literal, v.Type())
+			} else {
+				v.SetInt(intVal)
+			}
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if err = checkIntegerLiteral(n); err != nil {
+			break
+		}
+
+		var uintVal uint64
+		if uintVal, err = n.Uint64(); err == nil {
+			if v.OverflowUint(uintVal) {
+				err = fmt.Errorf("%w: %s does not fit into %s", ErrNumberOverflow, n.Token.Literal, v.Type())
+			} else {
+				v.SetUint(uintVal)
+			}
+		}
+	case reflect.Float32, reflect.Float64:
+		var floatVal float64
+		if floatVal, err = n.Value(); err == nil {
+			if v.OverflowFloat(floatVal) {
+				err = fmt.Errorf("%w: %s does not fit into %s", ErrNumberOverflow, n.Token.Literal, v.Type())
+			} else {
+				v.SetFloat(floatVal)
+			}
+		}
+	case reflect.String:
+		if v.Type() != jsonNumberType {
+			err = ErrDecodeType
+			break
+		}
+
+		var numVal json.Number
+		if numVal, err = n.JSONNumber(); err == nil {
+			v.SetString(string(numVal))
+		}
+	case reflect.Interface:
+		if !isEmptyInterface(v) {
+			err = ErrDecodeType
+			break
+		}
+
+		var floatVal float64
+		if floatVal, err = n.Value(); err == nil {
+			v.Set(reflect.ValueOf(floatVal))
+		}
+	default:
+		err = ErrDecodeType
+	}
+
+	if err != nil {
+		d.saveError(n, v.Type(), err)
+	}
+}
+
+func (d *decodeState) decodeArray(a *Array, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Slice:
+		slice := reflect.MakeSlice(v.Type(), a.Len(), a.Len())
+		for i, item := range a.Items {
+			d.decodeItem(item, i, slice.Index(i))
+		}
+		v.Set(slice)
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if i < a.Len() {
+				d.decodeItem(a.Items[i], i, v.Index(i))
+			} else {
+				v.Index(i).SetZero()
+			}
+		}
+	case reflect.Interface:
+		if !isEmptyInterface(v) {
+			d.saveError(a, v.Type(), ErrDecodeType)
+			return
+		}
+
+		items := make([]any, a.Len())
+		for i, item := range a.Items {
+			d.decodeItem(item, i, reflect.ValueOf(items).Index(i))
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		d.saveError(a, v.Type(), ErrDecodeType)
+	}
+}
+
+// decodeItem stores the item at index of an array into v.
+func (d *decodeState) decodeItem(item JSON, index int, v reflect.Value) {
+	d.path = append(d.path, strconv.Itoa(index))
+	d.decode(item, v)
+	d.path = d.path[:len(d.path)-1]
+}
+
+func (d *decodeState) decodeObject(o *Object, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Struct:
+		fields := cachedFields(v.Type())
+
+		for _, prop := range o.Properties {
+			f, ok := lookupField(fields, string(prop.key))
+			if !ok {
+				continue
+			}
+
+			d.path = append(d.path, string(prop.key))
+
+			if fieldVal, ok := d.fieldByIndex(prop.value, v, f.index); ok {
+				if f.quoted {
+					d.decodeQuoted(prop.value, fieldVal)
+				} else {
+					d.decode(prop.value, fieldVal)
+				}
+			}
+
+			d.path = d.path[:len(d.path)-1]
+		}
+	case reflect.Map:
+		keyType := v.Type().Key()
+
+		switch keyType.Kind() {
+		case reflect.String,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		default:
+			if !reflect.PointerTo(keyType).Implements(textUnmarshalerType) {
+				d.saveError(o, v.Type(), ErrDecodeType)
+				return
+			}
+		}
+
+		if v.IsNil() {
+			v.Set(reflect.MakeMapWithSize(v.Type(), o.Len()))
+		}
+
+		for _, prop := range o.Properties {
+			d.path = append(d.path, string(prop.key))
+
+			if key, err := mapKey(string(prop.key), keyType); err != nil {
+				d.saveError(o, v.Type(), err)
+			} else {
+				elem := reflect.New(v.Type().Elem()).Elem()
+				d.decode(prop.value, elem)
+				v.SetMapIndex(key, elem)
+			}
+
+			d.path = d.path[:len(d.path)-1]
+		}
+	case reflect.Interface:
+		if !isEmptyInterface(v) {
+			d.saveError(o, v.Type(), ErrDecodeType)
+			return
+		}
+
+		props := make(map[string]any, o.Len())
+		d.decodeObject(o, reflect.ValueOf(props))
+		v.Set(reflect.ValueOf(props))
+	default:
+		d.saveError(o, v.Type(), ErrDecodeType)
+	}
+}
+
+// fieldByIndex returns the field of the struct v at index, allocating the embedded
+// structs it is promoted through. It fails for an embedded pointer to an unexported struct.
+func (d *decodeState) fieldByIndex(node JSON, v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, fieldIndex := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					d.saveError(node, v.Type(), fmt.Errorf("%w: cannot set embedded pointer to unexported struct %s", ErrDecodeType, v.Type().Elem()))
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(fieldIndex)
+	}
+
+	return v, true
+}
+
+// decodeQuoted stores into v the value held by the JSON string node, for fields with the `string` tag option.
+func (d *decodeState) decodeQuoted(node JSON, v reflect.Value) {
+	str, ok := node.(*String)
+	if !ok {
+		if _, isNull := node.(*Null); isNull {
+			d.decode(node, v)
+			return
+		}
+
+		d.saveError(node, v.Type(), fmt.Errorf("%w: the `string` option expects a JSON string", ErrDecodeType))
+		return
+	}
+
+	strVal, err := str.Value()
+	if err != nil {
+		d.saveError(node, v.Type(), err)
+		return
+	}
+
+	parser := NewParser([]byte(strVal), str.config)
+
+	quoted, err := parser.Parse()
+	if err != nil {
+		d.saveError(node, v.Type(), err)
+		return
+	}
+
+	d.quotedNode = node
+	d.decode(quoted, v)
+	d.quotedNode = nil
+}
+
+// mapKey converts the key of a property into a key of the map type.
+func mapKey(key string, keyType reflect.Type) (reflect.Value, error) {
+	if reflect.PointerTo(keyType).Implements(textUnmarshalerType) {
+		keyVal := reflect.New(keyType)
+		if err := keyVal.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(key)); err != nil {
+			return reflect.Value{}, err
+		}
+		return keyVal.Elem(), nil
+	}
+
+	switch keyType.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(key).Convert(keyType), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		intVal, err := strconv.ParseInt(key, 10, 64)
+		if err != nil || reflect.Zero(keyType).OverflowInt(intVal) {
+			return reflect.Value{}, fmt.Errorf("%w: key %q is not a valid %s", ErrDecodeType, key, keyType)
+		}
+		return reflect.ValueOf(intVal).Convert(keyType), nil
+	default:
+		uintVal, err := strconv.ParseUint(key, 10, 64)
+		if err != nil || reflect.Zero(keyType).OverflowUint(uintVal) {
+			return reflect.Value{}, fmt.Errorf("%w: key %q is not a valid %s", ErrDecodeType, key, keyType)
+		}
+		return reflect.ValueOf(uintVal).Convert(keyType), nil
+	}
+}
+
+// indirect walks down v, allocating nil pointers, until it reaches a value that is not a pointer.
+// If it meets a json.Unmarshaler or an encoding.TextUnmarshaler on the way, it stops and returns it.
+// When decodingNull is set, it stops at the last pointer, so it can be set to nil.
+func indirect(v reflect.Value, decodingNull bool) (json.Unmarshaler, encoding.TextUnmarshaler, reflect.Value) {
+	start := v
+	haveAddr := false
+
+	// a named value that is addressable may have methods on its pointer
+	if v.Kind() != reflect.Pointer && v.Type().Name() != "" && v.CanAddr() {
+		haveAddr = true
+		v = v.Addr()
+	}
+
+	for {
+		// a non-nil pointer held by an interface is decoded into, as encoding/json does
+		if v.Kind() == reflect.Interface && !v.IsNil() {
+			elem := v.Elem()
+			if elem.Kind() == reflect.Pointer && !elem.IsNil() && (!decodingNull || elem.Elem().Kind() == reflect.Pointer) {
+				haveAddr = false
+				v = elem
+				continue
+			}
+		}
+
+		if v.Kind() != reflect.Pointer {
+			break
+		}
+
+		if decodingNull && v.CanSet() {
+			break
+		}
+
+		// a pointer to an interface holding the pointer itself would loop forever
+		if v.Elem().Kind() == reflect.Interface && v.Elem().Elem().Equal(v) {
+			v = v.Elem()
+			break
+		}
+
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+
+		if v.Type().NumMethod() > 0 && v.CanInterface() {
+			if u, ok := v.Interface().(json.Unmarshaler); ok {
+				return u, nil, reflect.Value{}
+			}
+			if !decodingNull {
+				if tu, ok := v.Interface().(encoding.TextUnmarshaler); ok {
+					return nil, tu, reflect.Value{}
+				}
+			}
+		}
+
+		if haveAddr {
+			v = start
+			haveAddr = false
+		} else {
+			v = v.Elem()
+		}
+	}
+
+	return nil, nil, v
+}
+
+// isEmptyInterface reports whether v is an interface without methods, which any value can be stored into.
+func isEmptyInterface(v reflect.Value) bool {
+	return v.Kind() == reflect.Interface && v.NumMethod() == 0
+}
+
+// jsonKind returns the name of the kind of node, for error messages.
+func jsonKind(node JSON) string {
+	switch node.(type) {
+	case *Null:
+		return "null"
+	case *Boolean:
+		return "boolean"
+	case *String:
+		return "string"
+	case *Number:
+		return "number"
+	case *Array:
+		return "array"
+	case *Object:
+		return "object"
+	case *ErrorNode:
+		return "invalid value"
+	default:
+		return fmt.Sprintf("%T", node)
+	}
+}
+
+// cloneStrings returns a copy of strs, or nil if it is empty.
+func cloneStrings(strs []string) []string {
+	if len(strs) == 0 {
+		return nil
+	}
+
+	return append([]string(nil), strs...)
+}
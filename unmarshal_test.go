@@ -0,0 +1,293 @@
+package jsonvx
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type unmarshalAddress struct {
+	Street string `json:"street"`
+	Zip    *int   `json:"zip,omitempty"`
+}
+
+type unmarshalBase struct {
+	ID      int    `json:"id"`
+	Created string `json:"created"`
+}
+
+type unmarshalUser struct {
+	unmarshalBase
+	Name     string             `json:"name"`
+	Age      uint8              `json:"age"`
+	Score    float64            `json:"score"`
+	Admin    bool               `json:"admin"`
+	Tags     []string           `json:"tags"`
+	Address  *unmarshalAddress  `json:"address"`
+	Meta     map[string]any     `json:"meta"`
+	Limits   map[int]float32    `json:"limits"`
+	Count    int64              `json:"count,string"`
+	Ignored  string             `json:"-"`
+	Fallback string             // matched by field name
+	Nested   map[string][]int   `json:"nested,omitempty"`
+	Raw      json.RawMessage    `json:"raw"`
+	Any      any                `json:"any"`
+	Pair     [2]int             `json:"pair"`
+	When     time.Time          `json:"when"`
+	ByDay    map[string]float64 `json:"by_day"`
+	internal string
+}
+
+type unmarshalLevel int
+
+func (l *unmarshalLevel) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return errors.New("unknown level")
+	}
+	return nil
+}
+
+func TestUnmarshal(t *testing.T) {
+	input := `{
+		// JSON5 straight into a struct
+		id: 0x2A,
+		created: 'yesterday',
+		NAME: 'Tom',
+		age: +30,
+		score: .5,
+		admin: true,
+		tags: ['a', "b",],
+		address: {street: 'Main', zip: 12345},
+		meta: {list: [1, null, 'x'], flag: false},
+		limits: {'1': Infinity, '2': 2.5},
+		count: "9000",
+		"-": 'ignored',
+		fallback: 'by name',
+		raw: {a: [1, 0x10]},
+		any: [{}, 1e2],
+		pair: [1, 2, 3],
+		when: '2024-01-02T03:04:05Z',
+		unknown: 'skipped',
+	}`
+
+	var user unmarshalUser
+	if err := Unmarshal([]byte(input), &user, JSON5Config()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	zip := 12345
+	expected := unmarshalUser{
+		unmarshalBase: unmarshalBase{ID: 42, Created: "yesterday"},
+		Name:          "Tom",
+		Age:           30,
+		Score:         0.5,
+		Admin:         true,
+		Tags:          []string{"a", "b"},
+		Address:       &unmarshalAddress{Street: "Main", Zip: &zip},
+		Meta:          map[string]any{"list": []any{1.0, nil, "x"}, "flag": false},
+		Limits:        map[int]float32{1: float32(math.Inf(1)), 2: 2.5},
+		Count:         9000,
+		Fallback:      "by name",
+		Raw:           json.RawMessage(`{"a":[1,16]}`),
+		Any:           []any{map[string]any{}, 100.0},
+		Pair:          [2]int{1, 2},
+		When:          time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	if !reflect.DeepEqual(user, expected) {
+		t.Errorf("got %+v, expected %+v", user, expected)
+	}
+}
+
+func TestUnmarshalValues(t *testing.T) {
+	level := unmarshalLevel(0)
+	ptr := &level
+
+	var tests = []struct {
+		msg      string
+		input    string
+		target   any
+		expected any
+	}{
+		{msg: "Unmarshal null into pointer", input: `null`, target: &ptr, expected: (*unmarshalLevel)(nil)},
+		{msg: "Unmarshal null into int", input: `null`, target: ptrTo(7), expected: 7},
+		{msg: "Unmarshal null into slice", input: `null`, target: &[]int{1}, expected: []int(nil)},
+		{msg: "Unmarshal empty array into slice", input: `[]`, target: new([]int), expected: []int{}},
+		{msg: "Unmarshal short array into array", input: `[9]`, target: &[3]int{1, 2, 3}, expected: [3]int{9, 0, 0}},
+		{msg: "Unmarshal bytes from base64", input: `"aGVsbG8="`, target: new([]byte), expected: []byte("hello")},
+		{msg: "Unmarshal json.Number", input: `0x1F`, target: new(json.Number), expected: json.Number("31")},
+		{msg: "Unmarshal exponent notation into float", input: `1e3`, target: new(float64), expected: 1000.0},
+		{msg: "Unmarshal NaN into float", input: `NaN`, target: new(float64), expected: math.NaN()},
+		{msg: "Unmarshal text unmarshaler", input: `'high'`, target: new(unmarshalLevel), expected: unmarshalLevel(2)},
+		{msg: "Unmarshal text unmarshaler keys", input: `{low: 1}`, target: new(map[unmarshalLevel]int), expected: map[unmarshalLevel]int{1: 1}},
+		{msg: "Unmarshal into existing map", input: `{b: 2}`, target: &map[string]int{"a": 1}, expected: map[string]int{"a": 1, "b": 2}},
+		{msg: "Unmarshal into pointer held by interface", input: `5`, target: &[]any{new(int)}[0], expected: ptrTo(5)},
+		{msg: "Unmarshal multiline string", input: "'a\\\nb'", target: new(string), expected: "ab"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			if err := Unmarshal([]byte(test.input), test.target, JSON5Config()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got := reflect.ValueOf(test.target).Elem().Interface()
+
+			if f, ok := got.(float64); ok && math.IsNaN(f) && math.IsNaN(test.expected.(float64)) {
+				return
+			}
+
+			if !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("got %#v, expected %#v", got, test.expected)
+			}
+		})
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	var tests = []struct {
+		msg          string
+		input        string
+		target       any
+		expectedErr  error
+		expectedPath []string
+		expectedLine int
+	}{
+		{msg: "Unmarshal into non-pointer", input: `1`, target: 0, expectedErr: ErrInvalidDecodeTarget},
+		{msg: "Unmarshal into nil pointer", input: `1`, target: (*int)(nil), expectedErr: ErrInvalidDecodeTarget},
+		{msg: "Unmarshal syntax error", input: `{a: 1}`, target: new(any), expectedErr: ErrJSONUnexpectedChar},
+		{msg: "Unmarshal string into int", input: "{\n\"id\": \"1\"}", target: new(unmarshalUser), expectedErr: ErrDecodeType, expectedPath: []string{"id"}, expectedLine: 2},
+		{msg: "Unmarshal overflowing number", input: `{"age": 256}`, target: new(unmarshalUser), expectedErr: ErrNumberOverflow, expectedPath: []string{"age"}, expectedLine: 1},
+		{msg: "Unmarshal negative number into uint", input: `[-1]`, target: new([]uint), expectedErr: ErrNumberOverflow, expectedPath: []string{"0"}, expectedLine: 1},
+		{msg: "Unmarshal fraction into int", input: `[1, 1.5]`, target: new([]int), expectedErr: ErrNumberTruncated, expectedPath: []string{"1"}, expectedLine: 1},
+		{msg: "Unmarshal integral fraction into int", input: `[2.0]`, target: new([]int64), expectedErr: ErrNumberTruncated, expectedPath: []string{"0"}, expectedLine: 1},
+		{msg: "Unmarshal exponent notation into uint", input: `[1e3]`, target: new([]uint), expectedErr: ErrNumberTruncated, expectedPath: []string{"0"}, expectedLine: 1},
+		{msg: "Unmarshal quoted fraction into int", input: `{"count": "2.0"}`, target: new(unmarshalUser), expectedErr: ErrNumberTruncated, expectedPath: []string{"count"}, expectedLine: 1},
+		{msg: "Unmarshal nested mismatch", input: "{\"meta\": {},\n \"tags\": [\"a\", {}]}", target: new(unmarshalUser), expectedErr: ErrDecodeType, expectedPath: []string{"tags", "1"}, expectedLine: 2},
+		{msg: "Unmarshal quoted field from number", input: `{"count": 1}`, target: new(unmarshalUser), expectedErr: ErrDecodeType, expectedPath: []string{"count"}, expectedLine: 1},
+		{msg: "Unmarshal invalid map key", input: `{"x": 1}`, target: new(map[int]int), expectedErr: ErrDecodeType, expectedPath: []string{"x"}, expectedLine: 1},
+		{msg: "Unmarshal invalid text", input: `"medium"`, target: new(unmarshalLevel), expectedLine: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			err := Unmarshal([]byte(test.input), test.target, nil)
+
+			if err == nil || (test.expectedErr != nil && !errors.Is(err, test.expectedErr)) {
+				t.Fatalf("got %v, expected %v", err, test.expectedErr)
+			}
+
+			if test.expectedLine == 0 {
+				return
+			}
+
+			var decodeErr *DecodeError
+			if !errors.As(err, &decodeErr) {
+				t.Fatalf("got %T, expected *DecodeError", err)
+			}
+
+			if !reflect.DeepEqual(decodeErr.Path, test.expectedPath) || decodeErr.Span.Start.Line != test.expectedLine {
+				t.Errorf("got path %v at line %d, expected %v at line %d", decodeErr.Path, decodeErr.Span.Start.Line, test.expectedPath, test.expectedLine)
+			}
+		})
+	}
+}
+
+func TestUnmarshalKeepsGoing(t *testing.T) {
+	var user unmarshalUser
+	err := Unmarshal([]byte(`{"id": "x", "name": "Tom", "age": -1}`), &user, nil)
+
+	if err == nil || !strings.Contains(err.Error(), "cannot decode string into Go value of type int at line 1, column 8 (path [id])") {
+		t.Errorf("got %v, expected the first failure", err)
+	}
+
+	if user.Name != "Tom" {
+		t.Errorf("got name %q, expected the valid fields to be decoded", user.Name)
+	}
+}
+
+func TestNodeDecode(t *testing.T) {
+	parser := NewParser([]byte(`{"users": [{"name": "Ann", "address": {"street": "High"}}]}`), nil)
+	node, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	users, err := node.(*Object).QueryPath("users")
+	if err != nil {
+		t.Fatalf("unexpected query error: %v", err)
+	}
+
+	var got []unmarshalUser
+	if err := users.Decode(&got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(got) != 1 || got[0].Name != "Ann" || got[0].Address == nil || got[0].Address.Street != "High" {
+		t.Errorf("got %+v", got)
+	}
+
+	json5Parser := NewParser([]byte(`{count: '0x1F', inner: {count: "+2"}}`), JSON5Config())
+	json5Node, err := json5Parser.Parse()
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	var quoted unmarshalUser
+	if err := json5Node.Decode(&quoted); err != nil || quoted.Count != 31 {
+		t.Errorf("got %d (%v), expected the quoted field to be parsed as JSON5", quoted.Count, err)
+	}
+
+	nested, err := json5Node.(*Object).QueryPath("inner")
+	if err != nil {
+		t.Fatalf("unexpected query error: %v", err)
+	}
+
+	if err := nested.Decode(&quoted); err != nil || quoted.Count != 2 {
+		t.Errorf("got %d (%v), expected the quoted field of a sub-tree to be parsed as JSON5", quoted.Count, err)
+	}
+
+	errNode := &ErrorNode{}
+	if err := errNode.Decode(new(any)); !errors.Is(err, ErrInvalidJSONType) {
+		t.Errorf("got %v, expected %v", err, ErrInvalidJSONType)
+	}
+}
+
+func TestTypeFields(t *testing.T) {
+	type inner struct {
+		A string
+		B string `json:"b"`
+	}
+	type other struct {
+		A string
+	}
+	type outer struct {
+		inner
+		*other
+		B string
+		C string `json:"c,omitempty,string"`
+	}
+
+	var names []string
+	for _, f := range typeFields(reflect.TypeFor[outer]()) {
+		names = append(names, f.name)
+	}
+
+	// A is ambiguous between inner and other, while the tag of inner.B gives it a name of its own
+	if expected := []string{"b", "B", "c"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("got %v, expected %v", names, expected)
+	}
+}
+
+func ptrTo[T any](v T) *T {
+	return &v
+}